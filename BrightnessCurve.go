@@ -0,0 +1,64 @@
+package dotstar
+
+import (
+	"math"
+)
+
+/*
+A BrightnessCurve maps the global brightness, from 0 to 1, to the light output wanted from the strip, from 0 to 1.
+*/
+type BrightnessCurve func(brightness float64) float64
+
+/*
+LinearBrightness sends the global brightness unchanged, the default.
+*/
+func LinearBrightness(brightness float64) float64 {
+	return brightness
+}
+
+/*
+SquareBrightness squares the global brightness, a simple approximation of how the eye sees light output.
+*/
+func SquareBrightness(brightness float64) float64 {
+	return brightness * brightness
+}
+
+/*
+CIE1931Brightness treats the global brightness as the CIE 1931 lightness, so that equal steps look equally large.
+*/
+func CIE1931Brightness(brightness float64) float64 {
+	lightness := brightness * 100
+	if lightness <= 8 {
+		return lightness / 903.3
+	}
+	return math.Pow((lightness+16)/116, 3)
+}
+
+/*
+BrightnessCurveConfig maps the global brightness through curve, for example CIE1931Brightness.
+
+The eye is much more sensitive to changes in dim light than bright light, so with linear scaling most of the
+visible change happens in the bottom quarter of the range.  A perceptual curve spreads it evenly, which suits
+dimmer controls and brightness fades.  As the curve gives low light levels, the nearest 5-bit brightness above
+the level is sent with the RGB values scaled to reach it exactly, as during FadeBrightness.  A nil curve is
+linear.
+*/
+func BrightnessCurveConfig(curve BrightnessCurve) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.brightnessCurve = curve
+	}
+}
+
+/*
+Internal method used to map the global brightness level, from 0 to 255, through the brightness curve.
+*/
+func (ctl *Controller) curveBrightness(level float32) float32 {
+	output := ctl.brightnessCurve(float64(level) / 255)
+	if output < 0 {
+		output = 0
+	}
+	if output > 1 {
+		output = 1
+	}
+	return float32(output * 255)
+}
@@ -0,0 +1,45 @@
+package dotstar
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestBrightnessCurves(t *testing.T) {
+	for name, curve := range map[string]BrightnessCurve{
+		"Linear": LinearBrightness, "Square": SquareBrightness, "CIE1931": CIE1931Brightness,
+	} {
+		if low, high := curve(0), curve(1); math.Abs(low) > 1e-9 || math.Abs(high-1) > 1e-9 {
+			t.Errorf("%s got %v at 0 and %v at 1\n", name, low, high)
+		}
+	}
+	if got := CIE1931Brightness(0.5); math.Abs(got-0.184) > 0.001 {
+		t.Errorf("Got %v expected 0.184\n", got)
+	}
+}
+
+func TestBrightnessCurveConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 1, BrightnessCurveConfig(SquareBrightness))
+	standard := NewController(&frameRecorder{}, 1)
+	strip.SetColour(0, White)
+	standard.SetColour(0, White)
+	if !bytes.Equal(strip.buffer, standard.buffer) {
+		t.Errorf("Got %v expected %v at full brightness\n", strip.buffer, standard.buffer)
+	}
+
+	// Half of the brightness range is a quarter of the light, sent as level 8 of 31 and scaled RGB.
+	strip.SetGlobalBrightness(128)
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	if packet[0] != brightnessHeader|8 || packet[1] != 248 {
+		t.Errorf("Got packet %v\n", packet)
+	}
+
+	// Linear scaling rounds dim levels off, CIE 1931 keeps them lit.
+	cie := NewController(&frameRecorder{}, 1, BrightnessCurveConfig(CIE1931Brightness))
+	cie.SetColour(0, White)
+	cie.SetGlobalBrightness(32)
+	if packet := cie.buffer[headerSize : headerSize+ledPacketSize]; packet[0] != brightnessHeader|1 || packet[1] == 0 {
+		t.Errorf("Got packet %v\n", packet)
+	}
+}
@@ -0,0 +1,107 @@
+package dotstar
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+ColourCorrectionConfig scales the red, green and blue channels before they are sent to the LEDs.
+
+This is used to correct the white balance of a strip, for example a strip where white looks green tinted might
+use ColourCorrectionConfig(1, 0.7, 0.95).  The scaling is applied after gamma correction, so is linear in the
+light produced.  Values are normally between 0 and 1, larger values are clipped at full intensity.
+*/
+func ColourCorrectionConfig(r, g, b float32) ConfigFunc {
+	return func(ctl *Controller) {
+		if r < 0 || g < 0 || b < 0 {
+			ctl.invalidConfig(errors.New("Colour correction must not be negative"))
+		}
+		ctl.correction = [3]float32{r, g, b}
+	}
+}
+
+// TypicalSMD5050 is the colour correction for typical 5050 SMD LED strips.
+var TypicalSMD5050 = ColourCorrectionConfig(1, 176.0/255, 240.0/255)
+
+// TypicalPixelString is the colour correction for typical 8mm pixel strings.
+var TypicalPixelString = ColourCorrectionConfig(1, 224.0/255, 140.0/255)
+
+// UncorrectedColour disables colour correction, which is the default.
+var UncorrectedColour = ColourCorrectionConfig(1, 1, 1)
+
+/*
+NewColourFromKelvin returns the colour of a black body at the given colour temperature.
+
+Values from 1000K (deep orange) to 40000K (blue) are supported, with 6600K being close to white.
+Typical warm white is around 2700K and daylight 5500K.  The approximation used is Tanner Helland's fit
+of the CIE 1964 10 degree colour matching functions.
+*/
+func NewColourFromKelvin(kelvin int, luminosity uint8) Colour {
+	if kelvin < 1000 {
+		kelvin = 1000
+	}
+	if kelvin > 40000 {
+		kelvin = 40000
+	}
+	temp := float64(kelvin) / 100
+
+	var r, g, b float64
+	if temp <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(temp) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(temp-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(temp-60, -0.0755148492)
+	}
+	switch {
+	case temp >= 66:
+		b = 255
+	case temp <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(temp-10) - 305.0447927307
+	}
+
+	return NewColour(clampChannel(r), clampChannel(g), clampChannel(b), luminosity)
+}
+
+/*
+TemperatureConfig tints the output of the strip to match a light source of the given colour temperature.
+
+White is then shown as NewColourFromKelvin would show it, for example TemperatureConfig(2700) makes the strip
+look like warm white incandescent lighting.  This is applied in addition to ColourCorrectionConfig.
+*/
+func TemperatureConfig(kelvin int) ConfigFunc {
+	clr := NewColourFromKelvin(kelvin, 255)
+	return func(ctl *Controller) {
+		if kelvin <= 0 {
+			ctl.invalidConfig(errors.New("Colour temperature must be positive"))
+		}
+		ctl.temperature = [3]float32{float32(clr.R) / 255, float32(clr.G) / 255, float32(clr.B) / 255}
+	}
+}
+
+/*
+Internal function used to convert a calculated channel value to a uint8, clamping to the valid range.
+*/
+func clampChannel(value float64) uint8 {
+	if value <= 0 {
+		return 0
+	}
+	if value >= 255 {
+		return 255
+	}
+	return uint8(value)
+}
+
+/*
+Internal function used to scale a colour channel, clipping at the maximum value.
+*/
+func scaleChannel(value uint8, scale float32) uint8 {
+	scaled := scale * float32(value)
+	if scaled >= 255 {
+		return 255
+	}
+	return uint8(scaled)
+}
@@ -0,0 +1,50 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestColourCorrectionConfig(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, DisableGammaCorrectionConfig(), ColourCorrectionConfig(1, 0.5, 2))
+	strip.SetColour(0, NewColour(100, 100, 200, 255))
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	// bgr order
+	if packet[3] != 100 || packet[2] != 50 || packet[1] != 255 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestTypicalSMD5050(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, DisableGammaCorrectionConfig(), TypicalSMD5050)
+	strip.SetColour(0, White)
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	if packet[3] != 255 || packet[2] != 176 || packet[1] != 240 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestNewColourFromKelvin(t *testing.T) {
+	tests := []struct {
+		kelvin   int
+		expected Colour
+	}{
+		{1900, NewColour(255, 131, 0, 255)},
+		{6600, NewColour(255, 255, 255, 255)},
+		{10000, NewColour(201, 218, 255, 255)},
+	}
+	for _, test := range tests {
+		if got := NewColourFromKelvin(test.kelvin, 255); got != test.expected {
+			t.Errorf("Got %v for %dK expected %v\n", got, test.kelvin, test.expected)
+		}
+	}
+}
+
+func TestTemperatureConfig(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, DisableGammaCorrectionConfig(), TemperatureConfig(1900))
+	strip.SetColour(0, White)
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	if packet[3] != 255 || packet[2] != 131 || packet[1] != 0 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
@@ -0,0 +1,79 @@
+package dotstar
+
+import (
+	"image"
+	"image/color"
+)
+
+/*
+ColourModel converts any color.Color to a Colour at full luminosity, leaving Colour values unchanged.
+
+This allows the standard image, draw and palette packages to work with Colour values, for example by
+using ColourModel.Convert or as the model of an image.
+*/
+var ColourModel color.Model = color.ModelFunc(colourModel)
+
+/*
+RGBA implements color.Color, returning the colour as an LED shows it.
+
+The 5-bit brightness sent for L is applied as in Flatten and the white channel is added to the red, green and
+blue values.  The colour is always fully opaque.
+*/
+func (c Colour) RGBA() (r, g, b, a uint32) {
+	flat := c.Flatten()
+	r = uint32(addChannel(flat.R, flat.W))
+	g = uint32(addChannel(flat.G, flat.W))
+	b = uint32(addChannel(flat.B, flat.W))
+	return r | r<<8, g | g<<8, b | b<<8, 0xffff
+}
+
+/*
+ColourFromColor converts c to a Colour with the given luminosity.
+
+Transparent colours are treated as if drawn over black, so half transparent white gives a mid grey.
+*/
+func ColourFromColor(c color.Color, luminosity uint8) Colour {
+	r, g, b, _ := c.RGBA()
+	return NewColour(uint8(r>>8), uint8(g>>8), uint8(b>>8), luminosity)
+}
+
+/*
+Internal function used by ColourModel to convert c to a Colour.
+*/
+func colourModel(c color.Color) color.Color {
+	if colour, ok := c.(Colour); ok {
+		return colour
+	}
+	return ColourFromColor(c, 255)
+}
+
+/*
+ColorModel implements image.Image, returning ColourModel.
+
+Together with Bounds, At and Set this makes a Matrix a draw.Image, so that images can be drawn onto it using
+the image/draw package.
+*/
+func (m *Matrix) ColorModel() color.Model {
+	return ColourModel
+}
+
+/*
+Bounds implements image.Image, returning a rectangle from 0, 0 to the width and height of the matrix.
+*/
+func (m *Matrix) Bounds() image.Rectangle {
+	return image.Rect(0, 0, m.width, m.height)
+}
+
+/*
+At implements image.Image, returning the colour of the LED at x, y.
+*/
+func (m *Matrix) At(x, y int) color.Color {
+	return m.GetPixel(x, y)
+}
+
+/*
+Set implements draw.Image, setting the LED at x, y to c converted with ColourModel.
+*/
+func (m *Matrix) Set(x, y int, c color.Color) {
+	m.SetPixel(x, y, ColourModel.Convert(c).(Colour))
+}
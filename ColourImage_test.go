@@ -0,0 +1,54 @@
+package dotstar
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestColourRGBA(t *testing.T) {
+	r, g, b, a := NewColour(255, 128, 0, 255).RGBA()
+	if r != 0xffff || g != 0x8080 || b != 0 || a != 0xffff {
+		t.Errorf("Got %x %x %x %x\n", r, g, b, a)
+	}
+	// Half brightness is sent as level 16 of 31.
+	if r, _, _, _ = NewColour(255, 0, 0, 128).RGBA(); r != 0x8383 {
+		t.Errorf("Got %x expected 8383\n", r)
+	}
+	if _, g, _, _ = NewColourW(0, 100, 0, 100, 255).RGBA(); g != 0xc8c8 {
+		t.Errorf("Got %x expected c8c8\n", g)
+	}
+}
+
+func TestColourFromColor(t *testing.T) {
+	if got := ColourFromColor(color.RGBA{R: 10, G: 20, B: 30, A: 255}, 64); got != NewColour(10, 20, 30, 64) {
+		t.Errorf("Got %v\n", got)
+	}
+	if got := ColourFromColor(color.NRGBA{R: 255, G: 255, B: 255, A: 128}, 255); got != NewColour(128, 128, 128, 255) {
+		t.Errorf("Got %v\n", got)
+	}
+	c := NewColour(1, 2, 3, 40)
+	if got := ColourModel.Convert(c); got != c {
+		t.Errorf("Got %v expected %v\n", got, c)
+	}
+	if got := ColourModel.Convert(color.Gray{Y: 200}); got != NewColour(200, 200, 200, 255) {
+		t.Errorf("Got %v\n", got)
+	}
+}
+
+func TestMatrixDrawImage(t *testing.T) {
+	ctl := NewController(&frameRecorder{}, 6)
+	m := NewMatrix(ctl, 3, 2, true)
+	draw.Draw(m, image.Rect(1, 0, 3, 2), image.NewUniform(color.RGBA{R: 255, A: 255}), image.ZP, draw.Src)
+
+	expected := []Colour{Off, Red, Red, Red, Red, Off}
+	for i, want := range expected {
+		if got := ctl.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+	if m.Bounds() != image.Rect(0, 0, 3, 2) || m.At(1, 1) != Red {
+		t.Errorf("Got %v %v\n", m.Bounds(), m.At(1, 1))
+	}
+}
@@ -0,0 +1,183 @@
+package dotstar
+
+/*
+Scale returns the colour with its red, green and blue channels multiplied by f, saturating at 255.
+
+The luminosity is unchanged.  Negative factors give black.
+*/
+func (c Colour) Scale(f float32) Colour {
+	if f < 0 {
+		f = 0
+	}
+	return Colour{R: scaleChannel(c.R, f), G: scaleChannel(c.G, f), B: scaleChannel(c.B, f), W: scaleChannel(c.W, f), L: c.L}
+}
+
+/*
+Add returns the sum of the red, green, blue and white channels of c and other, each saturating at 255.
+
+The luminosity of c is kept.
+*/
+func (c Colour) Add(other Colour) Colour {
+	return Colour{R: addChannel(c.R, other.R), G: addChannel(c.G, other.G), B: addChannel(c.B, other.B),
+		W: addChannel(c.W, other.W), L: c.L}
+}
+
+/*
+Sub returns the red, green, blue and white channels of other subtracted from c, each stopping at 0.
+
+The luminosity of c is kept.
+*/
+func (c Colour) Sub(other Colour) Colour {
+	return Colour{R: subChannel(c.R, other.R), G: subChannel(c.G, other.G), B: subChannel(c.B, other.B),
+		W: subChannel(c.W, other.W), L: c.L}
+}
+
+/*
+Lerp linearly interpolates all channels from c (t of 0) to other (t of 1), rounding to the nearest value.
+
+Values of t outside 0 to 1 are clamped.
+*/
+func (c Colour) Lerp(other Colour, t float64) Colour {
+	if t <= 0 {
+		return c
+	}
+	if t >= 1 {
+		return other
+	}
+	lerp := func(from, to uint8) uint8 {
+		return clampChannel(float64(from) + (float64(to)-float64(from))*t + 0.5)
+	}
+	return Colour{R: lerp(c.R, other.R), G: lerp(c.G, other.G), B: lerp(c.B, other.B), W: lerp(c.W, other.W),
+		L: lerp(c.L, other.L)}
+}
+
+/*
+Equal returns true if all channels of c and other match.
+*/
+func (c Colour) Equal(other Colour) bool {
+	return c == other
+}
+
+/*
+Flatten returns the colour as an LED shows it, with the 5-bit brightness sent for L applied to the red, green, blue
+and white values and L set to 255.
+
+This is used to show decoded frames on displays without a separate brightness, such as a screen or DMX fixture.
+*/
+func (c Colour) Flatten() Colour {
+	level := uint32(c.L >> 3)
+	return Colour{
+		R: uint8(uint32(c.R) * level / 31),
+		G: uint8(uint32(c.G) * level / 31),
+		B: uint8(uint32(c.B) * level / 31),
+		W: uint8(uint32(c.W) * level / 31),
+		L: 255,
+	}
+}
+
+/*
+Multiply returns the product of each channel of c and other, scaled so that 255 multiplied by a value leaves it
+unchanged.  This darkens c, with white having no effect and black giving black.
+*/
+func (c Colour) Multiply(other Colour) Colour {
+	return Colour{R: multiplyChannel(c.R, other.R), G: multiplyChannel(c.G, other.G), B: multiplyChannel(c.B, other.B),
+		W: multiplyChannel(c.W, other.W), L: multiplyChannel(c.L, other.L)}
+}
+
+/*
+Screen returns the inverse of multiplying the inverse of each channel of c and other.  This lightens c, with black
+having no effect and white giving white.
+*/
+func (c Colour) Screen(other Colour) Colour {
+	return Colour{R: screenChannel(c.R, other.R), G: screenChannel(c.G, other.G), B: screenChannel(c.B, other.B),
+		W: screenChannel(c.W, other.W), L: screenChannel(c.L, other.L)}
+}
+
+/*
+Lighten returns the larger value of each channel of c and other.
+*/
+func (c Colour) Lighten(other Colour) Colour {
+	return Colour{R: maxChannel(c.R, other.R), G: maxChannel(c.G, other.G), B: maxChannel(c.B, other.B),
+		W: maxChannel(c.W, other.W), L: maxChannel(c.L, other.L)}
+}
+
+/*
+AlphaOver returns above drawn over c with an opacity of alpha, from 0 (c unchanged) to 1 (above).
+*/
+func (c Colour) AlphaOver(above Colour, alpha float64) Colour {
+	return c.Lerp(above, alpha)
+}
+
+/*
+A BlendFunc combines the colour of a pixel with a colour drawn above it.
+
+The blend methods of Colour can be used as a BlendFunc, for example Colour.Multiply or Colour.Add.
+*/
+type BlendFunc func(below, above Colour) Colour
+
+/*
+AlphaOverFunc returns a BlendFunc that draws the colour above with an opacity of alpha.
+*/
+func AlphaOverFunc(alpha float64) BlendFunc {
+	return func(below, above Colour) Colour {
+		return below.AlphaOver(above, alpha)
+	}
+}
+
+/*
+BlendBuffer blends each colour of src onto the matching colour of dst.
+
+Only the colours present in both buffers are blended.
+*/
+func BlendBuffer(dst, src []Colour, blend BlendFunc) {
+	if len(src) < len(dst) {
+		dst = dst[:len(src)]
+	}
+	for i := range dst {
+		dst[i] = blend(dst[i], src[i])
+	}
+}
+
+/*
+Internal function used to add two channels, saturating at 255.
+*/
+func addChannel(a, b uint8) uint8 {
+	if sum := uint16(a) + uint16(b); sum < 255 {
+		return uint8(sum)
+	}
+	return 255
+}
+
+/*
+Internal function used to subtract b from a, stopping at 0.
+*/
+func subChannel(a, b uint8) uint8 {
+	if b >= a {
+		return 0
+	}
+	return a - b
+}
+
+/*
+Internal function used to multiply two channels, with 255 representing 1.
+*/
+func multiplyChannel(a, b uint8) uint8 {
+	return uint8((uint16(a)*uint16(b) + 127) / 255)
+}
+
+/*
+Internal function used to screen two channels.
+*/
+func screenChannel(a, b uint8) uint8 {
+	return 255 - multiplyChannel(255-a, 255-b)
+}
+
+/*
+Internal function used to find the larger of two channels.
+*/
+func maxChannel(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
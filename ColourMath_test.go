@@ -0,0 +1,70 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestColourScale(t *testing.T) {
+	c := NewColour(100, 200, 10, 128)
+	if scaled := c.Scale(0.5); scaled != NewColour(50, 100, 5, 128) {
+		t.Errorf("Got %v\n", scaled)
+	}
+	if scaled := c.Scale(2); scaled != NewColour(200, 255, 20, 128) {
+		t.Errorf("Got %v\n", scaled)
+	}
+	if scaled := c.Scale(-1); scaled != NewColour(0, 0, 0, 128) {
+		t.Errorf("Got %v\n", scaled)
+	}
+}
+
+func TestColourAddSub(t *testing.T) {
+	a, b := NewColour(200, 10, 100, 255), NewColour(100, 20, 100, 8)
+	if sum := a.Add(b); sum != NewColour(255, 30, 200, 255) {
+		t.Errorf("Got %v\n", sum)
+	}
+	if diff := a.Sub(b); diff != NewColour(100, 0, 0, 255) {
+		t.Errorf("Got %v\n", diff)
+	}
+}
+
+func TestColourLerp(t *testing.T) {
+	tests := []struct {
+		t        float64
+		expected Colour
+	}{
+		{-1, Off},
+		{0.5, NewColour(128, 0, 64, 128)},
+		{2, NewColour(255, 0, 127, 255)},
+	}
+	for _, test := range tests {
+		if c := Off.Lerp(NewColour(255, 0, 127, 255), test.t); !c.Equal(test.expected) {
+			t.Errorf("Got %v expected %v for %v\n", c, test.expected, test.t)
+		}
+	}
+}
+
+func TestColourBlendModes(t *testing.T) {
+	a, b := NewColour(200, 0, 255, 255), NewColour(128, 255, 255, 128)
+	tests := []struct {
+		name     string
+		blend    BlendFunc
+		expected Colour
+	}{
+		{"Add", Colour.Add, NewColour(255, 255, 255, 255)},
+		{"Multiply", Colour.Multiply, NewColour(100, 0, 255, 128)},
+		{"Screen", Colour.Screen, NewColour(228, 255, 255, 255)},
+		{"Lighten", Colour.Lighten, NewColour(200, 255, 255, 255)},
+		{"AlphaOver", AlphaOverFunc(0.5), NewColour(164, 128, 255, 192)},
+	}
+	for _, test := range tests {
+		if c := test.blend(a, b); c != test.expected {
+			t.Errorf("%s got %v expected %v\n", test.name, c, test.expected)
+		}
+	}
+
+	dst := []Colour{a, a, a}
+	BlendBuffer(dst, []Colour{b, NewColour(0, 0, 0, 255)}, Colour.Multiply)
+	if dst[0] != NewColour(100, 0, 255, 128) || dst[1] != NewColour(0, 0, 0, 255) || dst[2] != a {
+		t.Errorf("Got %v\n", dst)
+	}
+}
@@ -0,0 +1,153 @@
+package dotstar
+
+// colourNames holds the CSS named colours, which are also used by X11, keyed by lower case name.
+var colourNames = map[string]Colour{
+	"aliceblue":            {R: 0xF0, G: 0xF8, B: 0xFF, L: 255},
+	"antiquewhite":         {R: 0xFA, G: 0xEB, B: 0xD7, L: 255},
+	"aqua":                 {R: 0x00, G: 0xFF, B: 0xFF, L: 255},
+	"aquamarine":           {R: 0x7F, G: 0xFF, B: 0xD4, L: 255},
+	"azure":                {R: 0xF0, G: 0xFF, B: 0xFF, L: 255},
+	"beige":                {R: 0xF5, G: 0xF5, B: 0xDC, L: 255},
+	"bisque":               {R: 0xFF, G: 0xE4, B: 0xC4, L: 255},
+	"black":                {R: 0x00, G: 0x00, B: 0x00, L: 255},
+	"blanchedalmond":       {R: 0xFF, G: 0xEB, B: 0xCD, L: 255},
+	"blue":                 {R: 0x00, G: 0x00, B: 0xFF, L: 255},
+	"blueviolet":           {R: 0x8A, G: 0x2B, B: 0xE2, L: 255},
+	"brown":                {R: 0xA5, G: 0x2A, B: 0x2A, L: 255},
+	"burlywood":            {R: 0xDE, G: 0xB8, B: 0x87, L: 255},
+	"cadetblue":            {R: 0x5F, G: 0x9E, B: 0xA0, L: 255},
+	"chartreuse":           {R: 0x7F, G: 0xFF, B: 0x00, L: 255},
+	"chocolate":            {R: 0xD2, G: 0x69, B: 0x1E, L: 255},
+	"coral":                {R: 0xFF, G: 0x7F, B: 0x50, L: 255},
+	"cornflowerblue":       {R: 0x64, G: 0x95, B: 0xED, L: 255},
+	"cornsilk":             {R: 0xFF, G: 0xF8, B: 0xDC, L: 255},
+	"crimson":              {R: 0xDC, G: 0x14, B: 0x3C, L: 255},
+	"cyan":                 {R: 0x00, G: 0xFF, B: 0xFF, L: 255},
+	"darkblue":             {R: 0x00, G: 0x00, B: 0x8B, L: 255},
+	"darkcyan":             {R: 0x00, G: 0x8B, B: 0x8B, L: 255},
+	"darkgoldenrod":        {R: 0xB8, G: 0x86, B: 0x0B, L: 255},
+	"darkgray":             {R: 0xA9, G: 0xA9, B: 0xA9, L: 255},
+	"darkgreen":            {R: 0x00, G: 0x64, B: 0x00, L: 255},
+	"darkgrey":             {R: 0xA9, G: 0xA9, B: 0xA9, L: 255},
+	"darkkhaki":            {R: 0xBD, G: 0xB7, B: 0x6B, L: 255},
+	"darkmagenta":          {R: 0x8B, G: 0x00, B: 0x8B, L: 255},
+	"darkolivegreen":       {R: 0x55, G: 0x6B, B: 0x2F, L: 255},
+	"darkorange":           {R: 0xFF, G: 0x8C, B: 0x00, L: 255},
+	"darkorchid":           {R: 0x99, G: 0x32, B: 0xCC, L: 255},
+	"darkred":              {R: 0x8B, G: 0x00, B: 0x00, L: 255},
+	"darksalmon":           {R: 0xE9, G: 0x96, B: 0x7A, L: 255},
+	"darkseagreen":         {R: 0x8F, G: 0xBC, B: 0x8F, L: 255},
+	"darkslateblue":        {R: 0x48, G: 0x3D, B: 0x8B, L: 255},
+	"darkslategray":        {R: 0x2F, G: 0x4F, B: 0x4F, L: 255},
+	"darkslategrey":        {R: 0x2F, G: 0x4F, B: 0x4F, L: 255},
+	"darkturquoise":        {R: 0x00, G: 0xCE, B: 0xD1, L: 255},
+	"darkviolet":           {R: 0x94, G: 0x00, B: 0xD3, L: 255},
+	"deeppink":             {R: 0xFF, G: 0x14, B: 0x93, L: 255},
+	"deepskyblue":          {R: 0x00, G: 0xBF, B: 0xFF, L: 255},
+	"dimgray":              {R: 0x69, G: 0x69, B: 0x69, L: 255},
+	"dimgrey":              {R: 0x69, G: 0x69, B: 0x69, L: 255},
+	"dodgerblue":           {R: 0x1E, G: 0x90, B: 0xFF, L: 255},
+	"firebrick":            {R: 0xB2, G: 0x22, B: 0x22, L: 255},
+	"floralwhite":          {R: 0xFF, G: 0xFA, B: 0xF0, L: 255},
+	"forestgreen":          {R: 0x22, G: 0x8B, B: 0x22, L: 255},
+	"fuchsia":              {R: 0xFF, G: 0x00, B: 0xFF, L: 255},
+	"gainsboro":            {R: 0xDC, G: 0xDC, B: 0xDC, L: 255},
+	"ghostwhite":           {R: 0xF8, G: 0xF8, B: 0xFF, L: 255},
+	"gold":                 {R: 0xFF, G: 0xD7, B: 0x00, L: 255},
+	"goldenrod":            {R: 0xDA, G: 0xA5, B: 0x20, L: 255},
+	"gray":                 {R: 0x80, G: 0x80, B: 0x80, L: 255},
+	"green":                {R: 0x00, G: 0x80, B: 0x00, L: 255},
+	"greenyellow":          {R: 0xAD, G: 0xFF, B: 0x2F, L: 255},
+	"grey":                 {R: 0x80, G: 0x80, B: 0x80, L: 255},
+	"honeydew":             {R: 0xF0, G: 0xFF, B: 0xF0, L: 255},
+	"hotpink":              {R: 0xFF, G: 0x69, B: 0xB4, L: 255},
+	"indianred":            {R: 0xCD, G: 0x5C, B: 0x5C, L: 255},
+	"indigo":               {R: 0x4B, G: 0x00, B: 0x82, L: 255},
+	"ivory":                {R: 0xFF, G: 0xFF, B: 0xF0, L: 255},
+	"khaki":                {R: 0xF0, G: 0xE6, B: 0x8C, L: 255},
+	"lavender":             {R: 0xE6, G: 0xE6, B: 0xFA, L: 255},
+	"lavenderblush":        {R: 0xFF, G: 0xF0, B: 0xF5, L: 255},
+	"lawngreen":            {R: 0x7C, G: 0xFC, B: 0x00, L: 255},
+	"lemonchiffon":         {R: 0xFF, G: 0xFA, B: 0xCD, L: 255},
+	"lightblue":            {R: 0xAD, G: 0xD8, B: 0xE6, L: 255},
+	"lightcoral":           {R: 0xF0, G: 0x80, B: 0x80, L: 255},
+	"lightcyan":            {R: 0xE0, G: 0xFF, B: 0xFF, L: 255},
+	"lightgoldenrodyellow": {R: 0xFA, G: 0xFA, B: 0xD2, L: 255},
+	"lightgray":            {R: 0xD3, G: 0xD3, B: 0xD3, L: 255},
+	"lightgreen":           {R: 0x90, G: 0xEE, B: 0x90, L: 255},
+	"lightgrey":            {R: 0xD3, G: 0xD3, B: 0xD3, L: 255},
+	"lightpink":            {R: 0xFF, G: 0xB6, B: 0xC1, L: 255},
+	"lightsalmon":          {R: 0xFF, G: 0xA0, B: 0x7A, L: 255},
+	"lightseagreen":        {R: 0x20, G: 0xB2, B: 0xAA, L: 255},
+	"lightskyblue":         {R: 0x87, G: 0xCE, B: 0xFA, L: 255},
+	"lightslategray":       {R: 0x77, G: 0x88, B: 0x99, L: 255},
+	"lightslategrey":       {R: 0x77, G: 0x88, B: 0x99, L: 255},
+	"lightsteelblue":       {R: 0xB0, G: 0xC4, B: 0xDE, L: 255},
+	"lightyellow":          {R: 0xFF, G: 0xFF, B: 0xE0, L: 255},
+	"lime":                 {R: 0x00, G: 0xFF, B: 0x00, L: 255},
+	"limegreen":            {R: 0x32, G: 0xCD, B: 0x32, L: 255},
+	"linen":                {R: 0xFA, G: 0xF0, B: 0xE6, L: 255},
+	"magenta":              {R: 0xFF, G: 0x00, B: 0xFF, L: 255},
+	"maroon":               {R: 0x80, G: 0x00, B: 0x00, L: 255},
+	"mediumaquamarine":     {R: 0x66, G: 0xCD, B: 0xAA, L: 255},
+	"mediumblue":           {R: 0x00, G: 0x00, B: 0xCD, L: 255},
+	"mediumorchid":         {R: 0xBA, G: 0x55, B: 0xD3, L: 255},
+	"mediumpurple":         {R: 0x93, G: 0x70, B: 0xDB, L: 255},
+	"mediumseagreen":       {R: 0x3C, G: 0xB3, B: 0x71, L: 255},
+	"mediumslateblue":      {R: 0x7B, G: 0x68, B: 0xEE, L: 255},
+	"mediumspringgreen":    {R: 0x00, G: 0xFA, B: 0x9A, L: 255},
+	"mediumturquoise":      {R: 0x48, G: 0xD1, B: 0xCC, L: 255},
+	"mediumvioletred":      {R: 0xC7, G: 0x15, B: 0x85, L: 255},
+	"midnightblue":         {R: 0x19, G: 0x19, B: 0x70, L: 255},
+	"mintcream":            {R: 0xF5, G: 0xFF, B: 0xFA, L: 255},
+	"mistyrose":            {R: 0xFF, G: 0xE4, B: 0xE1, L: 255},
+	"moccasin":             {R: 0xFF, G: 0xE4, B: 0xB5, L: 255},
+	"navajowhite":          {R: 0xFF, G: 0xDE, B: 0xAD, L: 255},
+	"navy":                 {R: 0x00, G: 0x00, B: 0x80, L: 255},
+	"oldlace":              {R: 0xFD, G: 0xF5, B: 0xE6, L: 255},
+	"olive":                {R: 0x80, G: 0x80, B: 0x00, L: 255},
+	"olivedrab":            {R: 0x6B, G: 0x8E, B: 0x23, L: 255},
+	"orange":               {R: 0xFF, G: 0xA5, B: 0x00, L: 255},
+	"orangered":            {R: 0xFF, G: 0x45, B: 0x00, L: 255},
+	"orchid":               {R: 0xDA, G: 0x70, B: 0xD6, L: 255},
+	"palegoldenrod":        {R: 0xEE, G: 0xE8, B: 0xAA, L: 255},
+	"palegreen":            {R: 0x98, G: 0xFB, B: 0x98, L: 255},
+	"paleturquoise":        {R: 0xAF, G: 0xEE, B: 0xEE, L: 255},
+	"palevioletred":        {R: 0xDB, G: 0x70, B: 0x93, L: 255},
+	"papayawhip":           {R: 0xFF, G: 0xEF, B: 0xD5, L: 255},
+	"peachpuff":            {R: 0xFF, G: 0xDA, B: 0xB9, L: 255},
+	"peru":                 {R: 0xCD, G: 0x85, B: 0x3F, L: 255},
+	"pink":                 {R: 0xFF, G: 0xC0, B: 0xCB, L: 255},
+	"plum":                 {R: 0xDD, G: 0xA0, B: 0xDD, L: 255},
+	"powderblue":           {R: 0xB0, G: 0xE0, B: 0xE6, L: 255},
+	"purple":               {R: 0x80, G: 0x00, B: 0x80, L: 255},
+	"rebeccapurple":        {R: 0x66, G: 0x33, B: 0x99, L: 255},
+	"red":                  {R: 0xFF, G: 0x00, B: 0x00, L: 255},
+	"rosybrown":            {R: 0xBC, G: 0x8F, B: 0x8F, L: 255},
+	"royalblue":            {R: 0x41, G: 0x69, B: 0xE1, L: 255},
+	"saddlebrown":          {R: 0x8B, G: 0x45, B: 0x13, L: 255},
+	"salmon":               {R: 0xFA, G: 0x80, B: 0x72, L: 255},
+	"sandybrown":           {R: 0xF4, G: 0xA4, B: 0x60, L: 255},
+	"seagreen":             {R: 0x2E, G: 0x8B, B: 0x57, L: 255},
+	"seashell":             {R: 0xFF, G: 0xF5, B: 0xEE, L: 255},
+	"sienna":               {R: 0xA0, G: 0x52, B: 0x2D, L: 255},
+	"silver":               {R: 0xC0, G: 0xC0, B: 0xC0, L: 255},
+	"skyblue":              {R: 0x87, G: 0xCE, B: 0xEB, L: 255},
+	"slateblue":            {R: 0x6A, G: 0x5A, B: 0xCD, L: 255},
+	"slategray":            {R: 0x70, G: 0x80, B: 0x90, L: 255},
+	"slategrey":            {R: 0x70, G: 0x80, B: 0x90, L: 255},
+	"snow":                 {R: 0xFF, G: 0xFA, B: 0xFA, L: 255},
+	"springgreen":          {R: 0x00, G: 0xFF, B: 0x7F, L: 255},
+	"steelblue":            {R: 0x46, G: 0x82, B: 0xB4, L: 255},
+	"tan":                  {R: 0xD2, G: 0xB4, B: 0x8C, L: 255},
+	"teal":                 {R: 0x00, G: 0x80, B: 0x80, L: 255},
+	"thistle":              {R: 0xD8, G: 0xBF, B: 0xD8, L: 255},
+	"tomato":               {R: 0xFF, G: 0x63, B: 0x47, L: 255},
+	"turquoise":            {R: 0x40, G: 0xE0, B: 0xD0, L: 255},
+	"violet":               {R: 0xEE, G: 0x82, B: 0xEE, L: 255},
+	"wheat":                {R: 0xF5, G: 0xDE, B: 0xB3, L: 255},
+	"white":                {R: 0xFF, G: 0xFF, B: 0xFF, L: 255},
+	"whitesmoke":           {R: 0xF5, G: 0xF5, B: 0xF5, L: 255},
+	"yellow":               {R: 0xFF, G: 0xFF, B: 0x00, L: 255},
+	"yellowgreen":          {R: 0x9A, G: 0xCD, B: 0x32, L: 255},
+}
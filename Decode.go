@@ -0,0 +1,145 @@
+package dotstar
+
+import (
+	"errors"
+)
+
+/*
+A FrameFormat describes the messages sent by a Controller, for decoding them exactly.
+*/
+type FrameFormat struct {
+	// LedCount is the number of LED packets in each message, or 0 to work it out from the message.
+	LedCount int
+	// PacketSize is the number of bytes in each LED packet, 5 for RGBW colour orders and 4 otherwise.
+	PacketSize int
+}
+
+/*
+A FrameDecoder is a writer that decodes the Dotstar messages written to it, such as the SimulatorWriter.
+
+When a Controller is created or resized with a FrameDecoder as its writer, it calls SetFrameFormat so that the
+messages can be decoded without mistaking the end frame for LEDs.
+*/
+type FrameDecoder interface {
+	SetFrameFormat(format FrameFormat)
+}
+
+/*
+DecodeFrame decodes a Dotstar message, as sent by Update(), back into the colours of each LED.
+
+order is the colour order of the message as given to OrderConfig, "bgr" by default.  Only 32 luminosity levels
+are sent, so luminosity is scaled back up to the nearest of those (31 becomes 255).  Colours are returned after
+gamma correction and any brightness scaling applied by the Controller.  Decoding stops at the first byte that
+is not the start of an LED packet, which is normally the footer.  An end frame of 0xFF bytes looks like full
+white LEDs, so these are left out where the message length shows they are part of the end frame.
+*/
+func DecodeFrame(data []byte, order string) ([]Colour, error) {
+	return DecodeFrameFormat(data, order, FrameFormat{})
+}
+
+/*
+DecodeFrameFormat decodes a Dotstar message as DecodeFrame does, using format to find the LEDs.
+
+Giving the LedCount ensures that extra end frame bytes added by EndFrameConfig are not decoded as LEDs.
+*/
+func DecodeFrameFormat(data []byte, order string, format FrameFormat) ([]Colour, error) {
+	cfg, err := OrderConfig(order)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize {
+		return nil, errors.New("Frame is shorter than the header")
+	}
+	for _, b := range data[:headerSize] {
+		if b != 0 {
+			return nil, errors.New("Frame must start with a header of zero bytes")
+		}
+	}
+	var ctl Controller
+	cfg(&ctl)
+	return decodeColours(data, ctl.packetSize, ctl.rOffset, ctl.gOffset, ctl.bOffset, ctl.wOffset, format), nil
+}
+
+/*
+Internal function used to split a Dotstar message into the per-LED packets.
+
+Packets of size bytes are read from after the header until a byte without the brightness header bits set is found,
+or format.LedCount packets have been read.  Without a count, trailing packets of 0xFF bytes are dropped while the
+bytes after them are too few to be the end frame, as they must then be part of an end frame of 0xFF bytes.
+*/
+func decodePackets(data []byte, size int, format FrameFormat) [][]byte {
+	var packets [][]byte
+	for offset := headerSize; offset+size <= len(data); offset += size {
+		if data[offset]&brightnessHeader != brightnessHeader {
+			break
+		}
+		if format.LedCount > 0 && len(packets) == format.LedCount {
+			break
+		}
+		packets = append(packets, data[offset:offset+size])
+	}
+	if format.LedCount > 0 {
+		return packets
+	}
+	for count := len(packets); count > 0 && isEndFrame(packets[count-1]); count-- {
+		if len(data)-headerSize-count*size >= endFrameSize(count) {
+			break
+		}
+		packets = packets[:count-1]
+	}
+	return packets
+}
+
+/*
+Internal function used to check whether a packet is made of end frame bytes of 0xFF.
+*/
+func isEndFrame(packet []byte) bool {
+	for _, b := range packet {
+		if b != 0xFF {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Internal function used to decode a Dotstar message into Colour values.
+
+The packet size and offsets give the layout of each packet, as held by a Controller, with a wOffset of 0 for
+RGB strips.  The luminosity is scaled from the 5 bits sent back up to the 0-255 range.
+*/
+func decodeColours(data []byte, size, rOffset, gOffset, bOffset, wOffset int, format FrameFormat) []Colour {
+	packets := decodePackets(data, size, format)
+	clrs := make([]Colour, len(packets), len(packets))
+	for i, packet := range packets {
+		clrs[i] = Colour{
+			R: packet[rOffset],
+			G: packet[gOffset],
+			B: packet[bOffset],
+			L: uint8(uint32(packet[0]&^brightnessHeader) * 255 / 31),
+		}
+		if wOffset != 0 {
+			clrs[i].W = packet[wOffset]
+		}
+	}
+	return clrs
+}
+
+/*
+Internal method used to tell a FrameDecoder writer the format of the messages it will be sent.
+*/
+func (ctl *Controller) announceFrameFormat() {
+	if decoder, ok := ctl.writer().(FrameDecoder); ok {
+		decoder.SetFrameFormat(FrameFormat{LedCount: ctl.physicalCount, PacketSize: ctl.packetSize})
+	}
+}
+
+/*
+Internal method used to find the writer or Driver the Controller sends messages to.
+*/
+func (ctl *Controller) writer() interface{} {
+	if wd, ok := ctl.driver.(*writerDriver); ok {
+		return wd.w
+	}
+	return ctl.driver
+}
@@ -0,0 +1,41 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestDecodeFrame(t *testing.T) {
+	out := &frameRecorder{}
+	rgb, _ := OrderConfig("rgb")
+	strip := NewController(out, 3, rgb, DisableGammaCorrectionConfig())
+	expected := []Colour{NewColour(1, 2, 3, 255), Off, NewColour(255, 0, 128, 131)}
+	strip.SetColours(expected)
+	strip.Update()
+
+	clrs, err := DecodeFrame(out.frames[0], "RGB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clrs) != len(expected) {
+		t.Fatalf("Got %v expected %v\n", clrs, expected)
+	}
+	for i := range expected {
+		if clrs[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, clrs[i], expected[i])
+		}
+	}
+
+	if _, err := DecodeFrame(out.frames[0], "rgx"); err == nil {
+		t.Errorf("Expected an error for an invalid order\n")
+	}
+	if _, err := DecodeFrame([]byte{0, 1, 0, 0, 0xFF, 1, 2, 3}, "rgb"); err == nil {
+		t.Errorf("Expected an error for an invalid header\n")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	// 131 is sent as 16 of 31 levels.
+	if c := NewColour(255, 62, 0, 131).Flatten(); c != NewColour(131, 32, 0, 255) {
+		t.Errorf("Got %v\n", c)
+	}
+}
@@ -0,0 +1,75 @@
+package dotstar
+
+import (
+	"errors"
+	"strings"
+)
+
+// detectAnswers maps the answers accepted by DetectOrder to the channel letters used by OrderConfig.
+var detectAnswers = map[string]byte{"r": 'r', "red": 'r', "g": 'g', "green": 'g', "b": 'b', "blue": 'b'}
+
+/*
+DetectOrder works out the colour order of a strip by lighting one channel at a time and asking which colour is
+seen, returning the order to pass to OrderConfig.
+
+prompt is called with each question and should return the user's answer: red, green or blue, or just the first
+letter.  Two questions are asked, the third channel being worked out from the answers.  The colours of the strip
+are restored before returning.  For RGBW strips the white channel is assumed to be in the configured position.
+*/
+func DetectOrder(ctl *Controller, prompt func(question string) string) (string, error) {
+	saved := ctl.Snapshot()
+	defer func() {
+		ctl.SetColours(saved)
+		ctl.Update()
+	}()
+
+	positions := []int{1, 2, 3}
+	if ctl.wOffset != 0 {
+		positions = positions[:0]
+		for position := 1; position <= 4; position++ {
+			if position != ctl.wOffset {
+				positions = append(positions, position)
+			}
+		}
+	}
+
+	order := make([]byte, ctl.packetSize-1)
+	if ctl.wOffset != 0 {
+		order[ctl.wOffset-1] = 'w'
+	}
+	remaining := "rgb"
+	for _, position := range positions[:2] {
+		ctl.Fill(ctl.channelColour(position))
+		if err := ctl.Update(); err != nil {
+			return "", err
+		}
+		answer := strings.ToLower(strings.TrimSpace(prompt("Which colour are the LEDs showing: red, green or blue?")))
+		channel, ok := detectAnswers[answer]
+		if !ok {
+			return "", errors.New("Answer must be red, green or blue")
+		}
+		if strings.IndexByte(remaining, channel) == -1 {
+			return "", errors.New("The same colour was seen for two channels, check the wiring")
+		}
+		remaining = strings.Replace(remaining, string(channel), "", 1)
+		order[position-1] = channel
+	}
+	order[positions[2]-1] = remaining[0]
+	return string(order), nil
+}
+
+/*
+Internal method used to find the colour that lights only the channel sent at position in each packet.
+*/
+func (ctl *Controller) channelColour(position int) Colour {
+	clr := Colour{L: 255}
+	switch position {
+	case ctl.rOffset:
+		clr.R = 255
+	case ctl.gOffset:
+		clr.G = 255
+	case ctl.bOffset:
+		clr.B = 255
+	}
+	return clr
+}
@@ -0,0 +1,48 @@
+package dotstar
+
+import (
+	"strings"
+	"testing"
+)
+
+// wiredAs answers the questions asked by DetectOrder for a strip wired with the given order.
+func wiredAs(t *testing.T, ctl *Controller, order string) func(string) string {
+	return func(question string) string {
+		packet := ctl.packet(0)
+		for i := 1; i < len(packet); i++ {
+			if packet[i] == 255 {
+				return map[byte]string{'r': "Red", 'g': "green", 'b': "b"}[order[i-1]]
+			}
+		}
+		t.Fatalf("No channel lit for %v\n", question)
+		return ""
+	}
+}
+
+func TestDetectOrder(t *testing.T) {
+	for _, wired := range []string{"rgb", "rbg", "grb", "gbr", "brg", "bgr"} {
+		ctl := NewController(&frameRecorder{}, 2)
+		ctl.SetColour(1, Green)
+		order, err := DetectOrder(ctl, wiredAs(t, ctl, wired))
+		if err != nil || order != wired {
+			t.Errorf("Got %v %v expected %v\n", order, err, wired)
+		}
+		if ctl.GetColour(1) != Green || ctl.GetColour(0) != Off {
+			t.Errorf("Got %v expected the colours to be restored\n", ctl.Snapshot())
+		}
+	}
+
+	rgbw, _ := OrderConfig("rgbw")
+	ctl := NewController(&frameRecorder{}, 1, rgbw)
+	if order, err := DetectOrder(ctl, wiredAs(t, ctl, "grbw")); err != nil || order != "grbw" {
+		t.Errorf("Got %v %v expected grbw\n", order, err)
+	}
+
+	ctl = NewController(&frameRecorder{}, 1)
+	if _, err := DetectOrder(ctl, func(string) string { return "purple" }); err == nil {
+		t.Errorf("Expected an error for an unknown colour\n")
+	}
+	if _, err := DetectOrder(ctl, func(string) string { return "red" }); err == nil || !strings.Contains(err.Error(), "same") {
+		t.Errorf("Got %v expected an error for a repeated colour\n", err)
+	}
+}
@@ -0,0 +1,48 @@
+package dotstar
+
+/*
+DiffColours returns the positions at which a and b differ, in order.
+
+If one is longer than the other, the positions beyond the end of the shorter are included.  This is useful when
+comparing frames in tests, or to find which LEDs an effect changed between frames.
+*/
+func DiffColours(a, b []Colour) []int {
+	var diff []int
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) || i >= len(b) || a[i] != b[i] {
+			diff = append(diff, i)
+		}
+	}
+	return diff
+}
+
+/*
+ColoursEqual returns true if a and b are the same length and every channel of each colour, including luminosity,
+is within tolerance of the other.  A tolerance of 0 requires the colours to match exactly.
+
+A small tolerance allows for rounding, for example when comparing a fade against expected values.
+*/
+func ColoursEqual(a, b []Colour, tolerance uint8) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !channelsWithin(a[i], b[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Internal function used to check whether every channel of a is within tolerance of b.
+*/
+func channelsWithin(a, b Colour, tolerance uint8) bool {
+	within := func(x, y uint8) bool {
+		if x > y {
+			return x-y <= tolerance
+		}
+		return y-x <= tolerance
+	}
+	return within(a.R, b.R) && within(a.G, b.G) && within(a.B, b.B) && within(a.W, b.W) && within(a.L, b.L)
+}
@@ -0,0 +1,31 @@
+package dotstar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffColours(t *testing.T) {
+	a := []Colour{Red, Green, Blue, White}
+	b := []Colour{Red, Blue, Blue}
+	if diff := DiffColours(a, b); !reflect.DeepEqual(diff, []int{1, 3}) {
+		t.Errorf("Got %v expected %v\n", diff, []int{1, 3})
+	}
+	if diff := DiffColours(a, a); diff != nil {
+		t.Errorf("Got %v expected no differences\n", diff)
+	}
+}
+
+func TestColoursEqual(t *testing.T) {
+	a := []Colour{NewColour(10, 20, 30, 255), NewColourW(0, 0, 0, 100, 128)}
+	b := []Colour{NewColour(12, 19, 30, 253), NewColourW(0, 0, 0, 98, 130)}
+	if ColoursEqual(a, b, 1) {
+		t.Errorf("Expected a difference of 2 to be outside a tolerance of 1\n")
+	}
+	if !ColoursEqual(a, b, 2) {
+		t.Errorf("Expected a difference of 2 to be within a tolerance of 2\n")
+	}
+	if !ColoursEqual(a, a, 0) || ColoursEqual(a, a[:1], 255) {
+		t.Errorf("Got wrong result for exact or different length comparison\n")
+	}
+}
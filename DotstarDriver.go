@@ -4,16 +4,18 @@ The dotstar package provides an API for controlling a Dotstar LED strip.
 This package has been used to drive a 30 LED Dotstar strip from a Raspberry Pi over the SPI bus.
 The API requires an io.Writer that sends the written bytes to the Dotstar strip.  The
 github.com/kidoman/embd package provides such an interface as shown in the example.
-
 */
 package dotstar
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"strings"
+	"sync"
+	"time"
 )
 
 // headerSize is the leading header of zeros to start a message
@@ -30,6 +32,7 @@ func defaultGammaFunc(in Colour) (out Colour) {
 	out.R = defaultGammaTable[in.R]
 	out.G = defaultGammaTable[in.G]
 	out.B = defaultGammaTable[in.B]
+	out.W = defaultGammaTable[in.W]
 	out.L = in.L
 	return out
 }
@@ -61,12 +64,17 @@ type Colour struct {
 	R, G, B uint8
 	// Luminosity (Brightness).  255 is maximum, there are only 32 levels so use increments of 8
 	L uint8
+	// W is the white channel of RGBW strips, it is ignored by RGB strips.
+	W uint8
 }
 
 /*
 String returns a description of the colour value.
 */
 func (c Colour) String() string {
+	if c.W != 0 {
+		return fmt.Sprintf("R: %d G: %d B: %d W: %d L: %d (#%02X%02X%02X%02X W %02X)", c.R, c.G, c.B, c.W, c.L, c.R, c.G, c.B, c.L, c.W)
+	}
 	return fmt.Sprintf("R: %d G: %d B: %d L: %d (#%02X%02X%02X%02X)", c.R, c.G, c.B, c.L, c.R, c.G, c.B, c.L)
 }
 
@@ -85,9 +93,18 @@ func (originalColour Colour) Blend(NewColour Colour, Ratio float32) (result Colo
 	result.G = uint8((float32(NewColour.G)-float32(originalColour.G))*Ratio + float32(originalColour.G))
 	result.R = uint8((float32(NewColour.R)-float32(originalColour.R))*Ratio + float32(originalColour.R))
 	result.L = uint8((float32(NewColour.L)-float32(originalColour.L))*Ratio + float32(originalColour.L))
+	result.W = uint8((float32(NewColour.W)-float32(originalColour.W))*Ratio + float32(originalColour.W))
 	return result
 }
 
+/*
+BlendEased returns a new Colour that is on the way between this and NewColour, with progress t from 0 to 1
+mapped through the ease function, for example one of the curves from the easing package.
+*/
+func (originalColour Colour) BlendEased(NewColour Colour, t float64, ease func(t float64) float64) Colour {
+	return originalColour.Blend(NewColour, float32(ease(t)))
+}
+
 // Off represents an unlit LED
 var Off Colour = Colour{R: 0, G: 0, B: 0, L: 0}
 
@@ -116,44 +133,78 @@ func NewColour(red, green, blue, luminosity uint8) Colour {
 }
 
 /*
-NewColourFromHex takes a string in hex #RGBL or #RGB format and returns that colour.
+NewColourW creates a new Colour for RGBW strips, with a white channel.
 */
-func NewColourFromStr(clr string) Colour {
-	newClr := Colour{
-		R: 0,
-		G: 0,
-		B: 0,
-		L: 255,
-	}
+func NewColourW(red, green, blue, white, luminosity uint8) Colour {
+	return Colour{R: red, G: green, B: blue, W: white, L: luminosity}
+}
 
-	if len(clr) == 7 {
-		fmt.Sscanf(clr, "#%02X%02X%02X", &newClr.R, &newClr.G, &newClr.B)
-	}
+/*
+NewColourFromHex takes a string in hex #RGBL or #RGB format and returns that colour.
 
-	if len(clr) == 9 {
-		fmt.Sscanf(clr, "#%02X%02X%02X%02X", &newClr.R, &newClr.G, &newClr.B, &newClr.L)
+Any format accepted by ParseColour may be used.  Colours that cannot be parsed are returned as black, use
+ParseColour to detect them.
+*/
+func NewColourFromStr(clr string) Colour {
+	newClr, err := ParseColour(clr)
+	if err != nil {
+		return Colour{
+			R: 0,
+			G: 0,
+			B: 0,
+			L: 255,
+		}
 	}
 	return newClr
 }
 
 // ConfigFunc functions are used to change internal configuration of a Controller on creation.
+// Invalid settings are reported by NewControllerE, NewController ignores them.
 type ConfigFunc func(ctl *Controller)
 
+/*
+Internal method used by ConfigFuncs to record an invalid setting.  Only the first error is kept.
+*/
+func (ctl *Controller) invalidConfig(err error) {
+	if ctl.configErr == nil {
+		ctl.configErr = err
+	}
+}
+
+// orderAliases maps the names of common LED types to their usual colour order.
+var orderAliases = map[string]string{
+	"apa102":   "bgr",
+	"dotstar":  "bgr",
+	"sk9822":   "bgr",
+	"ws2812":   "grb",
+	"neopixel": "grb",
+	"sk6812":   "grbw",
+}
+
 // OrderConfig returns a configuration function to set the order of the RGB elements in the LED strip.
-// The default order is bgr (Blue, Green then Red)
+// The default order is bgr (Blue, Green then Red).  RGBW strips have a fourth w for the white channel, for
+// example grbw.  Each of r, g and b must be given exactly once, in upper or lower case.  The name of a common
+// LED type (apa102, dotstar, sk9822, ws2812, neopixel or sk6812) may be given instead for its usual order.
 func OrderConfig(order string) (ConfigFunc, error) {
-	// TODO - add validation of the order string
-	lowerOrder := strings.ToLower(order)
+	lowerOrder := strings.ToLower(strings.TrimSpace(order))
+	if alias, ok := orderAliases[lowerOrder]; ok {
+		lowerOrder = alias
+	}
+	for i, c := range lowerOrder {
+		if !strings.ContainsRune("rgbw", c) {
+			return nil, fmt.Errorf("Order configuration %q contains %q, only r, g, b and w are supported", order, c)
+		}
+		if strings.IndexRune(lowerOrder, c) != i {
+			return nil, fmt.Errorf("Order configuration %q contains %q more than once", order, c)
+		}
+	}
 	rOrder := strings.IndexAny(lowerOrder, "r")
 	gOrder := strings.IndexAny(lowerOrder, "g")
 	bOrder := strings.IndexAny(lowerOrder, "b")
+	wOrder := strings.IndexAny(lowerOrder, "w")
 
 	if rOrder == -1 || gOrder == -1 || bOrder == -1 {
-		return nil, errors.New("Order configuration must contain rgb")
-	}
-
-	if len(order) != 3 {
-		return nil, errors.New("Additional characters other than rgb are not supported")
+		return nil, fmt.Errorf("Order configuration %q must contain each of r, g and b", order)
 	}
 
 	return func(ctl *Controller) {
@@ -161,6 +212,12 @@ func OrderConfig(order string) (ConfigFunc, error) {
 		ctl.rOffset = rOrder + 1
 		ctl.gOffset = gOrder + 1
 		ctl.bOffset = bOrder + 1
+		ctl.wOffset = 0
+		ctl.packetSize = ledPacketSize
+		if wOrder != -1 {
+			ctl.wOffset = wOrder + 1
+			ctl.packetSize = ledPacketSize + 1
+		}
 	}, nil
 }
 
@@ -171,6 +228,7 @@ The Controller defaults to a gamma correction value of 2.8 when writing out colo
 func DisableGammaCorrectionConfig() ConfigFunc {
 	return func(ctl *Controller) {
 		ctl.gammaFunc = nil
+		ctl.separableGamma = true
 	}
 }
 
@@ -182,6 +240,99 @@ The gammafunc is called each time a value is written to the LEDs with Update()
 func SetCustomGammaCorrectionConfig(gammafunc func(in Colour) Colour) ConfigFunc {
 	return func(ctl *Controller) {
 		ctl.gammaFunc = gammafunc
+		ctl.separableGamma = false
+	}
+}
+
+/*
+A Chip identifies the LED driver chip used in the strip.
+
+The chips share the same wire format but differ in how the brightness bits are interpreted and in how
+many clock pulses are needed after the last LED.
+*/
+type Chip int
+
+const (
+	// ChipAPA102 is the default.  The 5-bit brightness is a PWM duty cycle applied on top of the RGB PWM.
+	ChipAPA102 Chip = iota
+	// ChipSK9822 uses the 5-bit brightness to set a constant-current level rather than a PWM duty cycle.
+	// Global brightness is applied by scaling the gamma corrected RGB values, and a 32-bit reset frame is
+	// sent ahead of the end frame so that the new values are latched in the same Update().
+	ChipSK9822
+)
+
+/*
+String returns the name of the chip.
+*/
+func (c Chip) String() string {
+	switch c {
+	case ChipAPA102:
+		return "APA102"
+	case ChipSK9822:
+		return "SK9822"
+	}
+	return fmt.Sprintf("Chip(%d)", int(c))
+}
+
+/*
+ChipConfig selects the LED chip used in the strip.  The default is ChipAPA102.
+*/
+func ChipConfig(chip Chip) ConfigFunc {
+	return func(ctl *Controller) {
+		if chip != ChipAPA102 && chip != ChipSK9822 {
+			ctl.invalidConfig(fmt.Errorf("Unknown chip %v", chip))
+		}
+		ctl.chip = chip
+	}
+}
+
+/*
+ChunkSizeConfig limits the number of bytes sent in a single transfer to the SPI bus.
+
+Update() splits the message across several Write calls of at most size bytes.  This is needed for long strips
+on SPI drivers that limit the transfer size, such as the 4096 byte default on the Raspberry Pi.
+A size of 0 (the default) sends the whole message in one transfer.  Chunking only applies when
+the Controller was created with an io.Writer that does not implement Driver.
+*/
+func ChunkSizeConfig(size int) ConfigFunc {
+	return func(ctl *Controller) {
+		if size < 0 {
+			ctl.invalidConfig(errors.New("Chunk size must not be negative"))
+			return
+		}
+		if wd, ok := ctl.driver.(*writerDriver); ok {
+			wd.chunkSize = size
+		}
+	}
+}
+
+/*
+LazyEncodeConfig delays encoding colours into the message buffer until Update() is called.
+
+By default each SetColour encodes the colour immediately and SetGlobalBrightness re-encodes the whole strip.
+In lazy mode any change marks the buffer and the whole strip is encoded once in the next Update().  This is
+faster for long strips where most LEDs change each frame, or where colours are set more than once per frame.
+*/
+func LazyEncodeConfig() ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.lazyEncode = true
+	}
+}
+
+/*
+RetryConfig makes Update() retry sending a message that fails with a transient error.
+
+The message is sent up to attempts times in total.  The first retry waits for backoff, with the wait doubling
+for each further retry.  ShortWriteError values and errors with a Temporary() method returning true are
+treated as transient.  Other errors are returned immediately.
+*/
+func RetryConfig(attempts int, backoff time.Duration) ConfigFunc {
+	return func(ctl *Controller) {
+		if attempts < 0 || backoff < 0 {
+			ctl.invalidConfig(errors.New("Retry attempts and backoff must not be negative"))
+		}
+		ctl.retryAttempts = attempts
+		ctl.retryBackoff = backoff
 	}
 }
 
@@ -189,7 +340,10 @@ func SetCustomGammaCorrectionConfig(gammafunc func(in Colour) Colour) ConfigFunc
 var defaultOrder, _ = OrderConfig("bgr")
 
 // defaultGamma uses a table of pre-computed gamma values using a global 2.8 value
-var defaultGamma = SetCustomGammaCorrectionConfig(defaultGammaFunc)
+var defaultGamma = separableGammaConfig(defaultGammaFunc)
+
+// ErrClosed is returned when updating a Controller that has been closed.
+var ErrClosed = errors.New("Controller is closed")
 
 /*
 A Controller provides a friendly interface to the Dotstar stip of LEDs.
@@ -197,41 +351,158 @@ A Controller provides a friendly interface to the Dotstar stip of LEDs.
 Methods are NOT safe to call from multiple goroutines concurrently.
 */
 type Controller struct {
-	// spi is a Writer to the underlying SPI
-	spi io.Writer
+	// driver sends the buffer to the underlying SPI
+	driver Driver
 	// ledColours holds the value that will be written to the LEDs on next Update()
 	ledColours []Colour
 	// buffer is used to construct the message stream to be sent in Update()
 	buffer []byte
-	// count is the number of LEDs in this Dotstar string
+	// count is the number of LEDs in this Dotstar string, as seen through any pixelMap
 	count int
+	// physicalCount is the number of LEDs in the chain.  This differs from count when pixels are remapped.
+	physicalCount int
+	// remaps transform the mapping from position to physical LEDs, pixelMap holds the result or nil if there is none.
+	remaps   []func(pixels [][]int) [][]int
+	pixelMap [][]int
 	// rOffset, gOffset and bOffset hold the order of Red, Green and Blue to be used when writing the colour data
 	rOffset, gOffset, bOffset int
+	// wOffset is the position of the white channel for RGBW strips, or 0 for RGB strips.
+	wOffset int
+	// packetSize is the number of bytes sent per LED, ledPacketSize for RGB or one more for RGBW.
+	packetSize int
+	// autoWhite moves the white shared by red, green and blue into the white channel.
+	autoWhite bool
 	// brightness holds the global brightness which is used to scale the per-LED Luminosity values.
 	brightness uint8
 	// gammaFunc may be nil (no gamma applied) or a function that pre-processes the Colour to apply gamma correction.
 	// The function is call when preparing the buffer contents.
 	gammaFunc func(Colour) Colour
+	// chip is the LED chip in use, it controls the footer size and how brightness is applied.
+	chip Chip
+	// protocol holds the message header and the marker bits sent at the start of each LED packet.
+	protocol Protocol
+	// luminosityCurve, if set, is applied to each LED's luminosity.  foldLuminosity applies it to RGB instead.
+	luminosityCurve *GammaTable
+	foldLuminosity  bool
+	// brightnessCurve, if set, maps the global brightness to the light output wanted.
+	brightnessCurve BrightnessCurve
+	// separableGamma is set when gammaFunc corrects each channel independently, allowing tables to be used.
+	separableGamma bool
+	// tables holds the encoding of each value for the current settings, or nil if they need building.
+	tables *encodeTables
+	// correction holds the scaling applied to the red, green and blue channels.
+	correction [3]float32
+	// temperature holds the channel scaling used to simulate a colour temperature.
+	temperature [3]float32
+	// fade holds the progress of a FadeBrightness, or nil if the brightness is not changing.
+	fade *brightnessFade
+	// lazyEncode delays encoding colours into the buffer until Update(), dirty is set when this is needed.
+	lazyEncode, dirty bool
+	// retryAttempts is the number of times Update() tries to send the message, retryBackoff the first delay between attempts.
+	retryAttempts int
+	retryBackoff  time.Duration
+	// closed is set once Close() has been called.
+	closed bool
+	// softStart is the time taken to ramp up the brightness when lighting a dark strip, with dark set once a
+	// frame with every LED off has been sent.
+	softStart time.Duration
+	dark      bool
+	// thermal, if set, reduces the brightness when the strip is too hot.
+	thermal *thermalLimit
+	// partialUpdate sends only changed LEDs to a RandomAccessDriver, sent holds the last message sent or nil if
+	// the whole message must be sent next.
+	partialUpdate bool
+	sent          []byte
+	// configErr holds the first invalid setting made by a ConfigFunc, returned by NewControllerE.
+	configErr error
+	// stats records the frames sent by Update().
+	stats frameStats
+	// sceneStore keeps the scenes saved with SaveScene.
+	sceneStore SceneStore
+	// middleware is run on every Update(), using middlewareFrame to hold the frame being changed.
+	middleware      []Middleware
+	middlewareFrame []Colour
+	// notify is the notification drawn over each frame, guarded by notifyLock as Notify may be called from any
+	// goroutine.  notified is set while the buffer holds a frame with a notification drawn over it.
+	notifyLock sync.Mutex
+	notify     *notification
+	notified   bool
+	// reopener, if set, replaces the driver when updates keep failing.
+	reopener *Reopener
+	// endFrameOnes sends the end frame as 0xFF bytes rather than zeros, with endFrameExtra bytes added to it.
+	endFrameOnes  bool
+	endFrameExtra int
+	// statePath, if set, is the file the state is saved to on Close(), with stateParams saved alongside it.
+	statePath   string
+	stateParams map[string]string
+	// now returns the current time and sleep waits, they are replaced in tests.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// brightnessFade tracks a change in global brightness over time.
+type brightnessFade struct {
+	start    time.Time
+	duration time.Duration
+	from, to uint8
+	// level is the current global brightness, including the fractional part.
+	level float32
 }
 
 /*
 NewController creates a new Controller.
 
-SpiOut is the io.Writer that sends written bytes to the Dotstar LED strip.
+SpiOut is the io.Writer that sends written bytes to the Dotstar LED strip.  If SpiOut also implements Driver
+then WriteFrame is used to send each message.
 LedCount is the number of LEDs present in the strip.
 
-The default settings are for LED colours to be  written out bgr and a gamma correction of 2.8 to be applied.
+The default settings are for an APA102 strip with LED colours to be  written out bgr and a gamma correction of 2.8 to be applied.
 Pass ConfigFunc values to override these settings.
 */
 func NewController(SpiOut io.Writer, LedCount int, cfgs ...ConfigFunc) *Controller {
-	// footerSize := int(math.Ceil(float64(LedCount-1)/16)) + 2
-	footerSize := int(math.Ceil(float64(LedCount-1)/16)) + 2
+	return NewDriverController(WriterDriver(SpiOut), LedCount, cfgs...)
+}
+
+/*
+NewControllerE creates a new Controller as NewController does, but returns an error instead of proceeding when
+the settings are invalid.
+
+SpiOut must not be nil and LedCount must be at least 1.  Any invalid setting passed to a ConfigFunc, for example a
+negative ChunkSizeConfig, is also returned as an error.
+*/
+func NewControllerE(SpiOut io.Writer, LedCount int, cfgs ...ConfigFunc) (*Controller, error) {
+	if SpiOut == nil {
+		return nil, errors.New("SpiOut must not be nil")
+	}
+	if LedCount < 1 {
+		return nil, fmt.Errorf("LED count must be at least 1, not %d", LedCount)
+	}
+	ctl := NewController(SpiOut, LedCount, cfgs...)
+	if ctl.configErr != nil {
+		return nil, ctl.configErr
+	}
+	return ctl, nil
+}
+
+/*
+NewDriverController creates a new Controller that sends messages to the strip using a Driver.
+
+This is the same as NewController, but for buses that need the whole message in a single call.
+*/
+func NewDriverController(SpiOut Driver, LedCount int, cfgs ...ConfigFunc) *Controller {
 	ctl := &Controller{
-		spi:        SpiOut,
-		count:      LedCount,
-		ledColours: make([]Colour, LedCount, LedCount),
-		buffer:     make([]byte, headerSize+LedCount*ledPacketSize+footerSize, headerSize+LedCount*ledPacketSize+footerSize),
-		brightness: 255,
+		driver:        SpiOut,
+		count:         LedCount,
+		physicalCount: LedCount,
+		ledColours:    make([]Colour, LedCount, LedCount),
+		brightness:    255,
+		chip:          ChipAPA102,
+		protocol:      APA102Protocol,
+		correction:    [3]float32{1, 1, 1},
+		temperature:   [3]float32{1, 1, 1},
+		sceneStore:    NewMemorySceneStore(),
+		now:           time.Now,
+		sleep:         time.Sleep,
 	}
 
 	defaultOrder(ctl)
@@ -240,22 +511,194 @@ func NewController(SpiOut io.Writer, LedCount int, cfgs ...ConfigFunc) *Controll
 	for _, cfg := range cfgs {
 		cfg(ctl)
 	}
+	ctl.applyRemaps()
+
+	// The buffer size depends on the chip, so can only be allocated once configuration is complete.
+	ctl.allocateBuffer()
 
 	return ctl
 }
 
+/*
+Internal method used to allocate the buffer for the current LED count and encode all of the colours.
+*/
+func (ctl *Controller) allocateBuffer() {
+	header := len(ctl.protocol.Header)
+	bufferSize := header + ctl.physicalCount*ctl.packetSize + ctl.footerSize()
+	ctl.buffer = make([]byte, bufferSize, bufferSize)
+	ctl.tables = nil
+	ctl.sent = nil
+	copy(ctl.buffer, ctl.protocol.Header)
+	// Physical LEDs not mapped to a position are left off.
+	for i := 0; i < ctl.physicalCount; i++ {
+		ctl.buffer[header+i*ctl.packetSize] = ctl.protocol.Marker
+	}
+	if ctl.endFrameOnes {
+		ctl.fillEndFrame()
+	}
+	for i, clr := range ctl.ledColours {
+		ctl.updateBuffer(i, clr)
+	}
+	ctl.dirty = false
+	ctl.announceFrameFormat()
+}
+
+/*
+Resize changes the number of LEDs in the strip.
+
+Existing colours are kept, LEDs added to the end of the strip are Off.  If the pixels are remapped, for example
+with ReverseConfig, newCount is the number of LEDs in the chain and the mapping is worked out again.  This does
+not trigger Update(), so when shrinking a strip call Clear() and Update() first to turn off the LEDs being removed.
+*/
+func (ctl *Controller) Resize(newCount int) {
+	if newCount < 0 {
+		newCount = 0
+	}
+	ctl.physicalCount = newCount
+	ctl.applyRemaps()
+	ctl.allocateBuffer()
+}
+
+/*
+Internal method used to calculate the number of bytes sent after the last LED.
+*/
+func (ctl *Controller) footerSize() int {
+	footerSize := endFrameSize(ctl.physicalCount) + ctl.endFrameExtra
+	if ctl.chip == ChipSK9822 {
+		// SK9822 needs a reset frame of 32 zero bits to latch the data.
+		footerSize += headerSize
+	}
+	return footerSize
+}
+
+/*
+Internal function used to calculate the number of end frame bytes needed to clock the data through count LEDs.
+
+Each LED delays the data by half a clock period, so count/2 extra clock pulses are needed to push the data to
+the last LED.  One byte gives 8 pulses, with 2 bytes more allowed for LEDs that are slow to latch.
+*/
+func endFrameSize(count int) int {
+	return int(math.Ceil(float64(count-1)/16)) + 2
+}
+
 /*
 Update sends the current Colour values to the LEDs.
 */
 func (ctl *Controller) Update() error {
-	n, err := ctl.spi.Write(ctl.buffer)
-	if err != nil {
+	return ctl.update(ctl.driver.WriteFrame, nil)
+}
+
+/*
+Internal method used to prepare the buffer and send it using the given write function.
+
+If beforeWrite is not nil it is called once the frame is ready, just before it is first written by either the
+write function or a partial update.
+*/
+func (ctl *Controller) update(write func(frame []byte) error, beforeWrite func()) error {
+	if ctl.closed {
+		return ErrClosed
+	}
+	start := ctl.now()
+	if ctl.softStart > 0 {
+		ctl.checkSoftStart()
+	}
+	if ctl.fade != nil {
+		ctl.stepFade()
+	}
+	if ctl.thermal != nil {
+		ctl.checkTemperature()
+	}
+	notify := ctl.currentNotification()
+	if notify == nil && ctl.notified {
+		// Encode the colours again without the notification.
+		ctl.notified, ctl.dirty = false, true
+	}
+	if len(ctl.middleware) > 0 || notify != nil {
+		ctl.runMiddleware(notify)
+		ctl.notified = notify != nil
+		ctl.dirty = false
+	}
+	if ctl.dirty {
+		for i, clr := range ctl.ledColours {
+			ctl.updateBuffer(i, clr)
+		}
+		ctl.dirty = false
+	}
+
+	size := len(ctl.buffer)
+	if driver, ok := ctl.driver.(RandomAccessDriver); ok && ctl.partialUpdate && ctl.sent != nil {
+		write = func(frame []byte) error {
+			var err error
+			size, err = ctl.writeChanged(driver, frame)
+			return err
+		}
+	}
+
+	if beforeWrite != nil {
+		beforeWrite()
+	}
+	err := write(ctl.buffer)
+	delay := ctl.retryBackoff
+	for attempt := 1; attempt < ctl.retryAttempts && err != nil && isTransient(err); attempt++ {
+		ctl.sleep(delay)
+		delay *= 2
+		err = write(ctl.buffer)
+	}
+	if ctl.reopener != nil {
+		err = ctl.superviseUpdate(err)
+	}
+	if err == nil {
+		ctl.stats.record(start, ctl.now(), size)
+	}
+	if ctl.partialUpdate {
+		ctl.recordSent(err)
+	}
+	return err
+}
+
+/*
+UpdateContext sends the current Colour values to the LEDs unless ctx is already done.
+
+If the Driver implements ContextDriver the context is also passed to it, allowing a slow write to be abandoned.
+*/
+func (ctl *Controller) UpdateContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-	if n != len(ctl.buffer) {
-		return errors.New("Unable to send the full LED colour buffer to device")
+	if _, ok := ctl.driver.(ContextDriver); !ok {
+		return ctl.Update()
 	}
-	return nil
+	return ctl.update(func(frame []byte) error {
+		return ctl.driver.(ContextDriver).WriteFrameContext(ctx, frame)
+	}, nil)
+}
+
+/*
+Close turns off all LEDs, sends this to the strip and closes the Driver.
+
+Animations interrupted on shutdown would otherwise leave the strip showing the last frame.  With
+PersistStateConfig the state is saved instead and the LEDs are left as they are.  Once closed, Update() returns
+ErrClosed.
+*/
+func (ctl *Controller) Close() error {
+	if ctl.closed {
+		return ErrClosed
+	}
+	var err error
+	if ctl.statePath != "" {
+		// The state will be restored on restart, so leave the strip showing it.
+		err = ctl.SaveState(ctl.statePath)
+	} else {
+		ctl.fade = nil
+		ctl.cancelNotification()
+		ctl.Clear()
+		err = ctl.Update()
+	}
+	ctl.closed = true
+	if closeErr := ctl.driver.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 /*
@@ -267,6 +710,13 @@ func (ctl *Controller) Clear() {
 	}
 }
 
+/*
+Len returns the number of LEDs in the strip.
+*/
+func (ctl *Controller) Len() int {
+	return ctl.count
+}
+
 /*
 Snapshot returns a copy of the currently set colours.
 
@@ -278,18 +728,39 @@ func (ctl *Controller) Snapshot() []Colour {
 	return result
 }
 
+/*
+SnapshotInto copies the currently set colours into dst without allocating, returning the number copied.
+
+If dst is shorter than the strip only the first len(dst) colours are copied.
+*/
+func (ctl *Controller) SnapshotInto(dst []Colour) int {
+	return copy(dst, ctl.ledColours)
+}
+
+/*
+Colours returns the currently set colours without copying them.
+
+The slice is owned by the Controller and must NOT be modified, use SetColour or SetColours instead.  It is only
+valid until the next call that changes the colours or Resize().
+*/
+func (ctl *Controller) Colours() []Colour {
+	return ctl.ledColours[:ctl.count:ctl.count]
+}
+
 /*
 SetGlobalBrightness scales the maximum brightness of any colour to be capped at the given value.
 
 Only the top 5 bits are useful, so a minimum increment change is 8.
-Set to 255 for maximum brightness.
+Set to 255 for maximum brightness.  With BrightnessCurveConfig the value is mapped through the curve first.
 */
 func (ctl *Controller) SetGlobalBrightness(brightness uint8) {
+	ctl.fade = nil
 	ctl.brightness = brightness
+	ctl.tables = nil
 
 	// Update the buffer to reflect this.
 	for i, clr := range ctl.ledColours {
-		ctl.updateBuffer(i, clr)
+		ctl.encode(i, clr)
 	}
 }
 
@@ -303,6 +774,45 @@ func (ctl *Controller) GetGlobalBrightness() uint8 {
 	return ctl.brightness
 }
 
+/*
+FadeBrightness changes the global brightness to target over the given duration.
+
+The brightness is moved towards the target each time Update() is called, so the fade is only as smooth as the
+animation loop.  As the LEDs only support 32 brightness levels, the steps in between are made by scaling
+the RGB values.  Calling SetGlobalBrightness stops the fade.
+*/
+func (ctl *Controller) FadeBrightness(target uint8, duration time.Duration) {
+	if duration <= 0 {
+		ctl.SetGlobalBrightness(target)
+		return
+	}
+	ctl.fade = &brightnessFade{
+		start:    ctl.now(),
+		duration: duration,
+		from:     ctl.brightness,
+		to:       target,
+		level:    float32(ctl.brightness),
+	}
+}
+
+/*
+Internal method used to move the global brightness along the current fade.
+*/
+func (ctl *Controller) stepFade() {
+	progress := float32(ctl.now().Sub(ctl.fade.start)) / float32(ctl.fade.duration)
+	if progress >= 1 {
+		ctl.SetGlobalBrightness(ctl.fade.to)
+		return
+	}
+	ctl.fade.level = float32(ctl.fade.from) + (float32(ctl.fade.to)-float32(ctl.fade.from))*progress
+	ctl.brightness = uint8(ctl.fade.level)
+	ctl.tables = nil
+
+	for i, clr := range ctl.ledColours {
+		ctl.encode(i, clr)
+	}
+}
+
 /*
 SetColour records the Colour that an LED should be set to at the next Update().
 
@@ -316,7 +826,33 @@ func (ctl *Controller) SetColour(position int, colour Colour) {
 
 	ctl.ledColours[position] = colour
 
-	ctl.updateBuffer(position, colour)
+	ctl.encode(position, colour)
+}
+
+/*
+SetLuminosity changes the per-LED brightness at position, keeping its red, green and blue values.
+
+This lets effects animate brightness without recomputing colours.  Gamma correction is only applied to the
+red, green and blue values, the luminosity is sent to the LED's 5-bit brightness field as given.
+*/
+func (ctl *Controller) SetLuminosity(position int, l uint8) {
+	if position >= ctl.count || position < 0 {
+		return
+	}
+	colour := ctl.ledColours[position]
+	colour.L = l
+	ctl.SetColour(position, colour)
+}
+
+/*
+SetColourRGB changes the red, green and blue values at position, keeping its luminosity and white.
+*/
+func (ctl *Controller) SetColourRGB(position int, r, g, b uint8) {
+	if position >= ctl.count || position < 0 {
+		return
+	}
+	current := ctl.ledColours[position]
+	ctl.SetColour(position, Colour{R: r, G: g, B: b, W: current.W, L: current.L})
 }
 
 /*
@@ -325,11 +861,36 @@ SetColours updates all of the LED colours to the values given.
 If more Colour values are given than LEDs, the additional Colours are ignored.
 */
 func (ctl *Controller) SetColours(clrs []Colour) {
-	for pos, c := range clrs {
-		if pos >= ctl.count {
+	ctl.SetColoursAt(0, clrs)
+}
+
+/*
+SetColoursAt updates the LED colours starting at position offset to the values given.
+
+Colours that would fall outside of the strip are ignored.  The colours are copied and encoded in a single pass,
+which is faster than calling SetColour for each LED.
+*/
+func (ctl *Controller) SetColoursAt(offset int, clrs []Colour) {
+	if offset < 0 {
+		if -offset >= len(clrs) {
 			return
 		}
-		ctl.SetColour(pos, c)
+		clrs = clrs[-offset:]
+		offset = 0
+	}
+	if offset >= ctl.count {
+		return
+	}
+
+	n := copy(ctl.ledColours[offset:], clrs)
+	if ctl.lazyEncode {
+		if n > 0 {
+			ctl.dirty = true
+		}
+		return
+	}
+	for i, clr := range ctl.ledColours[offset : offset+n] {
+		ctl.updateBuffer(offset+i, clr)
 	}
 }
 
@@ -350,22 +911,116 @@ func (ctl *Controller) GetColour(position int) Colour {
 	return ctl.ledColours[position]
 }
 
+/*
+Internal method used to encode a colour into the buffer, or to mark the buffer for encoding by Update() in lazy mode.
+*/
+func (ctl *Controller) encode(position int, colour Colour) {
+	if ctl.lazyEncode {
+		ctl.dirty = true
+		return
+	}
+	ctl.updateBuffer(position, colour)
+}
+
 /*
 Internal method used to update the buffer to reflect the given colour and global brightness.
 */
 func (ctl *Controller) updateBuffer(position int, colour Colour) {
-	bufferOffset := headerSize + position*ledPacketSize
+	if ctl.autoWhite {
+		colour = colour.ExtractWhite()
+	}
+	if ctl.pixelMap == nil {
+		ctl.encodeInto(ctl.packet(position), colour)
+		return
+	}
+	physical := ctl.pixelMap[position]
+	if len(physical) == 0 {
+		return
+	}
+	first := ctl.packet(physical[0])
+	ctl.encodeInto(first, colour)
+	for _, p := range physical[1:] {
+		copy(ctl.packet(p), first)
+	}
+}
+
+/*
+Internal method used to encode colour into packet, using the encoding tables when they can be used.
+*/
+func (ctl *Controller) encodeInto(packet []byte, colour Colour) {
+	if ctl.tables == nil && ctl.fade == nil && ctl.brightnessCurve == nil && !ctl.foldLuminosity && ctl.separableGamma {
+		ctl.buildTables()
+	}
+	if t := ctl.tables; t != nil {
+		packet[0] = t.brightness[colour.L]
+		packet[ctl.rOffset] = t.channels[0][colour.R]
+		packet[ctl.gOffset] = t.channels[1][colour.G]
+		packet[ctl.bOffset] = t.channels[2][colour.B]
+		if ctl.wOffset != 0 {
+			packet[ctl.wOffset] = t.channels[3][colour.W]
+		}
+		return
+	}
+	ctl.encodePacket(packet, colour)
+}
+
+/*
+Internal method used to work out the packet for colour, applying brightness, gamma and colour correction.
+
+This is the general form of the encoding, updateBuffer uses tables built from it where possible.
+*/
+func (ctl *Controller) encodePacket(packet []byte, colour Colour) {
 	// Write out the brightness
 	brightness := colour.L
-	if ctl.brightness != 255 {
-		brightness = uint8(float32(ctl.brightness) * float32(brightness) / 255)
+	if ctl.luminosityCurve != nil {
+		brightness = ctl.luminosityCurve[brightness]
+	}
+	level := float32(ctl.brightness)
+	if ctl.fade != nil {
+		level = ctl.fade.level
+	}
+	if ctl.brightnessCurve != nil {
+		level = ctl.curveBrightness(level)
+	}
+	if ctl.thermal != nil {
+		level *= 1 - ctl.thermal.derate
+	}
+	// scale is applied to the RGB values after gamma correction, along with colour and temperature correction.
+	var scale float32 = 1
+	switch {
+	case ctl.foldLuminosity:
+		// Send full brightness and apply it all through RGB.
+		scale = level / 255 * float32(brightness) / 255
+		brightness = 255
+	case ctl.chip == ChipSK9822:
+		// Changing the current level shifts the colour, so scale the PWM values instead.
+		scale = level / 255
+	case ctl.fade != nil || ctl.brightnessCurve != nil:
+		// Use the next 5-bit level up and scale RGB down to reach the exact brightness.
+		wanted := level * float32(brightness) / 255 * 31 / 255
+		steps := float32(math.Ceil(float64(wanted)))
+		if steps > 0 {
+			scale = wanted / steps
+		}
+		brightness = uint8(steps) << 3
+	case level != 255:
+		brightness = uint8(level * float32(brightness) / 255)
 	}
 	if ctl.gammaFunc != nil {
 		// Apply gamma correction.
 		colour = ctl.gammaFunc(colour)
 	}
-	ctl.buffer[bufferOffset] = brightness>>3 | brightnessHeader
-	ctl.buffer[bufferOffset+ctl.rOffset] = colour.R
-	ctl.buffer[bufferOffset+ctl.bOffset] = colour.B
-	ctl.buffer[bufferOffset+ctl.gOffset] = colour.G
+	if scale != 1 || ctl.correction != [3]float32{1, 1, 1} || ctl.temperature != [3]float32{1, 1, 1} {
+		colour.R = scaleChannel(colour.R, scale*ctl.correction[0]*ctl.temperature[0])
+		colour.G = scaleChannel(colour.G, scale*ctl.correction[1]*ctl.temperature[1])
+		colour.B = scaleChannel(colour.B, scale*ctl.correction[2]*ctl.temperature[2])
+	}
+	packet[0] = brightness>>3 | ctl.protocol.Marker
+	packet[ctl.rOffset] = colour.R
+	packet[ctl.bOffset] = colour.B
+	packet[ctl.gOffset] = colour.G
+	if ctl.wOffset != 0 {
+		// White has no colour to correct, so only the brightness scale applies.
+		packet[ctl.wOffset] = scaleChannel(colour.W, scale)
+	}
 }
@@ -1,7 +1,12 @@
 package dotstar
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestColourSetup1(t *testing.T) {
@@ -17,3 +22,270 @@ func TestColourSetup2(t *testing.T) {
 		t.Errorf("Got colour %v expected #FF000080\n", newClr)
 	}
 }
+
+func TestSK9822Footer(t *testing.T) {
+	apa := NewController(&bytes.Buffer{}, 30)
+	sk := NewController(&bytes.Buffer{}, 30, ChipConfig(ChipSK9822))
+	if len(sk.buffer) != len(apa.buffer)+headerSize {
+		t.Errorf("Got SK9822 buffer size %d expected %d\n", len(sk.buffer), len(apa.buffer)+headerSize)
+	}
+}
+
+func TestSK9822Brightness(t *testing.T) {
+	sk := NewController(&bytes.Buffer{}, 1, ChipConfig(ChipSK9822), DisableGammaCorrectionConfig())
+	sk.SetGlobalBrightness(128)
+	sk.SetColour(0, NewColour(200, 100, 0, 255))
+	packet := sk.buffer[headerSize : headerSize+ledPacketSize]
+	if packet[0] != 0xFF {
+		t.Errorf("Got brightness byte %X expected FF\n", packet[0])
+	}
+	if packet[3] != 100 || packet[2] != 50 || packet[1] != 0 {
+		t.Errorf("Got packet %v expected RGB scaled by half\n", packet)
+	}
+}
+
+func TestFadeBrightness(t *testing.T) {
+	now := time.Now()
+	strip := NewController(&bytes.Buffer{}, 1, DisableGammaCorrectionConfig())
+	strip.now = func() time.Time { return now }
+	strip.SetColour(0, White)
+	strip.FadeBrightness(0, time.Second)
+
+	now = now.Add(time.Second / 2)
+	strip.Update()
+	if strip.GetGlobalBrightness() != 127 {
+		t.Errorf("Got brightness %d half way expected 127\n", strip.GetGlobalBrightness())
+	}
+	// 127.5 of 255 is 15.5 of 31 steps, sent as 16 steps with the RGB scaled to make up the difference.
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	if packet[0] != 16|brightnessHeader || packet[1] != 247 {
+		t.Errorf("Got packet % X half way\n", packet)
+	}
+
+	now = now.Add(time.Second)
+	strip.Update()
+	if strip.GetGlobalBrightness() != 0 || strip.fade != nil {
+		t.Errorf("Got brightness %d after fade expected 0\n", strip.GetGlobalBrightness())
+	}
+}
+
+func TestLazyEncodeConfig(t *testing.T) {
+	eager := NewController(&bytes.Buffer{}, 10)
+	out := &bytes.Buffer{}
+	lazy := NewController(out, 10, LazyEncodeConfig())
+	for _, strip := range []*Controller{eager, lazy} {
+		strip.SetColour(2, Red)
+		strip.SetGlobalBrightness(100)
+		strip.FillRange(5, 8, Green)
+		strip.Rotate(3)
+	}
+	if bytes.Equal(eager.buffer, lazy.buffer) {
+		t.Errorf("Lazy buffer was encoded before Update()\n")
+	}
+	lazy.Update()
+	if !bytes.Equal(eager.buffer, out.Bytes()) {
+		t.Errorf("Got % X expected % X\n", out.Bytes(), eager.buffer)
+	}
+}
+
+// benchmarkFrame sets every LED and changes the global brightness each frame, as a fade would.
+func benchmarkFrame(b *testing.B, cfgs ...ConfigFunc) {
+	const ledCount = 300
+	strip := NewController(ioutil.Discard, ledCount, cfgs...)
+	clrs := make([]Colour, ledCount)
+	for i := range clrs {
+		clrs[i] = NewColour(uint8(i), uint8(i*2), uint8(i*3), 255)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		strip.SetColours(clrs)
+		strip.SetGlobalBrightness(uint8(i))
+		strip.Update()
+	}
+}
+
+func BenchmarkFrameEager300(b *testing.B) {
+	benchmarkFrame(b)
+}
+
+func BenchmarkFrameLazy300(b *testing.B) {
+	benchmarkFrame(b, LazyEncodeConfig())
+}
+
+// benchmarkEncode sets every LED of a long strip at a fixed global brightness, as most animations do.
+func benchmarkEncode(b *testing.B, cfgs ...ConfigFunc) {
+	const ledCount = 1000
+	strip := NewController(ioutil.Discard, ledCount, cfgs...)
+	strip.SetGlobalBrightness(128)
+	clrs := make([]Colour, ledCount)
+	for i := range clrs {
+		clrs[i] = NewColour(uint8(i), uint8(i*2), uint8(i*3), 255)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		strip.SetColours(clrs)
+		strip.Update()
+	}
+}
+
+func BenchmarkEncode1000(b *testing.B) {
+	benchmarkEncode(b)
+}
+
+func BenchmarkEncodeCorrected1000(b *testing.B) {
+	benchmarkEncode(b, ColourCorrectionConfig(1, 0.8, 0.6), TemperatureConfig(2700))
+}
+
+// BenchmarkEncodeCustomGamma1000 measures the general encoding used when tables cannot be.
+func BenchmarkEncodeCustomGamma1000(b *testing.B) {
+	benchmarkEncode(b, SetCustomGammaCorrectionConfig(defaultGammaFunc))
+}
+
+func TestSetColoursAt(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 4)
+	strip.SetColoursAt(-1, []Colour{White, Red, Green})
+	strip.SetColoursAt(3, []Colour{Blue, White})
+	expected := []Colour{Red, Green, Off, Blue}
+	for i, want := range expected {
+		if got := strip.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+	fresh := NewController(&bytes.Buffer{}, 4)
+	for i, clr := range expected {
+		fresh.SetColour(i, clr)
+	}
+	if !bytes.Equal(strip.buffer, fresh.buffer) {
+		t.Errorf("Got buffer % X expected % X\n", strip.buffer, fresh.buffer)
+	}
+}
+
+func TestResize(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 2)
+	strip.SetColours([]Colour{Red, Green})
+	strip.Resize(40)
+	fresh := NewController(&bytes.Buffer{}, 40)
+	fresh.SetColours([]Colour{Red, Green})
+	if !bytes.Equal(strip.buffer, fresh.buffer) {
+		t.Errorf("Got buffer % X expected % X\n", strip.buffer, fresh.buffer)
+	}
+
+	strip.Resize(1)
+	if len(strip.Snapshot()) != 1 || strip.GetColour(0) != Red || strip.GetColour(1) != Off {
+		t.Errorf("Got colours %v after shrinking\n", strip.Snapshot())
+	}
+}
+
+func TestBlendEased(t *testing.T) {
+	square := func(t float64) float64 { return t * t }
+	if got := Off.BlendEased(White, 0.5, square); got != NewColour(63, 63, 63, 63) {
+		t.Errorf("Got %v expected a quarter of the way to white\n", got)
+	}
+}
+
+func TestSetLuminosityAndRGB(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 2, DisableGammaCorrectionConfig())
+	strip.SetColour(0, NewColour(10, 20, 30, 255))
+	strip.SetLuminosity(0, 64)
+	strip.SetColourRGB(0, 40, 50, 60)
+	strip.SetLuminosity(5, 64)
+	if got := strip.GetColour(0); got != NewColour(40, 50, 60, 64) {
+		t.Errorf("Got %v expected #28323C40\n", got)
+	}
+	// The brightness field holds the luminosity, the colours are unchanged.
+	if packet := strip.packet(0); packet[0] != 0xE0|64>>3 || packet[1] != 60 || packet[3] != 40 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestSnapshotInto(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 3)
+	strip.SetColours([]Colour{Red, Green, Blue})
+	dst := make([]Colour, 2)
+	if n := strip.SnapshotInto(dst); n != 2 || dst[0] != Red || dst[1] != Green {
+		t.Errorf("Got %d, %v\n", n, dst)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { strip.SnapshotInto(dst) }); allocs != 0 {
+		t.Errorf("Got %v allocations expected none\n", allocs)
+	}
+	if clrs := strip.Colours(); len(clrs) != 3 || cap(clrs) != 3 || clrs[2] != Blue {
+		t.Errorf("Got %v\n", clrs)
+	}
+}
+
+func TestNewControllerE(t *testing.T) {
+	if ctl, err := NewControllerE(ioutil.Discard, 3, ChipConfig(ChipSK9822), RetryConfig(2, time.Millisecond)); err != nil || ctl.Len() != 3 {
+		t.Errorf("Got %v expected a valid Controller\n", err)
+	}
+
+	tests := []struct {
+		name string
+		out  *bytes.Buffer
+		leds int
+		cfg  ConfigFunc
+	}{
+		{"nil writer", nil, 3, LazyEncodeConfig()},
+		{"no LEDs", &bytes.Buffer{}, 0, LazyEncodeConfig()},
+		{"chip", &bytes.Buffer{}, 3, ChipConfig(Chip(7))},
+		{"chunk size", &bytes.Buffer{}, 3, ChunkSizeConfig(-1)},
+		{"retry", &bytes.Buffer{}, 3, RetryConfig(-1, 0)},
+		{"correction", &bytes.Buffer{}, 3, ColourCorrectionConfig(1, -1, 1)},
+		{"temperature", &bytes.Buffer{}, 3, TemperatureConfig(0)},
+		{"gamma", &bytes.Buffer{}, 3, GammaConfig(2.8, 0, 2.8)},
+		{"luminosity gamma", &bytes.Buffer{}, 3, LuminosityGammaConfig(-2)},
+		{"scene store", &bytes.Buffer{}, 3, SceneStoreConfig(nil)},
+	}
+	for _, test := range tests {
+		var out io.Writer
+		if test.out != nil {
+			out = test.out
+		}
+		if ctl, err := NewControllerE(out, test.leds, test.cfg); err == nil || ctl != nil {
+			t.Errorf("%s: got %v expected an error\n", test.name, err)
+		}
+	}
+}
+
+func TestOrderConfigValidation(t *testing.T) {
+	for _, order := range []string{"rgx", "rrg", "rrgb", "rgbb", "rg", "rgbwr", "rgbww", "", "ws2811"} {
+		if _, err := OrderConfig(order); err == nil {
+			t.Errorf("Expected an error for %q\n", order)
+		}
+	}
+	if _, err := OrderConfig("rrg"); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("Got %v expected a descriptive error\n", err)
+	}
+}
+
+func TestOrderConfigLayouts(t *testing.T) {
+	tests := []struct {
+		order    string
+		expected []byte
+	}{
+		{"rgb", []byte{0xFF, 1, 2, 3}},
+		{"rbg", []byte{0xFF, 1, 3, 2}},
+		{"grb", []byte{0xFF, 2, 1, 3}},
+		{"gbr", []byte{0xFF, 2, 3, 1}},
+		{"brg", []byte{0xFF, 3, 1, 2}},
+		{"bgr", []byte{0xFF, 3, 2, 1}},
+		{"GRB", []byte{0xFF, 2, 1, 3}},
+		{" Bgr ", []byte{0xFF, 3, 2, 1}},
+		{"NeoPixel", []byte{0xFF, 2, 1, 3}},
+		{"apa102", []byte{0xFF, 3, 2, 1}},
+		{"sk6812", []byte{0xFF, 2, 1, 3, 4}},
+	}
+	for _, test := range tests {
+		cfg, err := OrderConfig(test.order)
+		if err != nil {
+			t.Errorf("Got %v for %q\n", err, test.order)
+			continue
+		}
+		out := &bytes.Buffer{}
+		ctl := NewController(out, 1, cfg, DisableGammaCorrectionConfig())
+		ctl.SetColour(0, NewColourW(1, 2, 3, 4, 255))
+		ctl.Update()
+		if packet := out.Bytes()[headerSize : headerSize+len(test.expected)]; !bytes.Equal(packet, test.expected) {
+			t.Errorf("Got % X expected % X for %q\n", packet, test.expected, test.order)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package dotstar
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+/*
+A Driver sends complete messages to the Dotstar strip.
+
+Drivers are used instead of a plain io.Writer when the underlying bus needs to know where a message starts and ends,
+for example to split it into several transfers or to toggle chip select once the whole message has been sent.
+*/
+type Driver interface {
+	// WriteFrame sends the whole message to the strip, returning an error if it was not fully sent.
+	WriteFrame(frame []byte) error
+	// Close releases the underlying bus.
+	Close() error
+}
+
+/*
+A ContextDriver is a Driver that can abandon a write when a context is done.  It is used by UpdateContext.
+*/
+type ContextDriver interface {
+	Driver
+	// WriteFrameContext sends the whole message to the strip, returning early with an error if ctx is done.
+	WriteFrameContext(ctx context.Context, frame []byte) error
+}
+
+/*
+A RandomAccessDriver is a Driver that keeps the last message sent and can replace parts of it.  It is used by
+PartialUpdateConfig to send only the LEDs that have changed.
+
+This suits buses that hold a copy of the frame, such as a frame buffer or a remote controller.  A Dotstar strip
+on a plain SPI bus needs the whole message on every update.
+*/
+type RandomAccessDriver interface {
+	Driver
+	// WriteAt replaces the bytes of the last message from offset with p and shows the result.  It has the same
+	// semantics as io.WriterAt.
+	WriteAt(p []byte, offset int64) (int, error)
+}
+
+/*
+WriterDriver adapts an io.Writer to the Driver interface.
+
+Short writes are retried with the remaining bytes until the whole frame has been written.  If the io.Writer
+also implements io.Closer then Close is passed through, otherwise Close does nothing.
+*/
+func WriterDriver(w io.Writer) Driver {
+	if driver, ok := w.(Driver); ok {
+		return driver
+	}
+	return &writerDriver{w: w}
+}
+
+// writerDriver is the Driver used for io.Writer values.
+type writerDriver struct {
+	w io.Writer
+	// chunkSize is the maximum number of bytes in each Write, or 0 for no limit.
+	chunkSize int
+}
+
+// WriteFrame loops over the io.Writer until all of frame is written.
+func (wd *writerDriver) WriteFrame(frame []byte) error {
+	written := 0
+	for written < len(frame) {
+		chunk := frame[written:]
+		if wd.chunkSize > 0 && len(chunk) > wd.chunkSize {
+			chunk = chunk[:wd.chunkSize]
+		}
+		n, err := wd.w.Write(chunk)
+		if n > 0 {
+			written += n
+		}
+		if err != nil && n < len(chunk) {
+			return &ShortWriteError{Written: written, Expected: len(frame), Err: err}
+		}
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			return &ShortWriteError{Written: written, Expected: len(frame)}
+		}
+	}
+	return nil
+}
+
+// Close closes the io.Writer if it supports it.
+func (wd *writerDriver) Close() error {
+	if closer, ok := wd.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+/*
+A ShortWriteError is returned when only part of a message could be sent to the strip.
+
+These are often caused by transient bus glitches, so are retried when RetryConfig is used.
+*/
+type ShortWriteError struct {
+	// Written is the number of bytes sent before the failure.
+	Written int
+	// Expected is the size of the message.
+	Expected int
+	// Err is the error returned by the writer, or nil if it stopped making progress without an error.
+	Err error
+}
+
+/*
+Error describes the short write.
+*/
+func (e *ShortWriteError) Error() string {
+	msg := fmt.Sprintf("Unable to send the full LED colour buffer to device, sent %d of %d bytes", e.Written, e.Expected)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+/*
+Unwrap returns the underlying writer error.
+*/
+func (e *ShortWriteError) Unwrap() error {
+	return e.Err
+}
+
+/*
+Internal function used to decide whether a failed write is worth retrying.
+
+Short writes and errors reporting themselves as temporary are retried, anything else is treated as fatal.
+*/
+func isTransient(err error) bool {
+	if _, ok := err.(*ShortWriteError); ok {
+		return true
+	}
+	if temporary, ok := err.(interface{ Temporary() bool }); ok {
+		return temporary.Temporary()
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package dotstar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// shortWriter writes at most limit bytes per call.
+type shortWriter struct {
+	limit  int
+	writes int
+	out    bytes.Buffer
+}
+
+func (sw *shortWriter) Write(p []byte) (int, error) {
+	sw.writes++
+	if len(p) > sw.limit {
+		p = p[:sw.limit]
+	}
+	return sw.out.Write(p)
+}
+
+func TestWriterDriverShortWrites(t *testing.T) {
+	sw := &shortWriter{limit: 5}
+	strip := NewController(sw, 10)
+	strip.SetColour(9, Red)
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if !bytes.Equal(sw.out.Bytes(), strip.buffer) {
+		t.Errorf("Got % X expected % X\n", sw.out.Bytes(), strip.buffer)
+	}
+	if sw.writes != (len(strip.buffer)+4)/5 {
+		t.Errorf("Got %d writes expected %d\n", sw.writes, (len(strip.buffer)+4)/5)
+	}
+}
+
+func TestChunkSizeConfig(t *testing.T) {
+	sw := &shortWriter{limit: 4096}
+	strip := NewController(sw, 300, ChunkSizeConfig(64))
+	for i := 0; i < 300; i++ {
+		strip.SetColour(i, Blue)
+	}
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if !bytes.Equal(sw.out.Bytes(), strip.buffer) {
+		t.Errorf("Buffer was not sent intact\n")
+	}
+	if sw.writes != (len(strip.buffer)+63)/64 {
+		t.Errorf("Got %d writes expected %d\n", sw.writes, (len(strip.buffer)+63)/64)
+	}
+}
+
+// closeRecorder records the frames written and whether it was closed.
+type closeRecorder struct {
+	frameRecorder
+	closed bool
+}
+
+func (cr *closeRecorder) Close() error {
+	cr.closed = true
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	out := &closeRecorder{}
+	strip := NewController(out, 3)
+	strip.Fill(White)
+	strip.Update()
+	if err := strip.Close(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if !out.closed {
+		t.Errorf("Writer was not closed\n")
+	}
+	blank := NewController(&bytes.Buffer{}, 3)
+	if len(out.frames) != 2 || !bytes.Equal(out.frames[1], blank.buffer) {
+		t.Errorf("Got frames % X expected a final blank frame\n", out.frames)
+	}
+	if err := strip.Update(); err != ErrClosed {
+		t.Errorf("Got %v expected ErrClosed\n", err)
+	}
+}
+
+func TestUpdateContext(t *testing.T) {
+	out := &frameRecorder{}
+	strip := NewController(out, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := strip.UpdateContext(ctx); err != nil {
+		t.Errorf("Unexpected error %v\n", err)
+	}
+	cancel()
+	if err := strip.UpdateContext(ctx); err != context.Canceled {
+		t.Errorf("Got %v expected context.Canceled\n", err)
+	}
+	if len(out.frames) != 1 {
+		t.Errorf("Got %d frames expected 1\n", len(out.frames))
+	}
+}
+
+// flakyWriter fails the first failures writes part way through.
+type flakyWriter struct {
+	failures int
+	frameRecorder
+}
+
+func (fw *flakyWriter) Write(p []byte) (int, error) {
+	if fw.failures > 0 {
+		fw.failures--
+		return 2, errors.New("bus glitch")
+	}
+	return fw.frameRecorder.Write(p)
+}
+
+func TestRetryConfig(t *testing.T) {
+	out := &flakyWriter{failures: 2}
+	strip := NewController(out, 3, RetryConfig(3, time.Millisecond))
+	var delays []time.Duration
+	strip.sleep = func(d time.Duration) { delays = append(delays, d) }
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if len(out.frames) != 1 || len(delays) != 2 || delays[1] != 2*time.Millisecond {
+		t.Errorf("Got %d frames and delays %v\n", len(out.frames), delays)
+	}
+}
+
+func TestShortWriteError(t *testing.T) {
+	strip := NewController(&flakyWriter{failures: 1}, 3)
+	err := strip.Update()
+	shortWrite, ok := err.(*ShortWriteError)
+	if !ok {
+		t.Fatalf("Got %v expected a ShortWriteError\n", err)
+	}
+	if shortWrite.Written != 2 || shortWrite.Expected != len(strip.buffer) || shortWrite.Err.Error() != "bus glitch" {
+		t.Errorf("Got %+v\n", shortWrite)
+	}
+}
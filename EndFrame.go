@@ -0,0 +1,61 @@
+package dotstar
+
+import (
+	"errors"
+)
+
+/*
+An EndFrameStyle is the value of the bytes sent after the last LED to clock the data along the strip.
+*/
+type EndFrameStyle int
+
+const (
+	// EndFrameZeros sends zero bytes, which works with APA102 strips and nearly all clones.
+	EndFrameZeros EndFrameStyle = iota
+	// EndFrameOnes sends 0xFF bytes, as given in the original APA102 datasheet and needed by some clones.
+	EndFrameOnes
+)
+
+/*
+EndFrameConfig sets the style of the end frame sent after the last LED, and adds extra bytes to it.
+
+The end frame must give at least half a clock pulse for each LED to push the data to the end of the strip, which
+is worked out from the LED count.  Long chains, or LEDs that latch slowly, may need extra bytes, each giving 8
+more pulses.  SK9822 strips keep their zero reset frame before the end frame.
+
+0xFF bytes look like full white LED packets.  Writers that decode messages, such as the Simulator and the
+dmx.Sender, implement FrameDecoder so that they are told the LED count and stop before the end frame, and
+DecodeFrameFormat takes the count for the same reason.  EndFrameOnes is rejected for the WS2812Writer, as WS2812
+strips have no use for an end frame.
+*/
+func EndFrameConfig(style EndFrameStyle, extra int) ConfigFunc {
+	return func(ctl *Controller) {
+		if style != EndFrameZeros && style != EndFrameOnes {
+			ctl.invalidConfig(errors.New("Unknown end frame style"))
+			return
+		}
+		if extra < 0 {
+			ctl.invalidConfig(errors.New("Extra end frame bytes must not be negative"))
+			return
+		}
+		if _, ok := ctl.writer().(*WS2812Writer); ok && style == EndFrameOnes {
+			ctl.invalidConfig(errors.New("A 0xFF end frame cannot be used with a WS2812Writer"))
+			return
+		}
+		ctl.endFrameOnes = style == EndFrameOnes
+		ctl.endFrameExtra = extra
+	}
+}
+
+/*
+Internal method used to set the end frame bytes of the buffer to 0xFF, after the SK9822 reset frame if there is one.
+*/
+func (ctl *Controller) fillEndFrame() {
+	start := len(ctl.protocol.Header) + ctl.physicalCount*ctl.packetSize
+	if ctl.chip == ChipSK9822 {
+		start += headerSize
+	}
+	for i := start; i < len(ctl.buffer); i++ {
+		ctl.buffer[i] = 0xFF
+	}
+}
@@ -0,0 +1,90 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestEndFrameSize(t *testing.T) {
+	for _, test := range []struct {
+		count, bytes int
+	}{
+		{1, 2}, {16, 3}, {17, 3}, {18, 4}, {64, 6}, {65, 6}, {66, 7}, {1024, 66}, {1025, 66},
+	} {
+		size := endFrameSize(test.count)
+		if size != test.bytes {
+			t.Errorf("%d LEDs got %d bytes expected %d\n", test.count, size, test.bytes)
+		}
+		// There must be at least half a clock pulse for each LED.
+		if size*8 < (test.count+1)/2 {
+			t.Errorf("%d LEDs got too few pulses from %d bytes\n", test.count, size)
+		}
+		strip := NewController(&frameRecorder{}, test.count)
+		if len(strip.buffer) != headerSize+test.count*ledPacketSize+test.bytes {
+			t.Errorf("%d LEDs got a message of %d bytes\n", test.count, len(strip.buffer))
+		}
+	}
+}
+
+func TestEndFrameConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 65, EndFrameConfig(EndFrameOnes, 2))
+	footer := strip.buffer[headerSize+65*ledPacketSize:]
+	if len(footer) != 8 {
+		t.Errorf("Got %d end frame bytes expected %d\n", len(footer), 8)
+	}
+	for i, b := range footer {
+		if b != 0xFF {
+			t.Errorf("End frame byte %d got %X expected FF\n", i, b)
+		}
+	}
+	// Changing colours leaves the end frame alone.
+	strip.Fill(Red)
+	for _, b := range footer {
+		if b != 0xFF {
+			t.Errorf("Got end frame % X after Fill\n", footer)
+			break
+		}
+	}
+
+	sk9822 := NewController(&frameRecorder{}, 1, ChipConfig(ChipSK9822), EndFrameConfig(EndFrameOnes, 0))
+	if footer := sk9822.buffer[headerSize+ledPacketSize:]; len(footer) != headerSize+2 || footer[3] != 0 || footer[4] != 0xFF {
+		t.Errorf("Got SK9822 end frame % X\n", footer)
+	}
+
+	if _, err := NewControllerE(&frameRecorder{}, 1, EndFrameConfig(EndFrameOnes, -1)); err == nil {
+		t.Errorf("Expected an error for negative extra bytes\n")
+	}
+	if _, err := NewControllerE(&frameRecorder{}, 1, EndFrameConfig(EndFrameStyle(5), 0)); err == nil {
+		t.Errorf("Expected an error for an unknown style\n")
+	}
+}
+
+func TestEndFrameDecoding(t *testing.T) {
+	// The end frame of 0xFF bytes must not be decoded as white LEDs.
+	out := &frameRecorder{}
+	strip := NewController(out, 100, EndFrameConfig(EndFrameOnes, 0))
+	strip.SetColour(99, White)
+	strip.Update()
+	clrs, err := DecodeFrame(out.frames[0], "bgr")
+	if err != nil || len(clrs) != 100 || clrs[99] != White {
+		t.Errorf("Got %d LEDs ending %v, %v\n", len(clrs), clrs[len(clrs)-1], err)
+	}
+
+	// Extra bytes cannot be told apart from LEDs without the LED count.
+	strip = NewController(out, 3, EndFrameConfig(EndFrameOnes, 8))
+	strip.Update()
+	clrs, err = DecodeFrameFormat(out.frames[1], "bgr", FrameFormat{LedCount: 3})
+	if err != nil || len(clrs) != 3 {
+		t.Errorf("Got %d LEDs, %v\n", len(clrs), err)
+	}
+
+	sim, _ := NewSimulatorWriter(&frameRecorder{}, "bgr")
+	NewController(sim, 3, EndFrameConfig(EndFrameOnes, 8))
+	if sim.format.LedCount != 3 {
+		t.Errorf("Got format %+v expected 3 LEDs\n", sim.format)
+	}
+
+	ws := NewWS2812Controller(&frameRecorder{}, 3, EndFrameConfig(EndFrameOnes, 0))
+	if ws.configErr == nil || ws.endFrameOnes {
+		t.Errorf("Expected a 0xFF end frame to be rejected for a WS2812Writer\n")
+	}
+}
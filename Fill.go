@@ -0,0 +1,208 @@
+package dotstar
+
+/*
+Fill sets all LEDs to the given Colour.  This does not trigger Update().
+*/
+func (ctl *Controller) Fill(c Colour) {
+	ctl.FillRange(0, ctl.count, c)
+}
+
+/*
+FillRange sets the LEDs from start up to, but not including, end to the given Colour.
+
+The range is clipped to the LEDs in the strip.  The colour is only encoded once, so this is much faster
+than calling SetColour for each LED.
+*/
+func (ctl *Controller) FillRange(start, end int, c Colour) {
+	if start < 0 {
+		start = 0
+	}
+	if end > ctl.count {
+		end = ctl.count
+	}
+	if start >= end {
+		return
+	}
+	if ctl.pixelMap != nil {
+		for i := start; i < end; i++ {
+			ctl.SetColour(i, c)
+		}
+		return
+	}
+
+	ctl.SetColour(start, c)
+	packet := ctl.packet(start)
+	for i := start + 1; i < end; i++ {
+		ctl.ledColours[i] = c
+		copy(ctl.packet(i), packet)
+	}
+}
+
+/*
+FillGradient sets the LEDs from start up to, but not including, end to a gradient that runs from the colour from
+at start to the colour to at end-1.  This does not trigger Update().
+
+The gradient is worked out over the whole range before it is clipped to the strip, so a partly visible
+gradient keeps its shape.
+*/
+func (ctl *Controller) FillGradient(start, end int, from, to Colour) {
+	ctl.FillPalette(start, end, Palette{from, to})
+}
+
+/*
+FillPalette sets the LEDs from start up to, but not including, end to the colours of p spread evenly across the
+range, as FillGradient does for two colours.
+*/
+func (ctl *Controller) FillPalette(start, end int, p Palette) {
+	steps := float64(end - start - 1)
+	first, last := start, end
+	if first < 0 {
+		first = 0
+	}
+	if last > ctl.count {
+		last = ctl.count
+	}
+	for i := first; i < last; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i-start) / steps
+		}
+		ctl.SetColour(i, p.At(t))
+	}
+}
+
+/*
+Shift moves all colours n positions along the strip, towards the end of the strip for positive n and towards
+the start for negative n.  Positions left empty are set to Off.
+*/
+func (ctl *Controller) Shift(n int) {
+	if n >= ctl.count || -n >= ctl.count {
+		ctl.Clear()
+		return
+	}
+	if ctl.pixelMap != nil {
+		if n > 0 {
+			copy(ctl.ledColours[n:], ctl.ledColours)
+			ctl.FillRange(0, n, Off)
+		} else if n < 0 {
+			copy(ctl.ledColours, ctl.ledColours[-n:])
+			ctl.FillRange(ctl.count+n, ctl.count, Off)
+		}
+		for i, clr := range ctl.ledColours {
+			ctl.encode(i, clr)
+		}
+		return
+	}
+
+	bufferStart := len(ctl.protocol.Header)
+	bufferEnd := bufferStart + ctl.count*ctl.packetSize
+	if n > 0 {
+		copy(ctl.ledColours[n:], ctl.ledColours)
+		copy(ctl.buffer[bufferStart+n*ctl.packetSize:bufferEnd], ctl.buffer[bufferStart:bufferEnd])
+		ctl.FillRange(0, n, Off)
+	} else if n < 0 {
+		copy(ctl.ledColours, ctl.ledColours[-n:])
+		copy(ctl.buffer[bufferStart:bufferEnd], ctl.buffer[bufferStart-n*ctl.packetSize:bufferEnd])
+		ctl.FillRange(ctl.count+n, ctl.count, Off)
+	}
+}
+
+/*
+Rotate moves all colours n positions along the strip like Shift, with colours moved off one end of the strip
+put back at the other end.
+*/
+func (ctl *Controller) Rotate(n int) {
+	if ctl.count == 0 {
+		return
+	}
+	n %= ctl.count
+	if n < 0 {
+		n += ctl.count
+	}
+	if n == 0 {
+		return
+	}
+
+	// Rotate in place by reversing both parts and then the whole strip.
+	// In lazy mode the buffer is rebuilt by Update(), so moving the packets is harmless.
+	if ctl.lazyEncode {
+		ctl.dirty = true
+	}
+	ctl.reverse(0, ctl.count)
+	ctl.reverse(0, n)
+	ctl.reverse(n, ctl.count)
+}
+
+/*
+FadeAll scales the red, green and blue of every LED towards zero by amount/256ths, leaving the luminosity unchanged.
+
+Calling this once per frame before drawing gives trails behind moving colours, an amount of 64 fades
+to a quarter of the original colour in around 5 frames.  This does not trigger Update().
+*/
+func (ctl *Controller) FadeAll(amount uint8) {
+	if amount == 0 {
+		return
+	}
+	for i, c := range ctl.ledColours {
+		c = fadeColour(c, amount)
+		ctl.ledColours[i] = c
+		ctl.encode(i, c)
+	}
+}
+
+/*
+FadeColours scales the red, green and blue of every colour in leds towards zero by amount/256ths, as FadeAll does
+for the strip.
+
+This lets effects that draw into their own buffer leave the same fading trails.
+*/
+func FadeColours(leds []Colour, amount uint8) {
+	if amount == 0 {
+		return
+	}
+	for i, c := range leds {
+		leds[i] = fadeColour(c, amount)
+	}
+}
+
+/*
+Internal function used to fade a colour towards black by amount/256ths.
+*/
+func fadeColour(c Colour, amount uint8) Colour {
+	// Adding 1 to the scale ensures an amount of 0 leaves the colour unchanged.
+	scale := uint16(255-amount) + 1
+	c.R = uint8(uint16(c.R) * scale >> 8)
+	c.G = uint8(uint16(c.G) * scale >> 8)
+	c.B = uint8(uint16(c.B) * scale >> 8)
+	c.W = uint8(uint16(c.W) * scale >> 8)
+	return c
+}
+
+/*
+Internal method used to reverse the order of LEDs from start up to, but not including, end.
+*/
+func (ctl *Controller) reverse(start, end int) {
+	var buf [ledPacketSize + 1]byte
+	scratch := buf[:ctl.packetSize]
+	for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+		ctl.ledColours[i], ctl.ledColours[j] = ctl.ledColours[j], ctl.ledColours[i]
+		if ctl.pixelMap != nil {
+			ctl.encode(i, ctl.ledColours[i])
+			ctl.encode(j, ctl.ledColours[j])
+			continue
+		}
+		copy(scratch, ctl.packet(i))
+		copy(ctl.packet(i), ctl.packet(j))
+		copy(ctl.packet(j), scratch)
+	}
+}
+
+/*
+Internal method used to get the buffer bytes holding the LED in the given position of the chain.
+
+This is the position set by SetColour unless the pixels are remapped.
+*/
+func (ctl *Controller) packet(position int) []byte {
+	offset := len(ctl.protocol.Header) + position*ctl.packetSize
+	return ctl.buffer[offset : offset+ctl.packetSize]
+}
@@ -0,0 +1,80 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+// checkColours compares the colours of the strip and confirms the buffer matches a freshly encoded strip.
+func checkColours(t *testing.T, strip *Controller, expected []Colour) {
+	t.Helper()
+	for i, want := range expected {
+		if got := strip.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+	fresh := NewController(&bytes.Buffer{}, len(expected))
+	fresh.SetColours(expected)
+	if !bytes.Equal(strip.buffer, fresh.buffer) {
+		t.Errorf("Got buffer % X expected % X\n", strip.buffer, fresh.buffer)
+	}
+}
+
+func TestFillRange(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 5)
+	strip.Fill(Blue)
+	strip.FillRange(-1, 2, Red)
+	strip.FillRange(4, 10, Green)
+	checkColours(t, strip, []Colour{Red, Red, Blue, Blue, Green})
+}
+
+func TestShift(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 4)
+	strip.SetColours([]Colour{Red, Green, Blue, White})
+	strip.Shift(1)
+	checkColours(t, strip, []Colour{Off, Red, Green, Blue})
+	strip.Shift(-2)
+	checkColours(t, strip, []Colour{Green, Blue, Off, Off})
+	strip.Shift(4)
+	checkColours(t, strip, []Colour{Off, Off, Off, Off})
+}
+
+func TestRotate(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 4)
+	strip.SetColours([]Colour{Red, Green, Blue, White})
+	strip.Rotate(1)
+	checkColours(t, strip, []Colour{White, Red, Green, Blue})
+	strip.Rotate(-5)
+	checkColours(t, strip, []Colour{Red, Green, Blue, White})
+}
+
+func TestFadeAll(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 2)
+	strip.SetColours([]Colour{NewColour(200, 100, 1, 255), White})
+	strip.FadeAll(128)
+	checkColours(t, strip, []Colour{NewColour(100, 50, 0, 255), NewColour(127, 127, 127, 255)})
+	strip.FadeAll(255)
+	checkColours(t, strip, []Colour{NewColour(0, 0, 0, 255), NewColour(0, 0, 0, 255)})
+
+	leds := []Colour{NewColour(200, 100, 1, 255), White}
+	FadeColours(leds, 128)
+	if leds[0] != NewColour(100, 50, 0, 255) || leds[1] != NewColour(127, 127, 127, 255) {
+		t.Errorf("Got %v\n", leds)
+	}
+}
+
+func TestFillGradient(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 6)
+	strip.FillGradient(1, 6, NewColour(0, 0, 0, 255), NewColour(200, 100, 0, 255))
+	checkColours(t, strip, []Colour{Off, NewColour(0, 0, 0, 255), NewColour(50, 25, 0, 255), NewColour(100, 50, 0, 255), NewColour(150, 75, 0, 255), NewColour(200, 100, 0, 255)})
+
+	// Clipping keeps the gradient's shape.
+	strip.FillGradient(-2, 3, NewColour(0, 0, 0, 255), NewColour(200, 100, 0, 255))
+	checkColours(t, strip, []Colour{NewColour(100, 50, 0, 255), NewColour(150, 75, 0, 255), NewColour(200, 100, 0, 255), NewColour(100, 50, 0, 255), NewColour(150, 75, 0, 255), NewColour(200, 100, 0, 255)})
+}
+
+func TestFillPalette(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 5)
+	strip.FillPalette(0, 5, Palette{Red, Green, Blue})
+	checkColours(t, strip, []Colour{Red, Red.Blend(Green, 0.5), Green, Green.Blend(Blue, 0.5), Blue})
+}
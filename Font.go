@@ -0,0 +1,106 @@
+package dotstar
+
+import (
+	"unicode"
+)
+
+// FontHeight is the height in LEDs of text drawn with the built in font.
+const FontHeight = 5
+
+// A glyph is a character of the built in font, each row holding the pixels with the leftmost in the highest bit.
+type glyph struct {
+	width int
+	rows  [FontHeight]uint8
+}
+
+// font is a 3 by 5 font holding digits, upper case letters and a little punctuation.
+var font = map[rune]glyph{
+	'0': {3, [FontHeight]uint8{7, 5, 5, 5, 7}},
+	'1': {3, [FontHeight]uint8{2, 6, 2, 2, 7}},
+	'2': {3, [FontHeight]uint8{7, 1, 7, 4, 7}},
+	'3': {3, [FontHeight]uint8{7, 1, 7, 1, 7}},
+	'4': {3, [FontHeight]uint8{5, 5, 7, 1, 1}},
+	'5': {3, [FontHeight]uint8{7, 4, 7, 1, 7}},
+	'6': {3, [FontHeight]uint8{7, 4, 7, 5, 7}},
+	'7': {3, [FontHeight]uint8{7, 1, 1, 1, 1}},
+	'8': {3, [FontHeight]uint8{7, 5, 7, 5, 7}},
+	'9': {3, [FontHeight]uint8{7, 5, 7, 1, 7}},
+	'A': {3, [FontHeight]uint8{2, 5, 7, 5, 5}},
+	'B': {3, [FontHeight]uint8{6, 5, 6, 5, 6}},
+	'C': {3, [FontHeight]uint8{3, 4, 4, 4, 3}},
+	'D': {3, [FontHeight]uint8{6, 5, 5, 5, 6}},
+	'E': {3, [FontHeight]uint8{7, 4, 6, 4, 7}},
+	'F': {3, [FontHeight]uint8{7, 4, 6, 4, 4}},
+	'G': {3, [FontHeight]uint8{3, 4, 5, 5, 3}},
+	'H': {3, [FontHeight]uint8{5, 5, 7, 5, 5}},
+	'I': {3, [FontHeight]uint8{7, 2, 2, 2, 7}},
+	'J': {3, [FontHeight]uint8{1, 1, 1, 5, 2}},
+	'K': {3, [FontHeight]uint8{5, 5, 6, 5, 5}},
+	'L': {3, [FontHeight]uint8{4, 4, 4, 4, 7}},
+	'M': {3, [FontHeight]uint8{5, 7, 7, 5, 5}},
+	'N': {3, [FontHeight]uint8{6, 5, 5, 5, 5}},
+	'O': {3, [FontHeight]uint8{2, 5, 5, 5, 2}},
+	'P': {3, [FontHeight]uint8{6, 5, 6, 4, 4}},
+	'Q': {3, [FontHeight]uint8{2, 5, 5, 6, 3}},
+	'R': {3, [FontHeight]uint8{6, 5, 6, 5, 5}},
+	'S': {3, [FontHeight]uint8{3, 4, 2, 1, 6}},
+	'T': {3, [FontHeight]uint8{7, 2, 2, 2, 2}},
+	'U': {3, [FontHeight]uint8{5, 5, 5, 5, 7}},
+	'V': {3, [FontHeight]uint8{5, 5, 5, 5, 2}},
+	'W': {3, [FontHeight]uint8{5, 5, 7, 7, 5}},
+	'X': {3, [FontHeight]uint8{5, 5, 2, 5, 5}},
+	'Y': {3, [FontHeight]uint8{5, 5, 2, 2, 2}},
+	'Z': {3, [FontHeight]uint8{7, 1, 2, 4, 7}},
+	' ': {3, [FontHeight]uint8{0, 0, 0, 0, 0}},
+	':': {1, [FontHeight]uint8{0, 1, 0, 1, 0}},
+	'.': {1, [FontHeight]uint8{0, 0, 0, 0, 1}},
+	'-': {3, [FontHeight]uint8{0, 0, 7, 0, 0}},
+	'%': {3, [FontHeight]uint8{5, 1, 2, 4, 5}},
+	'?': {3, [FontHeight]uint8{7, 1, 2, 0, 2}},
+}
+
+/*
+Internal function used to look up the glyph for r, falling back to a question mark.
+*/
+func lookupGlyph(r rune) glyph {
+	if g, ok := font[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return font['?']
+}
+
+/*
+TextWidth returns the width in LEDs of text drawn with the built in font, including a one LED gap between characters.
+*/
+func TextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if width > 0 {
+			width++
+		}
+		width += lookupGlyph(r).width
+	}
+	return width
+}
+
+/*
+Internal function used to draw text with its top left corner at x, y by calling set for each lit pixel.
+*/
+func drawText(x, y int, text string, set func(x, y int)) int {
+	start := x
+	for i, r := range text {
+		if i > 0 {
+			x++
+		}
+		g := lookupGlyph(r)
+		for row, bits := range g.rows {
+			for column := 0; column < g.width; column++ {
+				if bits&(1<<uint(g.width-1-column)) != 0 {
+					set(x+column, y+row)
+				}
+			}
+		}
+		x += g.width
+	}
+	return x - start
+}
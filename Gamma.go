@@ -0,0 +1,119 @@
+package dotstar
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+A GammaTable maps each colour value to its gamma corrected value.
+*/
+type GammaTable [256]uint8
+
+/*
+NewGammaTable computes the GammaTable for the given gamma exponent.
+
+A gamma of 1 leaves values unchanged, the default used by the Controller is 2.8.
+*/
+func NewGammaTable(gamma float64) *GammaTable {
+	var table GammaTable
+	for i := range table {
+		table[i] = uint8(math.Pow(float64(i)/255, gamma)*255 + 0.5)
+	}
+	return &table
+}
+
+/*
+GammaConfig applies gamma correction with a separate exponent for each of the red, green and blue channels.
+The white channel of RGBW strips uses the green exponent.
+
+The lookup tables are computed once when the Controller is created.  GammaConfig(2.8, 2.8, 2.8) is equivalent
+to the default.
+*/
+func GammaConfig(rGamma, gGamma, bGamma float64) ConfigFunc {
+	rTable, gTable, bTable := NewGammaTable(rGamma), NewGammaTable(gGamma), NewGammaTable(bGamma)
+	cfg := separableGammaConfig(func(in Colour) (out Colour) {
+		out.R = rTable[in.R]
+		out.G = gTable[in.G]
+		out.B = bTable[in.B]
+		out.W = gTable[in.W]
+		out.L = in.L
+		return out
+	})
+	return func(ctl *Controller) {
+		if rGamma <= 0 || gGamma <= 0 || bGamma <= 0 {
+			ctl.invalidConfig(errors.New("Gamma must be positive"))
+		}
+		cfg(ctl)
+	}
+}
+
+/*
+Internal function used to set a gamma function that corrects each channel independently of the others.
+*/
+func separableGammaConfig(gammafunc func(in Colour) Colour) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.gammaFunc = gammafunc
+		ctl.separableGamma = true
+	}
+}
+
+// encodeTables hold the packet bytes for each brightness and channel value, looked up in place of encodePacket.
+type encodeTables struct {
+	brightness [256]byte
+	channels   [4][256]uint8
+}
+
+/*
+Internal method used to build the encoding tables for the current settings.
+
+The tables are only valid while the channels are encoded independently of each other and of the luminosity,
+so they are not used during a fade, with FoldLuminosityConfig, BrightnessCurveConfig or with a custom gamma
+function.
+*/
+func (ctl *Controller) buildTables() {
+	t := &encodeTables{}
+	var buf [ledPacketSize + 1]byte
+	packet := buf[:ctl.packetSize]
+	for value := 0; value < 256; value++ {
+		v := uint8(value)
+		ctl.encodePacket(packet, Colour{R: v, G: v, B: v, W: v, L: v})
+		t.brightness[value] = packet[0]
+		t.channels[0][value] = packet[ctl.rOffset]
+		t.channels[1][value] = packet[ctl.gOffset]
+		t.channels[2][value] = packet[ctl.bOffset]
+		if ctl.wOffset != 0 {
+			t.channels[3][value] = packet[ctl.wOffset]
+		}
+	}
+	ctl.tables = t
+}
+
+/*
+LuminosityGammaConfig applies a gamma curve to the per-LED luminosity before it is sent to the brightness field.
+
+By default luminosity is sent linearly, so fades made by stepping L look uneven.  A gamma of around 2 gives a
+more even perceived fade.  With only 32 brightness levels the lowest luminosity values round to off.
+*/
+func LuminosityGammaConfig(gamma float64) ConfigFunc {
+	table := NewGammaTable(gamma)
+	return func(ctl *Controller) {
+		if gamma <= 0 {
+			ctl.invalidConfig(errors.New("Gamma must be positive"))
+		}
+		ctl.luminosityCurve = table
+	}
+}
+
+/*
+FoldLuminosityConfig sends every LED at full brightness and applies the luminosity and global brightness by
+scaling the red, green and blue values instead.
+
+The APA102 brightness field drives a slow PWM that shows as flicker in video recordings and at high frame
+rates.  Folding brightness into the fast RGB PWM avoids this, at the cost of colour resolution in dim colours.
+*/
+func FoldLuminosityConfig() ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.foldLuminosity = true
+	}
+}
@@ -0,0 +1,72 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewGammaTableMatchesDefault(t *testing.T) {
+	table := NewGammaTable(2.8)
+	for i, value := range defaultGammaTable {
+		if table[i] != value {
+			t.Errorf("Got %d for %d expected %d\n", table[i], i, value)
+		}
+	}
+}
+
+func TestGammaConfig(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, GammaConfig(1, 2, 2.8))
+	strip.SetColour(0, NewColour(128, 128, 128, 255))
+	packet := strip.buffer[headerSize : headerSize+ledPacketSize]
+	// bgr order
+	if packet[3] != 128 || packet[2] != 64 || packet[1] != defaultGammaTable[128] {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestLuminosityGammaConfig(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, LuminosityGammaConfig(2))
+	strip.SetColour(0, NewColour(255, 255, 255, 128))
+	// 128 is about half, squared gives a quarter of 31 levels.
+	if packet := strip.packet(0); packet[0] != brightnessHeader|64>>3 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestFoldLuminosityConfig(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 1, FoldLuminosityConfig(), DisableGammaCorrectionConfig())
+	strip.SetGlobalBrightness(128)
+	strip.SetColour(0, NewColour(255, 200, 0, 128))
+	// Full brightness, with RGB scaled by a quarter (bgr order).
+	if packet := strip.packet(0); packet[0] != 0xFF || packet[3] != 64 || packet[2] != 50 || packet[1] != 0 {
+		t.Errorf("Got packet % X\n", packet)
+	}
+}
+
+func TestEncodeTablesMatchEncodePacket(t *testing.T) {
+	configs := [][]ConfigFunc{
+		{},
+		{DisableGammaCorrectionConfig()},
+		{GammaConfig(1, 2, 2.8), ColourCorrectionConfig(1, 0.8, 0.6)},
+		{ChipConfig(ChipSK9822), TemperatureConfig(2700)},
+		{LuminosityGammaConfig(2)},
+	}
+	for n, cfgs := range configs {
+		strip := NewController(&bytes.Buffer{}, 256, cfgs...)
+		strip.SetGlobalBrightness(200)
+		for i := 0; i < 256; i++ {
+			strip.SetColour(i, NewColour(uint8(i), uint8(255-i), uint8(i*7), uint8(i*3)))
+		}
+		if strip.tables == nil {
+			t.Errorf("Config %d did not use encoding tables\n", n)
+		}
+		var packet [ledPacketSize]byte
+		for i := 0; i < 256; i++ {
+			strip.encodePacket(packet[:], strip.GetColour(i))
+			if !bytes.Equal(packet[:], strip.packet(i)) {
+				t.Errorf("Config %d LED %d got % X expected % X\n", n, i, strip.packet(i), packet)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package dotstar
+
+import (
+	"math"
+)
+
+/*
+A Point is a position in space.  For flat layouts Z is left as 0.
+*/
+type Point struct {
+	X, Y, Z float64
+}
+
+/*
+Distance returns the straight line distance between two points.
+*/
+func (p Point) Distance(other Point) float64 {
+	dx, dy, dz := p.X-other.X, p.Y-other.Y, p.Z-other.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+/*
+A Layout gives each LED of a Controller a position in space, so that effects can be drawn in spatial terms.
+
+This is used for LEDs arranged in shapes other than a line, for example wrapped around a tree or sculpture.
+*/
+type Layout struct {
+	ctl    *Controller
+	points []Point
+}
+
+/*
+NewLayout creates a Layout where the LED at position i is at points[i].
+
+LEDs without a point are left out of the layout.
+*/
+func NewLayout(ctl *Controller, points []Point) *Layout {
+	if len(points) > ctl.Len() {
+		points = points[:ctl.Len()]
+	}
+	return &Layout{ctl: ctl, points: points}
+}
+
+/*
+Point returns the position in space of the LED at position.
+*/
+func (l *Layout) Point(position int) Point {
+	if position < 0 || position >= len(l.points) {
+		return Point{}
+	}
+	return l.points[position]
+}
+
+/*
+Bounds returns the smallest and largest coordinates of the LEDs in the layout.
+*/
+func (l *Layout) Bounds() (min, max Point) {
+	if len(l.points) == 0 {
+		return min, max
+	}
+	min, max = l.points[0], l.points[0]
+	for _, p := range l.points[1:] {
+		min = Point{math.Min(min.X, p.X), math.Min(min.Y, p.Y), math.Min(min.Z, p.Z)}
+		max = Point{math.Max(max.X, p.X), math.Max(max.Y, p.Y), math.Max(max.Z, p.Z)}
+	}
+	return min, max
+}
+
+/*
+Nearest returns the position of the LED closest to p, or -1 if the layout is empty.
+*/
+func (l *Layout) Nearest(p Point) int {
+	nearest := -1
+	nearestDistance := math.Inf(1)
+	for i, point := range l.points {
+		if distance := point.Distance(p); distance < nearestDistance {
+			nearest, nearestDistance = i, distance
+		}
+	}
+	return nearest
+}
+
+/*
+SetByPosition sets the LED closest to p to the given Colour.
+*/
+func (l *Layout) SetByPosition(p Point, c Colour) {
+	l.ctl.SetColour(l.Nearest(p), c)
+}
+
+/*
+SetWithin sets all LEDs within radius of p to the given Colour.
+*/
+func (l *Layout) SetWithin(p Point, radius float64, c Colour) {
+	for i, point := range l.points {
+		if point.Distance(p) <= radius {
+			l.ctl.SetColour(i, c)
+		}
+	}
+}
+
+/*
+Apply sets every LED in the layout to the Colour returned by f for the LED's position in space.
+
+This is the general way of drawing spatial effects, for example a gradient from bottom to top is
+
+	layout.Apply(func(p Point) Colour { return bottom.Blend(top, float32(p.Y / height)) })
+*/
+func (l *Layout) Apply(f func(p Point) Colour) {
+	for i, point := range l.points {
+		l.ctl.SetColour(i, f(point))
+	}
+}
+
+/*
+RadialGradient colours the LEDs by their distance from centre, blending from inner at the centre to outer at radius.
+
+LEDs further than radius from the centre are set to outer.
+*/
+func (l *Layout) RadialGradient(centre Point, radius float64, inner, outer Colour) {
+	l.Apply(func(p Point) Colour {
+		if radius <= 0 {
+			return outer
+		}
+		return inner.Blend(outer, float32(p.Distance(centre)/radius))
+	})
+}
+
+/*
+PlaneSweep lights the LEDs close to a plane, leaving the others unchanged.
+
+The plane passes through origin at right angles to normal.  LEDs on the plane are set to c, fading out to
+no change at width/2 either side of it.  Moving origin along normal on each frame sweeps the plane through
+the layout.
+*/
+func (l *Layout) PlaneSweep(origin, normal Point, width float64, c Colour) {
+	length := normal.Distance(Point{})
+	if length == 0 || width <= 0 {
+		return
+	}
+	for i, p := range l.points {
+		distance := math.Abs((p.X-origin.X)*normal.X+(p.Y-origin.Y)*normal.Y+(p.Z-origin.Z)*normal.Z) / length
+		if distance >= width/2 {
+			continue
+		}
+		l.ctl.SetColour(i, l.ctl.GetColour(i).Blend(c, float32(1-distance*2/width)))
+	}
+}
@@ -0,0 +1,34 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 4)
+	layout := NewLayout(strip, []Point{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {3, 3, 1}})
+
+	if min, max := layout.Bounds(); min != (Point{0, 0, 0}) || max != (Point{3, 3, 1}) {
+		t.Errorf("Got bounds %v %v\n", min, max)
+	}
+
+	layout.SetByPosition(Point{0.9, 0.2, 0}, Red)
+	if strip.GetColour(1) != Red {
+		t.Errorf("Got %v expected nearest LED to be red\n", strip.Snapshot())
+	}
+
+	layout.RadialGradient(Point{}, 2, White, Off)
+	expected := []Colour{White, NewColour(127, 127, 127, 127), NewColour(127, 127, 127, 127), Off}
+	for i, want := range expected {
+		if got := strip.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+
+	strip.Clear()
+	layout.PlaneSweep(Point{0, 1, 0}, Point{0, 1, 0}, 1, Blue)
+	if strip.GetColour(2) != Blue || strip.GetColour(0) != Off || strip.GetColour(3) != Off {
+		t.Errorf("Got %v after plane sweep\n", strip.Snapshot())
+	}
+}
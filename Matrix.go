@@ -0,0 +1,95 @@
+package dotstar
+
+/*
+A Matrix arranges the LEDs of a Controller in a grid of rows, so that they can be addressed by x and y.
+
+The first LED is at x 0, y 0 and each row runs along x.  Many LED panels are wired in a serpentine pattern, where
+every other row runs backwards.
+*/
+type Matrix struct {
+	ctl           *Controller
+	width, height int
+	serpentine    bool
+}
+
+/*
+NewMatrix creates a Matrix of width by height LEDs.  If serpentine is true the odd rows run from right to left.
+*/
+func NewMatrix(ctl *Controller, width, height int, serpentine bool) *Matrix {
+	return &Matrix{ctl: ctl, width: width, height: height, serpentine: serpentine}
+}
+
+/*
+Width returns the number of LEDs in each row.
+*/
+func (m *Matrix) Width() int {
+	return m.width
+}
+
+/*
+Height returns the number of rows.
+*/
+func (m *Matrix) Height() int {
+	return m.height
+}
+
+/*
+Controller returns the Controller of the LEDs in the matrix.
+*/
+func (m *Matrix) Controller() *Controller {
+	return m.ctl
+}
+
+/*
+Index returns the position along the strip of the LED at x, y or -1 if it is outside the matrix.
+*/
+func (m *Matrix) Index(x, y int) int {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return -1
+	}
+	if m.serpentine && y%2 == 1 {
+		x = m.width - 1 - x
+	}
+	return y*m.width + x
+}
+
+/*
+SetPixel sets the colour of the LED at x, y.  Points outside the matrix are ignored.
+*/
+func (m *Matrix) SetPixel(x, y int, colour Colour) {
+	if index := m.Index(x, y); index >= 0 {
+		m.ctl.SetColour(index, colour)
+	}
+}
+
+/*
+GetPixel returns the colour of the LED at x, y, or Off if it is outside the matrix.
+*/
+func (m *Matrix) GetPixel(x, y int) Colour {
+	if index := m.Index(x, y); index >= 0 && index < m.ctl.Len() {
+		return m.ctl.GetColour(index)
+	}
+	return Off
+}
+
+/*
+DrawText draws text in colour with its top left corner at x, y, returning the width drawn.
+*/
+func (m *Matrix) DrawText(x, y int, text string, colour Colour) int {
+	return drawText(x, y, text, func(x, y int) {
+		m.SetPixel(x, y, colour)
+	})
+}
+
+/*
+DrawTextInto draws text into leds, a frame laid out as the matrix, with its top left corner at x, y.
+
+The width drawn is returned.  This is used by effects, which draw into a frame rather than the Controller.
+*/
+func (m *Matrix) DrawTextInto(leds []Colour, x, y int, text string, colour Colour) int {
+	return drawText(x, y, text, func(x, y int) {
+		if index := m.Index(x, y); index >= 0 && index < len(leds) {
+			leds[index] = colour
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package dotstar
+
+import (
+	"context"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+/*
+PlayGIF plays the animated GIF read from r on the matrix, scaling each frame to the matrix size and waiting for
+each frame's delay before showing the next.
+
+If loop is true the animation repeats until an error occurs.  Use PlayGIFContext to be able to stop it.
+*/
+func (m *Matrix) PlayGIF(r io.Reader, loop bool) error {
+	return m.PlayGIFContext(context.Background(), r, loop)
+}
+
+/*
+PlayGIFContext plays the animated GIF read from r as PlayGIF does, stopping when ctx is done.
+
+The context is checked between frames and while waiting for a frame's delay, and its error returned once it is
+done.
+*/
+func (m *Matrix) PlayGIFContext(ctx context.Context, r io.Reader, loop bool) error {
+	animation, err := gif.DecodeAll(r)
+	if err != nil {
+		return err
+	}
+	bounds := image.Rect(0, 0, animation.Config.Width, animation.Config.Height)
+	if bounds.Empty() && len(animation.Image) > 0 {
+		bounds = animation.Image[0].Bounds()
+	}
+
+	for {
+		canvas := image.NewRGBA(bounds)
+		for i, frame := range animation.Image {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			disposal := byte(0)
+			if i < len(animation.Disposal) {
+				disposal = animation.Disposal[i]
+			}
+			var previous *image.RGBA
+			if disposal == gif.DisposalPrevious {
+				previous = image.NewRGBA(bounds)
+				draw.Draw(previous, bounds, canvas, bounds.Min, draw.Src)
+			}
+
+			draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+			m.drawImage(canvas)
+			if err := m.ctl.UpdateContext(ctx); err != nil {
+				return err
+			}
+			// GIF delays are in hundredths of a second.
+			if i < len(animation.Delay) {
+				if err := m.wait(ctx, time.Duration(animation.Delay[i])*10*time.Millisecond); err != nil {
+					return err
+				}
+			}
+
+			switch disposal {
+			case gif.DisposalBackground:
+				draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+			case gif.DisposalPrevious:
+				canvas = previous
+			}
+		}
+		if !loop {
+			return nil
+		}
+	}
+}
+
+/*
+Internal method used to wait for d, returning early with the context error if ctx is done first.
+*/
+func (m *Matrix) wait(ctx context.Context, d time.Duration) error {
+	if ctx.Done() == nil {
+		// The context can never be done, so use the Controller's sleep which tests replace.
+		m.ctl.sleep(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+/*
+Internal method used to set every pixel of the matrix from img, scaled to fit with nearest neighbour sampling.
+*/
+func (m *Matrix) drawImage(img image.Image) {
+	bounds := img.Bounds()
+	for y := 0; y < m.height; y++ {
+		sourceY := bounds.Min.Y + y*bounds.Dy()/m.height
+		for x := 0; x < m.width; x++ {
+			sourceX := bounds.Min.X + x*bounds.Dx()/m.width
+			m.SetPixel(x, y, ColourFromColor(img.At(sourceX, sourceY), 255))
+		}
+	}
+}
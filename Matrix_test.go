@@ -0,0 +1,99 @@
+package dotstar
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+func TestMatrixIndex(t *testing.T) {
+	m := NewMatrix(NewController(&bytes.Buffer{}, 12), 4, 3, true)
+	tests := []struct{ x, y, expected int }{
+		{0, 0, 0}, {3, 0, 3}, {0, 1, 7}, {3, 1, 4}, {1, 2, 9}, {4, 0, -1}, {0, -1, -1},
+	}
+	for _, test := range tests {
+		if index := m.Index(test.x, test.y); index != test.expected {
+			t.Errorf("Got %v expected %v for %v,%v\n", index, test.expected, test.x, test.y)
+		}
+	}
+}
+
+func TestMatrixDrawText(t *testing.T) {
+	m := NewMatrix(NewController(&bytes.Buffer{}, 7*5), 7, 5, false)
+	if width := m.DrawText(0, 0, "1:", Red); width != 5 || TextWidth("1:") != 5 {
+		t.Errorf("Got width %v expected 5\n", width)
+	}
+	// The 1 has its top pixel in the middle column, the colon its first dot on row 1.
+	if m.GetPixel(1, 0) != Red || m.GetPixel(0, 0) != Off || m.GetPixel(4, 1) != Red || m.GetPixel(4, 0) != Off {
+		t.Errorf("Text not drawn as expected\n")
+	}
+
+	leds := make([]Colour, 7*5)
+	m.DrawTextInto(leds, 4, 0, "7", Blue)
+	if leds[4] != Blue || leds[6] != Blue || leds[7+6] != Blue || leds[7+4] != Off {
+		t.Errorf("Got %v\n", leds)
+	}
+}
+
+func TestMatrixPlayGIF(t *testing.T) {
+	palette := color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	animation := &gif.GIF{}
+	for _, index := range []uint8{1, 2} {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		for i := range frame.Pix {
+			frame.Pix[i] = index
+		}
+		// Mark the top left quarter black so scaling can be checked.
+		frame.SetColorIndex(0, 0, 0)
+		frame.SetColorIndex(1, 0, 0)
+		frame.SetColorIndex(0, 1, 0)
+		frame.SetColorIndex(1, 1, 0)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 5)
+	}
+	data := &bytes.Buffer{}
+	if err := gif.EncodeAll(data, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &frameRecorder{}
+	ctl := NewController(out, 4, DisableGammaCorrectionConfig())
+	var delays []time.Duration
+	ctl.sleep = func(d time.Duration) { delays = append(delays, d) }
+	m := NewMatrix(ctl, 2, 2, false)
+	if err := m.PlayGIF(data, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.frames) != 2 || len(delays) != 2 || delays[0] != 50*time.Millisecond {
+		t.Fatalf("Got %v frames and delays %v\n", len(out.frames), delays)
+	}
+	if m.GetPixel(0, 0) != NewColour(0, 0, 0, 255) || m.GetPixel(1, 1) != NewColour(0, 0, 255, 255) {
+		t.Errorf("Got %v and %v\n", m.GetPixel(0, 0), m.GetPixel(1, 1))
+	}
+}
+
+func TestMatrixPlayGIFCancel(t *testing.T) {
+	palette := color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 0, 0, 255}}
+	// A single frame held for ten seconds.
+	animation := &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 2, 2), palette)}, Delay: []int{1000}}
+	data := &bytes.Buffer{}
+	if err := gif.EncodeAll(data, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMatrix(NewController(&frameRecorder{}, 4), 2, 2, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := m.PlayGIFContext(ctx, data, true); err != context.DeadlineExceeded {
+		t.Errorf("Got %v expected %v\n", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Took %v to stop\n", elapsed)
+	}
+}
@@ -0,0 +1,53 @@
+package dotstar
+
+/*
+A Middleware changes each frame on its way to the strip, for example to limit power, correct colours, override
+the brightness or record the frames sent.
+
+It is given the colours to be shown and returns the colours to show, which may be the same slice changed in place.
+The slice is owned by the Controller and only valid until the Middleware returns.
+*/
+type Middleware func(frame []Colour) []Colour
+
+/*
+MiddlewareConfig adds middleware run on every Update(), see Use.
+*/
+func MiddlewareConfig(middleware ...Middleware) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.Use(middleware...)
+	}
+}
+
+/*
+Use adds middleware to the end of the chain run on every Update().
+
+The chain starts with a copy of the colours set, so they are left unchanged for GetColour and Snapshot, and the
+frame returned by the last middleware is encoded and sent.  If a middleware returns fewer colours than the strip
+has, the remaining LEDs keep the colours given to it.  With middleware the whole strip is encoded on each Update().
+*/
+func (ctl *Controller) Use(middleware ...Middleware) {
+	ctl.middleware = append(ctl.middleware, middleware...)
+}
+
+/*
+Internal method used to run the middleware over the current colours and encode the result into the buffer.
+
+Any notification is drawn over the colours first, so that the middleware applies to it too.
+*/
+func (ctl *Controller) runMiddleware(notify *notification) {
+	if len(ctl.middlewareFrame) != ctl.count {
+		ctl.middlewareFrame = make([]Colour, ctl.count, ctl.count)
+	}
+	frame := ctl.middlewareFrame
+	copy(frame, ctl.ledColours)
+	if notify != nil {
+		notify.draw(frame, ctl.now())
+	}
+	for _, m := range ctl.middleware {
+		// Copying a slice onto itself is harmless, so middleware changing frame in place needs no special case.
+		copy(frame, m(frame))
+	}
+	for i, clr := range frame {
+		ctl.updateBuffer(i, clr)
+	}
+}
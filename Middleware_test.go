@@ -0,0 +1,48 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	out := &frameRecorder{}
+	var recorded []Colour
+	redToBlue := func(frame []Colour) []Colour {
+		for i, clr := range frame {
+			if clr == Red {
+				frame[i] = Blue
+			}
+		}
+		return frame
+	}
+	record := func(frame []Colour) []Colour {
+		recorded = append([]Colour(nil), frame...)
+		return frame
+	}
+	ctl := NewController(out, 3, DisableGammaCorrectionConfig(), MiddlewareConfig(redToBlue))
+	ctl.Use(record)
+	ctl.SetColours([]Colour{Red, Green, Red})
+	if err := ctl.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Middleware runs in order, and the colours set are left alone.
+	expected := []Colour{Blue, Green, Blue}
+	frame, _ := DecodeFrame(out.frames[0], "bgr")
+	for i := range expected {
+		if recorded[i] != expected[i] || frame[i] != expected[i] {
+			t.Errorf("LED %d recorded %v sent %v expected %v\n", i, recorded[i], frame[i], expected[i])
+		}
+	}
+	if ctl.GetColour(0) != Red {
+		t.Errorf("Got %v expected %v\n", ctl.GetColour(0), Red)
+	}
+
+	// Short results leave the remaining LEDs unchanged.
+	ctl.Use(func(frame []Colour) []Colour { return []Colour{White} })
+	ctl.Update()
+	frame, _ = DecodeFrame(out.frames[1], "bgr")
+	if frame[0] != White || frame[1] != Green || frame[2] != Blue {
+		t.Errorf("Got %v\n", frame)
+	}
+}
@@ -0,0 +1,237 @@
+package dotstar
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+A MultiController joins several Controllers into one strip with a single range of positions.
+
+Position 0 is the first LED of the first Controller, with the LEDs of the next Controller following on from
+the last LED of the previous one.  This allows installations spread over several SPI buses to be driven as
+one strip.  Positions are worked out from the current length of each Controller, so members may be resized.
+Methods are NOT safe to call from multiple goroutines concurrently.
+*/
+type MultiController struct {
+	ctls     []*Controller
+	parallel bool
+	// skew is the spread of write completion times in the last parallel Update().
+	skew time.Duration
+}
+
+/*
+NewMultiController creates a MultiController from the given Controllers, in order.
+
+If parallel is true then Update() sends to all of the Controllers at the same time from separate goroutines.
+Every Controller prepares its frame before any of them start writing, keeping the gap between the segments
+latching small.
+*/
+func NewMultiController(parallel bool, ctls ...*Controller) *MultiController {
+	return &MultiController{ctls: ctls, parallel: parallel}
+}
+
+/*
+Len returns the total number of LEDs.
+*/
+func (multi *MultiController) Len() int {
+	count := 0
+	for _, ctl := range multi.ctls {
+		count += ctl.Len()
+	}
+	return count
+}
+
+/*
+Update sends the current Colour values of every Controller to the LEDs.
+
+All Controllers are updated even if one fails, the first error is returned.
+*/
+func (multi *MultiController) Update() error {
+	if !multi.parallel {
+		var firstErr error
+		for _, ctl := range multi.ctls {
+			if err := ctl.Update(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	// Each Controller prepares its frame, then waits until every other Controller is ready before writing, so
+	// that the segments latch as close together as possible.
+	errs := make([]error, len(multi.ctls))
+	finished := make([]time.Time, len(multi.ctls))
+	var ready, wg sync.WaitGroup
+	release := make(chan struct{})
+	ready.Add(len(multi.ctls))
+	wg.Add(len(multi.ctls))
+	for i, ctl := range multi.ctls {
+		go func(i int, ctl *Controller) {
+			defer wg.Done()
+			waiting := true
+			errs[i] = ctl.update(ctl.driver.WriteFrame, func() {
+				waiting = false
+				ready.Done()
+				<-release
+			})
+			// A Controller that fails before writing must not hold up the others.
+			if waiting {
+				ready.Done()
+			}
+			finished[i] = ctl.now()
+		}(i, ctl)
+	}
+	ready.Wait()
+	close(release)
+	wg.Wait()
+	multi.recordSkew(finished)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Skew returns the time between the first and last Controller finishing their writes in the last parallel Update().
+
+This is an indication of how far apart the segments of the strip latched their new colours.
+*/
+func (multi *MultiController) Skew() time.Duration {
+	return multi.skew
+}
+
+/*
+Internal method used to record the spread of the times the Controllers finished writing.
+*/
+func (multi *MultiController) recordSkew(finished []time.Time) {
+	multi.skew = 0
+	for _, a := range finished {
+		for _, b := range finished {
+			if gap := b.Sub(a); gap > multi.skew {
+				multi.skew = gap
+			}
+		}
+	}
+}
+
+/*
+Close closes every Controller, returning the first error.
+*/
+func (multi *MultiController) Close() error {
+	var firstErr error
+	for _, ctl := range multi.ctls {
+		if err := ctl.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+/*
+SetColour records the Colour that an LED should be set to at the next Update().
+
+If position is out of bounds, no update is made.
+*/
+func (multi *MultiController) SetColour(position int, colour Colour) {
+	if ctl, offset := multi.locate(position); ctl != nil {
+		ctl.SetColour(offset, colour)
+	}
+}
+
+/*
+GetColour retrieves the previously set colour of an LED in the given position.
+
+If position is out of bounds then a zero value Colour is returned
+*/
+func (multi *MultiController) GetColour(position int) Colour {
+	if ctl, offset := multi.locate(position); ctl != nil {
+		return ctl.GetColour(offset)
+	}
+	return Colour{}
+}
+
+/*
+SetColours updates the LED colours to the values given, starting at position 0.
+
+If more Colour values are given than LEDs, the additional Colours are ignored.
+*/
+func (multi *MultiController) SetColours(clrs []Colour) {
+	multi.SetColoursAt(0, clrs)
+}
+
+/*
+SetColoursAt updates the LED colours starting at position offset to the values given.
+*/
+func (multi *MultiController) SetColoursAt(offset int, clrs []Colour) {
+	start := 0
+	for _, ctl := range multi.ctls {
+		ctl.SetColoursAt(offset-start, clrs)
+		start += ctl.Len()
+	}
+}
+
+/*
+Fill sets all LEDs to the given Colour.
+*/
+func (multi *MultiController) Fill(c Colour) {
+	for _, ctl := range multi.ctls {
+		ctl.Fill(c)
+	}
+}
+
+/*
+Clear turns off all LEDs.  This does not trigger Update().
+*/
+func (multi *MultiController) Clear() {
+	multi.Fill(Off)
+}
+
+/*
+Snapshot returns a copy of the currently set colours of all LEDs.
+*/
+func (multi *MultiController) Snapshot() []Colour {
+	result := make([]Colour, 0, multi.Len())
+	for _, ctl := range multi.ctls {
+		result = append(result, ctl.ledColours...)
+	}
+	return result
+}
+
+/*
+SetGlobalBrightness sets the global brightness of every Controller.
+*/
+func (multi *MultiController) SetGlobalBrightness(brightness uint8) {
+	for _, ctl := range multi.ctls {
+		ctl.SetGlobalBrightness(brightness)
+	}
+}
+
+/*
+GetGlobalBrightness returns the global brightness of the first Controller.
+*/
+func (multi *MultiController) GetGlobalBrightness() uint8 {
+	if len(multi.ctls) == 0 {
+		return 255
+	}
+	return multi.ctls[0].GetGlobalBrightness()
+}
+
+/*
+Internal method used to find the Controller and position within it for a position in the joined strip.
+*/
+func (multi *MultiController) locate(position int) (*Controller, int) {
+	if position < 0 {
+		return nil, 0
+	}
+	start := 0
+	for _, ctl := range multi.ctls {
+		if position < start+ctl.Len() {
+			return ctl, position - start
+		}
+		start += ctl.Len()
+	}
+	return nil, 0
+}
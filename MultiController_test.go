@@ -0,0 +1,88 @@
+package dotstar
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultiController(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		outA, outB := &frameRecorder{}, &frameRecorder{}
+		a, b := NewController(outA, 2), NewController(outB, 3)
+		multi := NewMultiController(parallel, a, b)
+		if multi.Len() != 5 {
+			t.Errorf("Got length %d expected 5\n", multi.Len())
+		}
+
+		multi.SetColour(1, Red)
+		multi.SetColour(2, Green)
+		multi.SetColoursAt(3, []Colour{Blue, White, White})
+		if err := multi.Update(); err != nil {
+			t.Fatalf("Unexpected error %v\n", err)
+		}
+
+		expected := []Colour{Off, Red, Green, Blue, White}
+		for i, want := range expected {
+			if got := multi.GetColour(i); got != want {
+				t.Errorf("LED %d got %v expected %v\n", i, got, want)
+			}
+		}
+		if len(outA.frames) != 1 || len(outB.frames) != 1 || !bytes.Equal(outB.frames[0], b.buffer) {
+			t.Errorf("Controllers were not updated\n")
+		}
+		if b.GetColour(0) != Green || multi.GetColour(5) != Off {
+			t.Errorf("Got %v %v\n", b.GetColour(0), multi.GetColour(5))
+		}
+	}
+}
+
+func TestMultiControllerParallelPartialUpdate(t *testing.T) {
+	// The partial update Controller must wait for the slow Controller to prepare its frame before writing.
+	var prepared int32
+	slow := func(frame []Colour) []Colour {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt32(&prepared, 1)
+		return frame
+	}
+	driver := &randomAccessRecorder{}
+	a := NewDriverController(driver, 2, PartialUpdateConfig())
+	b := NewController(&frameRecorder{}, 2, MiddlewareConfig(slow))
+	a.Update()
+	multi := NewMultiController(true, a, b)
+
+	multi.SetColour(0, Red)
+	atomic.StoreInt32(&prepared, 0)
+	written := make(chan int32, 1)
+	driver.onWrite = func() {
+		written <- atomic.LoadInt32(&prepared)
+	}
+	if err := multi.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if len(driver.writes) != 1 {
+		t.Fatalf("Got writes %v expected a partial update\n", driver.writes)
+	}
+	if <-written != 1 {
+		t.Errorf("Partial update was written before the other Controller was ready\n")
+	}
+}
+
+func TestMultiControllerResize(t *testing.T) {
+	a, b := NewController(&frameRecorder{}, 2), NewController(&frameRecorder{}, 3)
+	multi := NewMultiController(false, a, b)
+	a.Resize(4)
+	if multi.Len() != 7 {
+		t.Errorf("Got length %d expected 7\n", multi.Len())
+	}
+
+	multi.SetColour(3, Red)
+	multi.SetColoursAt(4, []Colour{Green, Blue})
+	if a.GetColour(3) != Red || b.GetColour(0) != Green || b.GetColour(1) != Blue {
+		t.Errorf("Got %v %v %v\n", a.GetColour(3), b.GetColour(0), b.GetColour(1))
+	}
+	if got := multi.GetColour(4); got != Green {
+		t.Errorf("Got %v expected %v\n", got, Green)
+	}
+}
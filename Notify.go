@@ -0,0 +1,118 @@
+package dotstar
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+/*
+A NotifyPattern is the animation used by Notify to draw attention to the strip.
+*/
+type NotifyPattern int
+
+const (
+	// NotifyFlash switches the whole strip between the notification colour and the colours underneath.
+	NotifyFlash NotifyPattern = iota
+	// NotifyPulse fades the whole strip smoothly to the notification colour and back.
+	NotifyPulse
+	// NotifyChase runs a block of the notification colour along the strip.
+	NotifyChase
+)
+
+// notifyPeriod is the time taken by each flash, pulse or run along the strip.
+const notifyPeriod = 500 * time.Millisecond
+
+/*
+A notification is an overlay drawn over the frames sent by Update() until it ends.
+*/
+type notification struct {
+	ctx      context.Context
+	colour   Colour
+	pattern  NotifyPattern
+	start    time.Time
+	duration time.Duration
+}
+
+/*
+Notify shows colour over the current contents of the strip using pattern for duration.  This is intended for alerts
+such as a doorbell or a failed build.
+
+The notification is drawn over each frame sent by Update(), leaving the colours set untouched, so a running
+effects Animator keeps rendering underneath and shows again once the notification ends.  Notify returns straight
+away and is safe to call from any goroutine while the strip is being updated.  Nothing is shown unless Update() is
+called regularly, for example by an Animator.  A later notification replaces an earlier one.
+*/
+func (ctl *Controller) Notify(colour Colour, pattern NotifyPattern, duration time.Duration) {
+	ctl.NotifyContext(context.Background(), colour, pattern, duration)
+}
+
+/*
+NotifyContext starts a Notify that also ends early once ctx is done.
+*/
+func (ctl *Controller) NotifyContext(ctx context.Context, colour Colour, pattern NotifyPattern, duration time.Duration) {
+	ctl.notifyLock.Lock()
+	defer ctl.notifyLock.Unlock()
+	ctl.notify = &notification{ctx: ctx, colour: colour, pattern: pattern, start: ctl.now(), duration: duration}
+}
+
+/*
+Internal method used to find the notification to draw over the next frame, or nil if there is none.
+*/
+func (ctl *Controller) currentNotification() *notification {
+	ctl.notifyLock.Lock()
+	defer ctl.notifyLock.Unlock()
+	if ctl.notify != nil && (ctl.notify.ctx.Err() != nil || ctl.now().Sub(ctl.notify.start) >= ctl.notify.duration) {
+		ctl.notify = nil
+	}
+	return ctl.notify
+}
+
+/*
+Internal method used to end any notification, so that it is not drawn over the frame sent by Close().
+*/
+func (ctl *Controller) cancelNotification() {
+	ctl.notifyLock.Lock()
+	defer ctl.notifyLock.Unlock()
+	ctl.notify = nil
+}
+
+/*
+Internal method used to draw the notification over frame.
+*/
+func (n *notification) draw(frame []Colour, now time.Time) {
+	elapsed := now.Sub(n.start)
+	phase := float64(elapsed%notifyPeriod) / float64(notifyPeriod)
+	notifyFrame(frame, n.colour, n.pattern, phase)
+}
+
+/*
+Internal function used to draw a frame of a notification over the colours in frame, with phase running from 0
+to 1 over each notifyPeriod.
+*/
+func notifyFrame(frame []Colour, colour Colour, pattern NotifyPattern, phase float64) {
+	switch pattern {
+	case NotifyPulse:
+		ratio := float32((1 - math.Cos(2*math.Pi*phase)) / 2)
+		for i := range frame {
+			frame[i] = frame[i].Blend(colour, ratio)
+		}
+	case NotifyChase:
+		width := len(frame) / 8
+		if width < 1 {
+			width = 1
+		}
+		head := int(phase * float64(len(frame)+width))
+		for i := range frame {
+			if i < head && i >= head-width {
+				frame[i] = colour
+			}
+		}
+	default:
+		if phase < 0.5 {
+			for i := range frame {
+				frame[i] = colour
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package dotstar
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 8, DisableGammaCorrectionConfig())
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time { return clock }
+	background := []Colour{Blue, Blue, Blue, Blue, Green, Green, Green, Green}
+	ctl.SetColours(background)
+
+	ctl.Notify(Red, NotifyFlash, time.Second)
+	ctl.Update()
+	frame, _ := DecodeFrame(out.frames[len(out.frames)-1], "bgr")
+	if frame[0] != Red || frame[7] != Red {
+		t.Errorf("Got %v expected the notification\n", frame)
+	}
+	// The colours underneath can still be changed while the notification is shown.
+	background[0] = White
+	ctl.SetColour(0, White)
+	for i, clr := range ctl.Snapshot() {
+		if clr != background[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, clr, background[i])
+		}
+	}
+
+	clock = clock.Add(notifyPeriod * 3 / 4)
+	ctl.Update()
+	frame, _ = DecodeFrame(out.frames[len(out.frames)-1], "bgr")
+	if frame[0] != White || frame[7] != Green {
+		t.Errorf("Got %v expected the colours between flashes\n", frame)
+	}
+
+	clock = clock.Add(time.Second)
+	ctl.Update()
+	frame, _ = DecodeFrame(out.frames[len(out.frames)-1], "bgr")
+	for i, clr := range frame {
+		if clr != background[i] {
+			t.Errorf("LED %d got %v expected %v after the notification\n", i, clr, background[i])
+		}
+	}
+}
+
+func TestNotifyFrames(t *testing.T) {
+	blue := func() []Colour {
+		return []Colour{Blue, Blue, Blue, Blue, Blue, Blue, Blue, Blue}
+	}
+
+	frame := blue()
+	notifyFrame(frame, Red, NotifyFlash, 0.25)
+	if frame[0] != Red || frame[7] != Red {
+		t.Errorf("Got %v\n", frame)
+	}
+	frame = blue()
+	notifyFrame(frame, Red, NotifyFlash, 0.75)
+	if frame[0] != Blue {
+		t.Errorf("Got %v\n", frame)
+	}
+	frame = blue()
+	notifyFrame(frame, Red, NotifyPulse, 0.5)
+	if frame[3] != Red {
+		t.Errorf("Got %v\n", frame[3])
+	}
+	frame = blue()
+	notifyFrame(frame, Red, NotifyChase, 0.5)
+	lit := 0
+	for _, clr := range frame {
+		if clr == Red {
+			lit++
+		}
+	}
+	if lit != 1 || frame[3] != Red {
+		t.Errorf("Got %v\n", frame)
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 2)
+	ctl.SetColour(0, Green)
+	ctx, cancel := context.WithCancel(context.Background())
+	ctl.NotifyContext(ctx, Red, NotifyFlash, time.Hour)
+	cancel()
+	ctl.Update()
+	if frame, _ := DecodeFrame(out.frames[0], "bgr"); frame[0] != ctl.GetColour(0) {
+		t.Errorf("Got %v expected %v\n", frame[0], ctl.GetColour(0))
+	}
+}
+
+func TestNotifyWhileUpdating(t *testing.T) {
+	// Run with -race to check that Notify can be called while another goroutine updates the strip.
+	ctl := NewController(&frameRecorder{}, 4)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			ctl.Fill(Blue)
+			ctl.Update()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		ctl.Notify(Red, NotifyPulse, time.Millisecond)
+	}
+	wg.Wait()
+}
+
+func TestNotifyClose(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 2)
+	ctl.Notify(Red, NotifyFlash, time.Hour)
+	ctl.Close()
+	if frame, _ := DecodeFrame(out.frames[len(out.frames)-1], "bgr"); frame[0] != ctl.GetColour(0) {
+		t.Errorf("Got %v expected the strip to be turned off\n", frame[0])
+	}
+}
@@ -0,0 +1,65 @@
+package dotstar
+
+import (
+	"math"
+)
+
+/*
+A Palette is a gradient through a list of evenly spaced colours.
+
+Palettes are used to map a value, such as the output of a noise function or the heat of a fire, onto colours.
+*/
+type Palette []Colour
+
+/*
+At returns the colour at position t along the Palette, from 0 (the first colour) to 1 (the last colour).
+
+Positions between colours are blended.  Values of t outside 0 to 1 are clamped.
+*/
+func (p Palette) At(t float64) Colour {
+	switch len(p) {
+	case 0:
+		return Off
+	case 1:
+		return p[0]
+	}
+	if t <= 0 {
+		return p[0]
+	}
+	if t >= 1 {
+		return p[len(p)-1]
+	}
+	position := t * float64(len(p)-1)
+	index := math.Floor(position)
+	return p[int(index)].Blend(p[int(index)+1], float32(position-index))
+}
+
+// RainbowPalette moves through the colours of the rainbow, returning to red at the end.
+var RainbowPalette = Palette{
+	Red, NewColour(255, 127, 0, 255), NewColour(255, 255, 0, 255), Green,
+	Blue, NewColour(75, 0, 130, 255), NewColour(148, 0, 211, 255), Red,
+}
+
+// HeatPalette moves from black through red, orange and yellow to white, like the colours of a flame.
+var HeatPalette = Palette{
+	NewColour(0, 0, 0, 255), NewColour(128, 0, 0, 255), Red, NewColour(255, 102, 0, 255),
+	NewColour(255, 204, 0, 255), White,
+}
+
+// LavaPalette is mostly dark reds with occasional bright orange, for molten lava.
+var LavaPalette = Palette{
+	NewColour(0, 0, 0, 255), NewColour(128, 0, 0, 255), NewColour(0, 0, 0, 255), NewColour(139, 0, 0, 255),
+	NewColour(128, 0, 0, 255), Red, NewColour(255, 165, 0, 255), NewColour(139, 0, 0, 255),
+}
+
+// CloudPalette is blues and white, for drifting clouds.
+var CloudPalette = Palette{
+	Blue, NewColour(0, 0, 139, 255), NewColour(135, 206, 235, 255), White,
+	NewColour(173, 216, 230, 255), NewColour(135, 206, 235, 255), Blue,
+}
+
+// OceanPalette is deep blues, teals and aqua, for water.
+var OceanPalette = Palette{
+	NewColour(0, 0, 128, 255), NewColour(0, 0, 139, 255), NewColour(0, 139, 139, 255), NewColour(0, 128, 128, 255),
+	NewColour(0, 255, 255, 255), NewColour(127, 255, 212, 255), NewColour(0, 0, 139, 255),
+}
@@ -0,0 +1,28 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestPaletteAt(t *testing.T) {
+	p := Palette{Red, Green, Blue}
+	tests := []struct {
+		t        float64
+		expected Colour
+	}{
+		{-1, Red},
+		{0, Red},
+		{0.25, NewColour(127, 127, 0, 255)},
+		{0.5, Green},
+		{1, Blue},
+		{2, Blue},
+	}
+	for _, test := range tests {
+		if got := p.At(test.t); got != test.expected {
+			t.Errorf("At(%v) got %v expected %v\n", test.t, got, test.expected)
+		}
+	}
+	if (Palette{}).At(0.5) != Off {
+		t.Errorf("Empty palette should be Off\n")
+	}
+}
@@ -0,0 +1,73 @@
+package dotstar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+ParseColour converts a colour description into a Colour, returning an error if it cannot be understood.
+
+The following forms are accepted, ignoring case and surrounding space:
+
+	#RGB, #RGBL        short hex, each digit repeated (#f80 is #ff8800)
+	#RRGGBB, #RRGGBBLL hex as used by NewColourFromStr
+	rgb(r, g, b)       decimal channels from 0 to 255
+	tomato             CSS / X11 colour names
+
+The luminosity is 255 unless given in hex.
+*/
+func ParseColour(clr string) (Colour, error) {
+	text := strings.ToLower(strings.TrimSpace(clr))
+	if named, ok := colourNames[text]; ok {
+		return named, nil
+	}
+
+	if strings.HasPrefix(text, "#") {
+		return parseHexColour(text[1:], clr)
+	}
+
+	if strings.HasPrefix(text, "rgb(") && strings.HasSuffix(text, ")") {
+		parts := strings.Split(text[4:len(text)-1], ",")
+		if len(parts) != 3 {
+			return Off, fmt.Errorf("Colour %q must have three channels", clr)
+		}
+		var channels [3]uint8
+		for i, part := range parts {
+			value, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+			if err != nil {
+				return Off, fmt.Errorf("Colour %q has invalid channel %q", clr, strings.TrimSpace(part))
+			}
+			channels[i] = uint8(value)
+		}
+		return NewColour(channels[0], channels[1], channels[2], 255), nil
+	}
+
+	return Off, fmt.Errorf("Unknown colour %q", clr)
+}
+
+/*
+Internal function used to parse the digits of a hex colour, original being the full text for errors.
+*/
+func parseHexColour(digits string, original string) (Colour, error) {
+	// Short forms repeat each digit.
+	if len(digits) == 3 || len(digits) == 4 {
+		long := make([]byte, 0, len(digits)*2)
+		for i := 0; i < len(digits); i++ {
+			long = append(long, digits[i], digits[i])
+		}
+		digits = string(long)
+	}
+	if len(digits) != 6 && len(digits) != 8 {
+		return Off, fmt.Errorf("Colour %q must have 3, 4, 6 or 8 hex digits", original)
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Off, fmt.Errorf("Colour %q is not valid hex", original)
+	}
+	if len(digits) == 6 {
+		value = value<<8 | 0xFF
+	}
+	return NewColour(uint8(value>>24), uint8(value>>16), uint8(value>>8), uint8(value)), nil
+}
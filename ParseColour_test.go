@@ -0,0 +1,37 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestParseColour(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected Colour
+	}{
+		{"tomato", NewColour(255, 99, 71, 255)},
+		{" DeepSkyBlue ", NewColour(0, 191, 255, 255)},
+		{"#f80", NewColour(255, 136, 0, 255)},
+		{"#f808", NewColour(255, 136, 0, 136)},
+		{"#102030", NewColour(16, 32, 48, 255)},
+		{"#10203040", NewColour(16, 32, 48, 64)},
+		{"rgb(1, 2,3)", NewColour(1, 2, 3, 255)},
+	}
+	for _, test := range tests {
+		clr, err := ParseColour(test.text)
+		if err != nil || clr != test.expected {
+			t.Errorf("Got %v, %v expected %v for %q\n", clr, err, test.expected, test.text)
+		}
+	}
+}
+
+func TestParseColourErrors(t *testing.T) {
+	for _, text := range []string{"", "notacolour", "#12", "#12345g", "rgb(1,2)", "rgb(1,2,256)"} {
+		if clr, err := ParseColour(text); err == nil {
+			t.Errorf("Got %v expected an error for %q\n", clr, text)
+		}
+	}
+	if clr := NewColourFromStr("bogus"); clr != NewColour(0, 0, 0, 255) {
+		t.Errorf("Got %v expected black\n", clr)
+	}
+}
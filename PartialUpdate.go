@@ -0,0 +1,62 @@
+package dotstar
+
+import (
+	"bytes"
+)
+
+/*
+PartialUpdateConfig makes Update() send only the LEDs that have changed since the last message, when the Driver
+is a RandomAccessDriver.  Other Drivers are sent the whole message as usual.
+
+The first Update(), and the first after a failure or a change in the size of the message, sends the whole
+message.  Runs of changed LEDs are each sent with one WriteAt call, and nothing is sent if no LEDs have changed.
+*/
+func PartialUpdateConfig() ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.partialUpdate = true
+	}
+}
+
+/*
+Internal method used to send the runs of packets in frame that differ from the last message sent, returning the
+number of bytes sent.
+*/
+func (ctl *Controller) writeChanged(driver RandomAccessDriver, frame []byte) (int, error) {
+	written := 0
+	runStart := -1
+	for i := 0; i <= ctl.physicalCount; i++ {
+		offset := len(ctl.protocol.Header) + i*ctl.packetSize
+		changed := false
+		if i < ctl.physicalCount {
+			changed = !bytes.Equal(frame[offset:offset+ctl.packetSize], ctl.sent[offset:offset+ctl.packetSize])
+		}
+		if changed && runStart == -1 {
+			runStart = offset
+		}
+		if !changed && runStart != -1 {
+			n, err := driver.WriteAt(frame[runStart:offset], int64(runStart))
+			written += n
+			if err != nil {
+				return written, err
+			}
+			runStart = -1
+		}
+	}
+	return written, nil
+}
+
+/*
+Internal method used to keep a copy of the message after Update(), so the next can be compared with it.
+
+After a failed write the state of the strip is unknown, so the whole message is sent next time.
+*/
+func (ctl *Controller) recordSent(err error) {
+	if err != nil {
+		ctl.sent = nil
+		return
+	}
+	if len(ctl.sent) != len(ctl.buffer) {
+		ctl.sent = make([]byte, len(ctl.buffer), len(ctl.buffer))
+	}
+	copy(ctl.sent, ctl.buffer)
+}
@@ -0,0 +1,94 @@
+package dotstar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// randomAccessRecorder keeps a copy of the frame and records the writes made to it.
+type randomAccessRecorder struct {
+	frame  []byte
+	frames int
+	writes [][2]int64
+	fail   bool
+	// onWrite is called, if set, before each WriteAt.
+	onWrite func()
+}
+
+func (r *randomAccessRecorder) WriteFrame(frame []byte) error {
+	if r.fail {
+		return errors.New("Write failed")
+	}
+	r.frame = append(r.frame[:0], frame...)
+	r.frames++
+	return nil
+}
+
+func (r *randomAccessRecorder) WriteAt(p []byte, offset int64) (int, error) {
+	if r.onWrite != nil {
+		r.onWrite()
+	}
+	if r.fail {
+		return 0, errors.New("Write failed")
+	}
+	copy(r.frame[offset:], p)
+	r.writes = append(r.writes, [2]int64{offset, int64(len(p))})
+	return len(p), nil
+}
+
+func (r *randomAccessRecorder) Close() error {
+	return nil
+}
+
+func TestPartialUpdate(t *testing.T) {
+	driver := &randomAccessRecorder{}
+	ctl := NewDriverController(driver, 10, PartialUpdateConfig())
+	ctl.Update()
+	if driver.frames != 1 || len(driver.writes) != 0 {
+		t.Fatalf("Got %d frames and %v writes, expected the first update to send the whole message\n", driver.frames, driver.writes)
+	}
+
+	ctl.SetColour(2, Red)
+	ctl.SetColour(3, Red)
+	ctl.SetColour(7, Blue)
+	ctl.Update()
+	expected := [][2]int64{{headerSize + 2*ledPacketSize, 2 * ledPacketSize}, {headerSize + 7*ledPacketSize, ledPacketSize}}
+	if len(driver.writes) != 2 || driver.writes[0] != expected[0] || driver.writes[1] != expected[1] {
+		t.Errorf("Got %v expected %v\n", driver.writes, expected)
+	}
+	if !bytes.Equal(driver.frame, ctl.buffer) {
+		t.Errorf("Got % X expected % X\n", driver.frame, ctl.buffer)
+	}
+	if stats := ctl.Stats(); stats.Bytes != uint64(len(ctl.buffer)+3*ledPacketSize) {
+		t.Errorf("Got %d bytes sent\n", stats.Bytes)
+	}
+
+	// Nothing is sent when nothing has changed.
+	driver.writes = nil
+	ctl.Update()
+	if len(driver.writes) != 0 || driver.frames != 1 {
+		t.Errorf("Got %v writes\n", driver.writes)
+	}
+
+	// After a failure the whole message is sent.
+	driver.fail = true
+	ctl.SetColour(0, Green)
+	if err := ctl.Update(); err == nil {
+		t.Fatalf("Expected an error\n")
+	}
+	driver.fail = false
+	ctl.Update()
+	if driver.frames != 2 || !bytes.Equal(driver.frame, ctl.buffer) {
+		t.Errorf("Got %d frames expected 2\n", driver.frames)
+	}
+
+	// Drivers without WriteAt are always sent the whole message.
+	out := &frameRecorder{}
+	plain := NewController(out, 3, PartialUpdateConfig())
+	plain.Update()
+	plain.Update()
+	if len(out.frames) != 2 {
+		t.Errorf("Got %d frames expected 2\n", len(out.frames))
+	}
+}
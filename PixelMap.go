@@ -0,0 +1,115 @@
+package dotstar
+
+/*
+ReverseConfig reverses the strip, so that position 0 is the LED furthest from the controller.
+
+This is useful when a strip is mounted with its input at the far end.
+*/
+func ReverseConfig() ConfigFunc {
+	return remapConfig(func(pixels [][]int) [][]int {
+		reversed := make([][]int, len(pixels))
+		for i := range pixels {
+			reversed[i] = pixels[len(pixels)-1-i]
+		}
+		return reversed
+	})
+}
+
+/*
+MirrorConfig treats the strip as two halves mirrored about its centre.
+
+Each position sets an LED in both halves, position 0 being both ends of the strip, so Len() is half the number
+of LEDs (rounded up).  Effects then run symmetrically from the ends towards the middle.
+*/
+func MirrorConfig() ConfigFunc {
+	return remapConfig(func(pixels [][]int) [][]int {
+		mirrored := make([][]int, (len(pixels)+1)/2)
+		for i := range mirrored {
+			mirrored[i] = pixels[i]
+			if other := len(pixels) - 1 - i; other != i {
+				mirrored[i] = append(append([]int(nil), pixels[i]...), pixels[other]...)
+			}
+		}
+		return mirrored
+	})
+}
+
+/*
+PixelMapConfig maps each position to the LED in the chain given by pixels, so that pixels[0] = 5 makes position 0
+set the sixth LED.
+
+Len() becomes one more than the largest position in pixels.  Positions missing from pixels are ignored when set,
+and LEDs in the chain without a position are left off.  Mapped LEDs beyond the end of the chain are ignored.
+*/
+func PixelMapConfig(pixels map[int]int) ConfigFunc {
+	return remapConfig(func(chain [][]int) [][]int {
+		count := 0
+		for position := range pixels {
+			if position >= count {
+				count = position + 1
+			}
+		}
+		mapped := make([][]int, count)
+		for position, led := range pixels {
+			if position >= 0 && led >= 0 && led < len(chain) {
+				mapped[position] = chain[led]
+			}
+		}
+		return mapped
+	})
+}
+
+/*
+SkipConfig leaves out the given LEDs of the chain, which are kept off.
+
+This is used to skip dead LEDs, or sacrificial LEDs at the start of the chain used as level shifters.  Later
+LEDs move down to fill the gaps, so SkipConfig(0) on a 30 LED strip gives positions 0 to 28 on LEDs 1 to 29.
+*/
+func SkipConfig(leds ...int) ConfigFunc {
+	return remapConfig(func(chain [][]int) [][]int {
+		skip := make(map[int]bool, len(leds))
+		for _, led := range leds {
+			skip[led] = true
+		}
+		var kept [][]int
+		for i, pixels := range chain {
+			if !skip[i] {
+				kept = append(kept, pixels)
+			}
+		}
+		return kept
+	})
+}
+
+/*
+Internal function used to build a ConfigFunc that changes how positions map onto the LEDs in the chain.
+
+Remaps are applied in the order they are configured, each working on the positions left by the last.
+*/
+func remapConfig(remap func(pixels [][]int) [][]int) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.remaps = append(ctl.remaps, remap)
+	}
+}
+
+/*
+Internal method used to work out the pixelMap and LED count from the physical LED count and remaps.
+*/
+func (ctl *Controller) applyRemaps() {
+	ctl.count = ctl.physicalCount
+	ctl.pixelMap = nil
+	if len(ctl.remaps) > 0 {
+		pixels := make([][]int, ctl.physicalCount)
+		for i := range pixels {
+			pixels[i] = []int{i}
+		}
+		for _, remap := range ctl.remaps {
+			pixels = remap(pixels)
+		}
+		ctl.pixelMap = pixels
+		ctl.count = len(pixels)
+	}
+	clrs := make([]Colour, ctl.count, ctl.count)
+	copy(clrs, ctl.ledColours)
+	ctl.ledColours = clrs
+}
@@ -0,0 +1,94 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+// physicalColours returns the colours sent to each LED of the chain by strip.
+func physicalColours(t *testing.T, strip *Controller) []Colour {
+	t.Helper()
+	clrs, err := DecodeFrame(strip.buffer, "bgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clrs
+}
+
+func checkPhysical(t *testing.T, strip *Controller, expected []Colour) {
+	t.Helper()
+	got := physicalColours(t, strip)
+	if len(got) != len(expected) {
+		t.Fatalf("Got %v expected %v\n", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Physical LED %d got %v expected %v\n", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestReverseConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 4, ReverseConfig(), DisableGammaCorrectionConfig())
+	strip.SetColour(0, Red)
+	strip.SetColours([]Colour{Red, Green})
+	checkPhysical(t, strip, []Colour{Off, Off, Green, Red})
+
+	strip.Rotate(1)
+	checkPhysical(t, strip, []Colour{Off, Green, Red, Off})
+	strip.Shift(-1)
+	checkPhysical(t, strip, []Colour{Off, Off, Green, Red})
+	strip.FillRange(2, 4, Blue)
+	checkPhysical(t, strip, []Colour{Blue, Blue, Green, Red})
+	if strip.GetColour(0) != Red || strip.Len() != 4 {
+		t.Errorf("Got %v\n", strip.Snapshot())
+	}
+}
+
+func TestMirrorConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 5, MirrorConfig(), DisableGammaCorrectionConfig())
+	if strip.Len() != 3 {
+		t.Fatalf("Got length %d expected 3\n", strip.Len())
+	}
+	strip.SetColours([]Colour{Red, Green, Blue})
+	checkPhysical(t, strip, []Colour{Red, Green, Blue, Green, Red})
+
+	strip.Resize(4)
+	checkPhysical(t, strip, []Colour{Red, Green, Green, Red})
+	if strip.Len() != 2 {
+		t.Errorf("Got length %d expected 2\n", strip.Len())
+	}
+}
+
+func TestReverseMirrorLazy(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 4, MirrorConfig(), ReverseConfig(), LazyEncodeConfig(), DisableGammaCorrectionConfig())
+	strip.SetColours([]Colour{Red, Green})
+	strip.Update()
+	checkPhysical(t, strip, []Colour{Green, Red, Red, Green})
+}
+
+func TestPixelMapConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 4, PixelMapConfig(map[int]int{0: 3, 1: 1, 3: 9}), DisableGammaCorrectionConfig())
+	if strip.Len() != 4 {
+		t.Fatalf("Got length %d expected 4\n", strip.Len())
+	}
+	strip.SetColours([]Colour{Red, Green, Blue, White})
+	checkPhysical(t, strip, []Colour{Off, Green, Off, Red})
+	if strip.GetColour(2) != Blue {
+		t.Errorf("Got %v expected the colour to be kept\n", strip.GetColour(2))
+	}
+}
+
+func TestSkipConfig(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 5, SkipConfig(0, 3), DisableGammaCorrectionConfig())
+	if strip.Len() != 3 {
+		t.Fatalf("Got length %d expected 3\n", strip.Len())
+	}
+	strip.Fill(Red)
+	strip.SetColour(2, Blue)
+	checkPhysical(t, strip, []Colour{Off, Red, Red, Off, Blue})
+
+	// Skipping applies after reversing, so the reversed strip loses its new first LED.
+	reversed := NewController(&frameRecorder{}, 3, ReverseConfig(), SkipConfig(0), DisableGammaCorrectionConfig())
+	reversed.SetColours([]Colour{Red, Green})
+	checkPhysical(t, reversed, []Colour{Green, Red, Off})
+}
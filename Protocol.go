@@ -0,0 +1,50 @@
+package dotstar
+
+import (
+	"errors"
+)
+
+/*
+A Protocol describes the framing of the messages sent to the strip.
+
+Each message is the Header, followed by one packet per LED and then the end frame.  Every packet starts with a
+byte holding Marker in its top 3 bits and the 5-bit brightness in the rest, followed by the colour values in the
+order set by OrderConfig.  Clone chips that need a longer or different start frame can be supported by changing
+the Header, for example some HD107S strips run more reliably at high clock speeds with a longer header.
+*/
+type Protocol struct {
+	// Header is sent at the start of every message.
+	Header []byte
+	// Marker holds the bits set at the start of every LED packet, only its top 3 bits may be set.
+	Marker byte
+}
+
+/*
+APA102Protocol is the framing used by the APA102 and compatible chips, which is the default.
+
+The header is 32 zero bits and each LED packet starts with 3 bits set.
+*/
+var APA102Protocol = Protocol{Header: []byte{0, 0, 0, 0}, Marker: brightnessHeader}
+
+/*
+ProtocolConfig sets the framing of the messages sent to the strip, see Protocol.
+
+The buffer is laid out once configuration is complete, so the Header may be of any length.  Decoders such as
+DecodeFrame, the Simulator and the WS2812Writer expect APA102Protocol.
+*/
+func ProtocolConfig(protocol Protocol) ConfigFunc {
+	return func(ctl *Controller) {
+		if protocol.Marker&^brightnessHeader != 0 {
+			ctl.invalidConfig(errors.New("Protocol marker must leave the 5 brightness bits clear"))
+			return
+		}
+		ctl.protocol = Protocol{Header: append([]byte(nil), protocol.Header...), Marker: protocol.Marker}
+	}
+}
+
+/*
+Protocol returns the framing of the messages sent to the strip.
+*/
+func (ctl *Controller) Protocol() Protocol {
+	return Protocol{Header: append([]byte(nil), ctl.protocol.Header...), Marker: ctl.protocol.Marker}
+}
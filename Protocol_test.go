@@ -0,0 +1,48 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProtocolConfig(t *testing.T) {
+	protocol := Protocol{Header: []byte{0, 0, 0, 0, 0, 0, 0, 0}, Marker: 0xC0}
+	out := &frameRecorder{}
+	strip, err := NewControllerE(out, 2, ProtocolConfig(protocol))
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	protocol.Header[0] = 0xAA
+	strip.SetColour(1, NewColour(255, 0, 0, 255))
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+
+	frame := out.frames[0]
+	standard := NewController(&frameRecorder{}, 2)
+	if len(frame) != len(standard.buffer)+4 {
+		t.Errorf("Got %d bytes expected %d\n", len(frame), len(standard.buffer)+4)
+	}
+	if !bytes.Equal(frame[:8], make([]byte, 8)) {
+		t.Errorf("Got header %v\n", frame[:8])
+	}
+	expected := []byte{0xC0, 0, 0, 0, 0xDF, 0, 0, 255}
+	if !bytes.Equal(frame[8:16], expected) {
+		t.Errorf("Got packets %v expected %v\n", frame[8:16], expected)
+	}
+	if got := strip.Protocol(); len(got.Header) != 8 || got.Marker != 0xC0 {
+		t.Errorf("Got %v\n", got)
+	}
+
+	if _, err := NewControllerE(out, 2, ProtocolConfig(Protocol{Marker: 0xE1})); err == nil {
+		t.Errorf("Expected an error for a marker using the brightness bits\n")
+	}
+}
+
+func TestProtocolDefault(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 1, ProtocolConfig(APA102Protocol))
+	standard := NewController(&frameRecorder{}, 1)
+	if !bytes.Equal(strip.buffer, standard.buffer) {
+		t.Errorf("Got %v expected %v\n", strip.buffer, standard.buffer)
+	}
+}
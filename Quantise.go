@@ -0,0 +1,65 @@
+package dotstar
+
+/*
+Quantise returns the colour as an LED actually shows it with the given global brightness and gamma table, after
+the red, green and blue values are gamma corrected and the luminosity is rounded to one of the 32 brightness
+levels the LEDs support.  A nil gamma table leaves the values unchanged.
+
+This lets fades and palettes be checked for steps that collapse onto the same output: two colours show the same
+if they quantise to the same colour.  The luminosity returned is the brightness level scaled back to 0-255, the
+same as DecodeFrame returns.  Colour correction and the SK9822 and folded luminosity modes are not included.
+*/
+func (c Colour) Quantise(brightness uint8, gamma *GammaTable) Colour {
+	if gamma != nil {
+		c.R, c.G, c.B, c.W = gamma[c.R], gamma[c.G], gamma[c.B], gamma[c.W]
+	}
+	c.L = uint8(uint32(luminosityLevel(c.L, brightness)) * 255 / 31)
+	return c
+}
+
+/*
+QuantisationStep returns how much value must increase before its gamma corrected value changes, or 0 if no larger
+value gives a different output.  A nil gamma table means no gamma correction, so the step is always 1 below 255.
+
+With the default gamma of 2.8 the dim end of each channel changes in large steps, for example values from 0 to 27
+all show as off.
+*/
+func QuantisationStep(value uint8, gamma *GammaTable) int {
+	if gamma == nil {
+		if value == 255 {
+			return 0
+		}
+		return 1
+	}
+	for next := int(value) + 1; next < 256; next++ {
+		if gamma[next] != gamma[value] {
+			return next - int(value)
+		}
+	}
+	return 0
+}
+
+/*
+LuminosityStep returns how much the luminosity l must increase before the brightness level sent to the LED
+changes, at the given global brightness, or 0 if it is already at the brightest level.
+*/
+func LuminosityStep(l, brightness uint8) int {
+	level := luminosityLevel(l, brightness)
+	for next := int(l) + 1; next < 256; next++ {
+		if luminosityLevel(uint8(next), brightness) != level {
+			return next - int(l)
+		}
+	}
+	return 0
+}
+
+/*
+Internal function used to find the 5-bit brightness level sent for luminosity l at a global brightness, as
+encodePacket does.
+*/
+func luminosityLevel(l, brightness uint8) uint8 {
+	if brightness != 255 {
+		l = uint8(float32(brightness) * float32(l) / 255)
+	}
+	return l >> 3
+}
@@ -0,0 +1,54 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestQuantise(t *testing.T) {
+	gamma := NewGammaTable(2.8)
+	got := NewColour(10, 128, 255, 100).Quantise(255, gamma)
+	expected := NewColour(0, gamma[128], 255, 98)
+	if got != expected {
+		t.Errorf("Got %v expected %v\n", got, expected)
+	}
+	// Nearby values collapse onto the same output.
+	if NewColour(1, 0, 0, 200).Quantise(128, gamma) != NewColour(5, 0, 0, 203).Quantise(128, gamma) {
+		t.Errorf("Expected dim colours to quantise to the same colour\n")
+	}
+
+	// The quantised colour matches what is sent to the strip.
+	strip := NewController(&frameRecorder{}, 1)
+	strip.SetGlobalBrightness(128)
+	clr := NewColour(30, 60, 90, 200)
+	strip.SetColour(0, clr)
+	frame, err := DecodeFrame(strip.buffer, "bgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame[0] != clr.Quantise(128, NewGammaTable(2.8)) {
+		t.Errorf("Got %v expected %v\n", clr.Quantise(128, NewGammaTable(2.8)), frame[0])
+	}
+}
+
+func TestQuantisationStep(t *testing.T) {
+	gamma := NewGammaTable(2.8)
+	if step := QuantisationStep(0, gamma); step < 10 {
+		t.Errorf("Got step %d expected dim values to move in large steps\n", step)
+	}
+	if step := QuantisationStep(254, gamma); step != 1 {
+		t.Errorf("Got %v expected %v\n", step, 1)
+	}
+	if QuantisationStep(255, gamma) != 0 || QuantisationStep(255, nil) != 0 || QuantisationStep(3, nil) != 1 {
+		t.Errorf("Got wrong steps at the top or without gamma\n")
+	}
+
+	if step := LuminosityStep(0, 255); step != 8 {
+		t.Errorf("Got %v expected %v\n", step, 8)
+	}
+	if step := LuminosityStep(0, 128); step < 15 || step > 16 {
+		t.Errorf("Got %v expected around %v\n", step, 16)
+	}
+	if step := LuminosityStep(250, 255); step != 0 {
+		t.Errorf("Got %v expected %v\n", step, 0)
+	}
+}
@@ -0,0 +1,34 @@
+package dotstar
+
+/*
+ExtractWhite returns the colour with the white shared by the red, green and blue channels moved into W.
+
+The smallest of R, G and B is removed from each of them and added to W, saturating at 255.  This lets an RGBW
+strip show whites and pastels with its white LED rather than by mixing all three colours.
+*/
+func (c Colour) ExtractWhite() Colour {
+	white := c.R
+	if c.G < white {
+		white = c.G
+	}
+	if c.B < white {
+		white = c.B
+	}
+	c.R -= white
+	c.G -= white
+	c.B -= white
+	c.W = addChannel(c.W, white)
+	return c
+}
+
+/*
+AutoWhiteConfig applies ExtractWhite to every colour set on the Controller, so that effects written for RGB strips
+make use of the white channel of an RGBW strip.
+
+It has no effect unless an RGBW order, such as grbw, is also configured with OrderConfig.
+*/
+func AutoWhiteConfig() ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.autoWhite = true
+	}
+}
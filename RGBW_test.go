@@ -0,0 +1,79 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtractWhite(t *testing.T) {
+	if c := NewColour(200, 100, 150, 255).ExtractWhite(); c != NewColourW(100, 0, 50, 100, 255) {
+		t.Errorf("Got %v\n", c)
+	}
+	if c := NewColourW(255, 255, 255, 100, 255).ExtractWhite(); c != NewColourW(0, 0, 0, 255, 255) {
+		t.Errorf("Got %v\n", c)
+	}
+}
+
+func TestRGBWOrder(t *testing.T) {
+	out := &frameRecorder{}
+	grbw, err := OrderConfig("grbw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strip := NewController(out, 3, grbw, DisableGammaCorrectionConfig())
+	expected := []Colour{NewColourW(1, 2, 3, 4, 255), Off, NewColourW(255, 0, 128, 64, 255)}
+	strip.SetColours(expected)
+	strip.Update()
+
+	frame := out.frames[0]
+	if len(frame) != headerSize+3*5+strip.footerSize() {
+		t.Fatalf("Got %d bytes expected %d\n", len(frame), headerSize+3*5+strip.footerSize())
+	}
+	if packet := frame[headerSize : headerSize+5]; !bytes.Equal(packet, []byte{0xFF, 2, 1, 3, 4}) {
+		t.Errorf("Got % X expected FF 02 01 03 04\n", packet)
+	}
+
+	clrs, err := DecodeFrame(frame, "grbw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range expected {
+		if clrs[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, clrs[i], expected[i])
+		}
+	}
+
+	if _, err := OrderConfig("grbx"); err == nil {
+		t.Errorf("Expected an error for an invalid order\n")
+	}
+}
+
+func TestRGBWGammaTables(t *testing.T) {
+	grbw, _ := OrderConfig("grbw")
+	fast := NewController(&frameRecorder{}, 1, grbw, GammaConfig(2.8, 2.8, 2.8))
+	slow := NewController(&frameRecorder{}, 1, grbw, GammaConfig(2.8, 2.8, 2.8))
+	clr := NewColourW(10, 200, 30, 180, 200)
+	fast.SetColour(0, clr)
+	var packet [5]byte
+	slow.encodePacket(packet[:], clr)
+	if fast.tables == nil {
+		t.Fatalf("Expected the encode tables to be used\n")
+	}
+	if !bytes.Equal(fast.packet(0), packet[:]) {
+		t.Errorf("Got % X expected % X\n", fast.packet(0), packet[:])
+	}
+}
+
+func TestAutoWhiteConfig(t *testing.T) {
+	out := &frameRecorder{}
+	rgbw, _ := OrderConfig("rgbw")
+	strip := NewController(out, 1, rgbw, AutoWhiteConfig(), DisableGammaCorrectionConfig())
+	strip.SetColour(0, NewColour(50, 60, 70, 255))
+	if !bytes.Equal(strip.packet(0), []byte{0xFF, 0, 10, 20, 50}) {
+		t.Errorf("Got % X\n", strip.packet(0))
+	}
+	// The colour held by the Controller is unchanged.
+	if c := strip.GetColour(0); c != NewColour(50, 60, 70, 255) {
+		t.Errorf("Got %v\n", c)
+	}
+}
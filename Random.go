@@ -0,0 +1,91 @@
+package dotstar
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+RandomColour returns a colour with random red, green and blue values at full luminosity.
+
+If rng is nil the shared source of the math/rand package is used.  Passing a source with a fixed seed gives
+the same colours every time, which keeps effects built on random colours repeatable in tests.
+*/
+func RandomColour(rng *rand.Rand) Colour {
+	return NewColour(uint8(randomIntn(rng, 256)), uint8(randomIntn(rng, 256)), uint8(randomIntn(rng, 256)), 255)
+}
+
+/*
+RandomSaturatedColour returns a fully saturated colour of random hue at full luminosity.
+
+One of red, green and blue is always 255 and another 0, avoiding the washed out greys and browns that
+RandomColour often picks.  If rng is nil the shared source of the math/rand package is used.
+*/
+func RandomSaturatedColour(rng *rand.Rand) Colour {
+	return hueColour(randomFloat64(rng))
+}
+
+/*
+RandomPalette returns a Palette of n saturated colours with hues spread evenly around the colour wheel from a
+random starting hue, so that the colours are always distinct from each other.
+
+Each hue is moved by up to a quarter of the spacing between them to stop palettes looking too regular.  If rng
+is nil the shared source of the math/rand package is used.
+*/
+func RandomPalette(rng *rand.Rand, n int) Palette {
+	if n <= 0 {
+		return Palette{}
+	}
+	palette := make(Palette, n, n)
+	start := randomFloat64(rng)
+	spacing := 1 / float64(n)
+	for i := range palette {
+		jitter := (randomFloat64(rng) - 0.5) * spacing / 2
+		palette[i] = hueColour(start + float64(i)*spacing + jitter)
+	}
+	return palette
+}
+
+/*
+Internal function used to convert a hue, where 0 and 1 are red, into a fully saturated colour.
+*/
+func hueColour(hue float64) Colour {
+	hue = (hue - math.Floor(hue)) * 6
+	sector := int(hue)
+	rising := uint8((hue - float64(sector)) * 255)
+	falling := 255 - rising
+	switch sector {
+	case 0:
+		return NewColour(255, rising, 0, 255)
+	case 1:
+		return NewColour(falling, 255, 0, 255)
+	case 2:
+		return NewColour(0, 255, rising, 255)
+	case 3:
+		return NewColour(0, falling, 255, 255)
+	case 4:
+		return NewColour(rising, 0, 255, 255)
+	default:
+		return NewColour(255, 0, falling, 255)
+	}
+}
+
+/*
+Internal function used to pick a random number from 0 up to 1 from rng, or the shared source if rng is nil.
+*/
+func randomFloat64(rng *rand.Rand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+/*
+Internal function used to pick a random number from 0 up to n from rng, or the shared source if rng is nil.
+*/
+func randomIntn(rng *rand.Rand, n int) int {
+	if rng == nil {
+		return rand.Intn(n)
+	}
+	return rng.Intn(n)
+}
@@ -0,0 +1,66 @@
+package dotstar
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomColour(t *testing.T) {
+	a, b := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	for i := 0; i < 10; i++ {
+		if ca, cb := RandomColour(a), RandomColour(b); ca != cb || ca.L != 255 {
+			t.Errorf("Got %v and %v from the same seed\n", ca, cb)
+		}
+	}
+	// The shared source should also work.
+	if c := RandomColour(nil); c.L != 255 {
+		t.Errorf("Got %v\n", c)
+	}
+}
+
+func TestRandomSaturatedColour(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		c := RandomSaturatedColour(rng)
+		channels := []uint8{c.R, c.G, c.B}
+		full, empty := 0, 0
+		for _, value := range channels {
+			if value == 255 {
+				full++
+			}
+			if value == 0 {
+				empty++
+			}
+		}
+		if full == 0 || empty == 0 {
+			t.Errorf("Got %v which is not saturated\n", c)
+		}
+	}
+	if c := hueColour(1.0 / 3); c != Green {
+		t.Errorf("Got %v expected %v\n", c, Green)
+	}
+	if c := hueColour(1.5); c != NewColour(0, 255, 255, 255) {
+		t.Errorf("Got %v expected cyan\n", c)
+	}
+}
+
+func TestRandomPalette(t *testing.T) {
+	a := RandomPalette(rand.New(rand.NewSource(7)), 5)
+	b := RandomPalette(rand.New(rand.NewSource(7)), 5)
+	if len(a) != 5 {
+		t.Fatalf("Got %d colours expected 5\n", len(a))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("Colour %d got %v and %v from the same seed\n", i, a[i], b[i])
+		}
+		for j := i + 1; j < len(a); j++ {
+			if a[i] == a[j] {
+				t.Errorf("Colours %d and %d are both %v\n", i, j, a[i])
+			}
+		}
+	}
+	if len(RandomPalette(nil, 0)) != 0 {
+		t.Errorf("Expected an empty palette\n")
+	}
+}
@@ -0,0 +1,159 @@
+package dotstar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// recordingMagic is written at the start of every recording to identify the format.
+const recordingMagic = "DSTRREC1"
+
+// recordHeaderSize is the size of the per-frame header: 8 bytes of nanoseconds since the first frame and 4 bytes of length.
+const recordHeaderSize = 12
+
+// maxRecordedFrame is the largest frame recorded or played, enough for over 250000 LEDs.
+const maxRecordedFrame = 1 << 20
+
+/*
+A Recorder is a Driver that records each message sent by a Controller before passing it on to the strip.
+
+The recording starts with the 8 byte magic string "DSTRREC1".  Each frame is then stored as a big endian
+uint64 of nanoseconds since the first frame, a big endian uint32 length and the message bytes.  Frames over
+1MiB are rejected, both when recording and playing, so a corrupt recording cannot exhaust memory.
+*/
+type Recorder struct {
+	out    Driver
+	record io.Writer
+	start  time.Time
+	header [recordHeaderSize]byte
+}
+
+/*
+NewRecorder creates a Recorder that writes frames to record and then to out.
+
+out may be nil to record without an LED strip attached.
+*/
+func NewRecorder(out io.Writer, record io.Writer) *Recorder {
+	recorder := &Recorder{record: record}
+	if out != nil {
+		recorder.out = WriterDriver(out)
+	}
+	return recorder
+}
+
+/*
+WriteFrame records the message and sends it to the strip.
+*/
+func (r *Recorder) WriteFrame(frame []byte) error {
+	if len(frame) > maxRecordedFrame {
+		return fmt.Errorf("Frame of %d bytes is too large to record", len(frame))
+	}
+	if r.start.IsZero() {
+		r.start = time.Now()
+		if _, err := io.WriteString(r.record, recordingMagic); err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint64(r.header[:8], uint64(time.Since(r.start)))
+	binary.BigEndian.PutUint32(r.header[8:], uint32(len(frame)))
+	if _, err := r.record.Write(r.header[:]); err != nil {
+		return err
+	}
+	if _, err := r.record.Write(frame); err != nil {
+		return err
+	}
+
+	if r.out == nil {
+		return nil
+	}
+	return r.out.WriteFrame(frame)
+}
+
+/*
+Write records the message and sends it to the strip.  Each call must contain a whole message.
+*/
+func (r *Recorder) Write(p []byte) (int, error) {
+	if err := r.WriteFrame(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/*
+Close closes the strip output.  The recording writer is left for the caller to close.
+*/
+func (r *Recorder) Close() error {
+	if r.out == nil {
+		return nil
+	}
+	return r.out.Close()
+}
+
+/*
+A Player replays a recording made by a Recorder.
+*/
+type Player struct {
+	in *bufio.Reader
+}
+
+/*
+NewPlayer creates a Player that reads the recording from in.
+*/
+func NewPlayer(in io.Reader) *Player {
+	return &Player{in: bufio.NewReader(in)}
+}
+
+/*
+Play writes each recorded frame to out with the original timing, returning once the recording ends.
+
+speed scales the playback rate, 1 is the original speed and 2 twice as fast.  A speed of 0 or less plays
+the frames as fast as out accepts them.  out is normally the writer used by a Controller.
+*/
+func (p *Player) Play(out io.Writer, speed float64) error {
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(p.in, magic); err != nil {
+		return err
+	}
+	if string(magic) != recordingMagic {
+		return errors.New("Not a Dotstar recording")
+	}
+
+	driver := WriterDriver(out)
+	var header [recordHeaderSize]byte
+	var frame []byte
+	start := time.Now()
+	for {
+		if _, err := io.ReadFull(p.in, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		offset := time.Duration(binary.BigEndian.Uint64(header[:8]))
+		size := int(binary.BigEndian.Uint32(header[8:]))
+		if size > maxRecordedFrame {
+			return fmt.Errorf("Recorded frame of %d bytes is too large", size)
+		}
+		if cap(frame) < size {
+			frame = make([]byte, size, size)
+		}
+		frame = frame[:size]
+		if _, err := io.ReadFull(p.in, frame); err != nil {
+			return err
+		}
+
+		if speed > 0 {
+			wait := time.Duration(float64(offset)/speed) - time.Since(start)
+			if wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		if err := driver.WriteFrame(frame); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecordAndPlay(t *testing.T) {
+	recording := &bytes.Buffer{}
+	recorder := NewRecorder(nil, recording)
+	strip := NewController(recorder, 5)
+	var sent [][]byte
+	for _, clr := range []Colour{Red, Green, Blue} {
+		strip.Clear()
+		strip.SetColour(1, clr)
+		strip.Update()
+		sent = append(sent, append([]byte(nil), strip.buffer...))
+	}
+
+	played := &frameRecorder{}
+	if err := NewPlayer(recording).Play(played, 0); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if len(played.frames) != len(sent) {
+		t.Fatalf("Got %d frames expected %d\n", len(played.frames), len(sent))
+	}
+	for i := range sent {
+		if !bytes.Equal(played.frames[i], sent[i]) {
+			t.Errorf("Frame %d got % X expected % X\n", i, played.frames[i], sent[i])
+		}
+	}
+}
+
+func TestPlayRejectsBadRecording(t *testing.T) {
+	if err := NewPlayer(bytes.NewBufferString("notarecording")).Play(&frameRecorder{}, 1); err == nil {
+		t.Errorf("Expected an error playing an invalid recording\n")
+	}
+}
+
+func TestPlayRejectsOversizedFrame(t *testing.T) {
+	// A frame header claiming 4GiB must be rejected before any memory is allocated for it.
+	recording := bytes.NewBufferString(recordingMagic)
+	recording.Write([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF})
+	played := &frameRecorder{}
+	if err := NewPlayer(recording).Play(played, 0); err == nil {
+		t.Errorf("Expected an error for an oversized frame\n")
+	}
+	if len(played.frames) != 0 {
+		t.Errorf("Got %d frames expected 0\n", len(played.frames))
+	}
+}
+
+// frameRecorder keeps a copy of each frame written.
+type frameRecorder struct {
+	frames [][]byte
+}
+
+func (fr *frameRecorder) Write(p []byte) (int, error) {
+	fr.frames = append(fr.frames, append([]byte(nil), p...))
+	return len(p), nil
+}
@@ -0,0 +1,170 @@
+package dotstar
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// Defaults used by ReopenConfig.
+const (
+	defaultReopenFailures   = 3
+	defaultReopenBackoff    = 100 * time.Millisecond
+	defaultReopenMaxBackoff = 30 * time.Second
+)
+
+/*
+A ReopenEventKind says what happened in a ReopenEvent.
+*/
+type ReopenEventKind int
+
+const (
+	// ReopenUpdateFailed is sent for each failed Update().
+	ReopenUpdateFailed ReopenEventKind = iota
+	// ReopenSucceeded is sent when the writer has been reopened and the frame sent with it.
+	ReopenSucceeded
+	// ReopenFailed is sent when reopening the writer, or sending the frame with the new writer, fails.
+	ReopenFailed
+)
+
+/*
+A ReopenEvent reports the progress of a Reopener, for logging or monitoring.
+*/
+type ReopenEvent struct {
+	Kind ReopenEventKind
+	// Failures is the number of Update() calls that have failed in a row.
+	Failures int
+	// Err is the error from the failed update or reopen, nil for ReopenSucceeded.
+	Err error
+}
+
+/*
+A Reopener rebuilds the writer used by a Controller when updates keep failing, see ReopenConfig.
+*/
+type Reopener struct {
+	// Reopen opens a new writer for the strip, for example by opening the SPI device again.  It is required.
+	Reopen func() (io.Writer, error)
+	// Failures is the number of Update() calls in a row that must fail before reopening, 0 uses 3.
+	Failures int
+	// Backoff is the time to wait after a failed reopen before trying again, doubling with each failure up to
+	// MaxBackoff.  0 uses 100ms and a MaxBackoff of 30s.
+	Backoff, MaxBackoff time.Duration
+	// OnEvent, if set, is called on the goroutine calling Update() for each failure and reopen.
+	OnEvent func(ReopenEvent)
+
+	// failures counts the updates failed in a row, backoff is the current wait and retryAt when the next
+	// reopen may be tried.
+	failures int
+	backoff  time.Duration
+	retryAt  time.Time
+}
+
+/*
+ReopenConfig keeps a strip running through bus failures, such as a USB to SPI adapter being unplugged or
+re-enumerating, without restarting the program.
+
+Once Failures updates in a row have failed, the old writer is closed and r.Reopen is called to get a new one.  The
+failed frame is then sent again with it.  If reopening fails, further attempts are made by later Update() calls,
+waiting longer each time as set by Backoff, so an animation loop keeps running while the bus is missing.  Update()
+still returns the error of each failed update.  The new writer is used as NewController would use it, keeping any
+ChunkSizeConfig.
+*/
+func ReopenConfig(r *Reopener) ConfigFunc {
+	return func(ctl *Controller) {
+		if r == nil || r.Reopen == nil {
+			ctl.invalidConfig(errors.New("A Reopen function is required"))
+			return
+		}
+		if r.Failures < 0 || r.Backoff < 0 || r.MaxBackoff < 0 {
+			ctl.invalidConfig(errors.New("Reopen settings must not be negative"))
+			return
+		}
+		ctl.reopener = r
+	}
+}
+
+/*
+Internal method used to record the result of an update and reopen the writer once enough updates have failed.
+
+The error returned is that of the update, or nil if the frame was sent after reopening.
+*/
+func (ctl *Controller) superviseUpdate(err error) error {
+	r := ctl.reopener
+	if err == nil {
+		r.failures, r.backoff = 0, 0
+		return nil
+	}
+	r.failures++
+	r.event(ReopenEvent{Kind: ReopenUpdateFailed, Failures: r.failures, Err: err})
+
+	failures := r.Failures
+	if failures == 0 {
+		failures = defaultReopenFailures
+	}
+	now := ctl.now()
+	if r.failures < failures || now.Before(r.retryAt) {
+		return err
+	}
+
+	reopenErr := ctl.reopen()
+	if reopenErr == nil {
+		reopenErr = ctl.driver.WriteFrame(ctl.buffer)
+	}
+	if reopenErr != nil {
+		if r.backoff == 0 {
+			r.backoff = r.Backoff
+			if r.backoff == 0 {
+				r.backoff = defaultReopenBackoff
+			}
+		} else {
+			r.backoff *= 2
+		}
+		maxBackoff := r.MaxBackoff
+		if maxBackoff == 0 {
+			maxBackoff = defaultReopenMaxBackoff
+		}
+		if r.backoff > maxBackoff {
+			r.backoff = maxBackoff
+		}
+		r.retryAt = now.Add(r.backoff)
+		r.event(ReopenEvent{Kind: ReopenFailed, Failures: r.failures, Err: reopenErr})
+		return err
+	}
+
+	r.event(ReopenEvent{Kind: ReopenSucceeded, Failures: r.failures})
+	r.failures, r.backoff = 0, 0
+	return nil
+}
+
+/*
+Internal method used to close the current driver and replace it with a new one from the Reopener.
+*/
+func (ctl *Controller) reopen() error {
+	w, err := ctl.reopener.Reopen()
+	if err != nil {
+		return err
+	}
+	chunkSize := 0
+	if wd, ok := ctl.driver.(*writerDriver); ok {
+		chunkSize = wd.chunkSize
+	}
+	// The old writer has failed, so any error closing it is of no interest.
+	ctl.driver.Close()
+	ctl.driver = WriterDriver(w)
+	if wd, ok := ctl.driver.(*writerDriver); ok {
+		wd.chunkSize = chunkSize
+	}
+	// The new writer holds no previous frame.
+	ctl.sent = nil
+	ctl.announceFrameFormat()
+	return nil
+}
+
+/*
+Internal method used to send an event if there is a callback for it.
+*/
+func (r *Reopener) event(e ReopenEvent) {
+	if r.OnEvent != nil {
+		r.OnEvent(e)
+	}
+}
@@ -0,0 +1,80 @@
+package dotstar
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// brokenWriter fails every write, recording whether it was closed.
+type brokenWriter struct {
+	closed bool
+}
+
+func (bw *brokenWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("Device unplugged")
+}
+
+func (bw *brokenWriter) Close() error {
+	bw.closed = true
+	return nil
+}
+
+func TestReopenConfig(t *testing.T) {
+	broken := &brokenWriter{}
+	out := &frameRecorder{}
+	available := false
+	var events []ReopenEventKind
+	r := &Reopener{
+		Reopen: func() (io.Writer, error) {
+			if !available {
+				return nil, errors.New("No device")
+			}
+			return out, nil
+		},
+		Failures: 2,
+		Backoff:  time.Second,
+		OnEvent:  func(e ReopenEvent) { events = append(events, e.Kind) },
+	}
+	strip := NewController(broken, 2, ChunkSizeConfig(3), ReopenConfig(r))
+	clock := time.Unix(0, 0)
+	strip.now = func() time.Time { return clock }
+	strip.Fill(Red)
+
+	// The first failure is returned without reopening, the second tries to reopen.
+	for i := 0; i < 2; i++ {
+		if err := strip.Update(); err == nil {
+			t.Fatalf("Expected update %d to fail\n", i)
+		}
+	}
+	expected := []ReopenEventKind{ReopenUpdateFailed, ReopenUpdateFailed, ReopenFailed}
+	if len(events) != len(expected) || events[2] != ReopenFailed {
+		t.Errorf("Got events %v expected %v\n", events, expected)
+	}
+
+	// Further reopens wait for the backoff.
+	available = true
+	events = nil
+	if err := strip.Update(); err == nil || len(events) != 1 {
+		t.Errorf("Got %v with events %v, expected a failure without reopening\n", err, events)
+	}
+	clock = clock.Add(time.Second)
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if events[len(events)-1] != ReopenSucceeded || !broken.closed {
+		t.Errorf("Got events %v, closed %v\n", events, broken.closed)
+	}
+	// The frame is sent with the new writer, keeping the chunk size.
+	if len(out.frames) == 0 || len(out.frames[0]) != 3 {
+		t.Errorf("Got frames %v\n", out.frames)
+	}
+	if err := strip.Update(); err != nil {
+		t.Errorf("Unexpected error %v\n", err)
+	}
+
+	if _, err := NewControllerE(out, 1, ReopenConfig(&Reopener{})); err == nil {
+		t.Errorf("Expected an error without a Reopen function\n")
+	}
+}
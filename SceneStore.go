@@ -0,0 +1,198 @@
+package dotstar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrSceneNotFound is returned when loading a scene that has not been saved.
+var ErrSceneNotFound = errors.New("Scene not found")
+
+/*
+A SavedScene holds the state of a strip saved with SaveScene.
+*/
+type SavedScene struct {
+	Colours    []Colour
+	Brightness uint8
+	// Params holds application settings saved alongside the colours, such as the running effect and its settings.
+	Params map[string]string
+}
+
+/*
+A SceneStore keeps saved scenes by name.
+*/
+type SceneStore interface {
+	// SaveScene stores scene under name, replacing any existing scene.
+	SaveScene(name string, scene SavedScene) error
+	// LoadScene returns the scene saved under name, or ErrSceneNotFound.
+	LoadScene(name string) (SavedScene, error)
+}
+
+/*
+SceneStoreConfig sets the store used by SaveScene and LoadScene.  By default scenes are kept in memory.
+*/
+func SceneStoreConfig(store SceneStore) ConfigFunc {
+	return func(ctl *Controller) {
+		if store == nil {
+			ctl.invalidConfig(errors.New("Scene store must not be nil"))
+			return
+		}
+		ctl.sceneStore = store
+	}
+}
+
+/*
+SaveScene saves the current colours and global brightness under name.
+*/
+func (ctl *Controller) SaveScene(name string) error {
+	return ctl.SaveSceneParams(name, nil)
+}
+
+/*
+SaveSceneParams saves the current colours and global brightness under name, along with application params.
+
+The params are returned by LoadScene, so that for example an effect can be restarted with its settings.
+*/
+func (ctl *Controller) SaveSceneParams(name string, params map[string]string) error {
+	return ctl.sceneStore.SaveScene(name, SavedScene{Colours: ctl.Snapshot(), Brightness: ctl.brightness, Params: params})
+}
+
+/*
+LoadScene restores the colours and global brightness saved under name, returning any params saved with them.
+
+Update() must be called to show the scene.  If the scene was saved with a different number of LEDs the extra
+colours are dropped, or the remaining LEDs left unchanged.
+*/
+func (ctl *Controller) LoadScene(name string) (map[string]string, error) {
+	scene, err := ctl.sceneStore.LoadScene(name)
+	if err != nil {
+		return nil, err
+	}
+	ctl.SetGlobalBrightness(scene.Brightness)
+	ctl.SetColours(scene.Colours)
+	return scene.Params, nil
+}
+
+/*
+A MemorySceneStore keeps scenes in memory.  It is safe to use from multiple goroutines.
+*/
+type MemorySceneStore struct {
+	mu     sync.Mutex
+	scenes map[string]SavedScene
+}
+
+/*
+NewMemorySceneStore creates an empty MemorySceneStore.
+*/
+func NewMemorySceneStore() *MemorySceneStore {
+	return &MemorySceneStore{scenes: make(map[string]SavedScene)}
+}
+
+/*
+SaveScene stores a copy of scene.
+*/
+func (s *MemorySceneStore) SaveScene(name string, scene SavedScene) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scene.Colours = append([]Colour(nil), scene.Colours...)
+	s.scenes[name] = scene
+	return nil
+}
+
+/*
+LoadScene returns the scene saved under name.
+*/
+func (s *MemorySceneStore) LoadScene(name string) (SavedScene, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scene, ok := s.scenes[name]
+	if !ok {
+		return SavedScene{}, ErrSceneNotFound
+	}
+	scene.Colours = append([]Colour(nil), scene.Colours...)
+	return scene, nil
+}
+
+/*
+A FileSceneStore keeps each scene as a JSON file in a directory, so that scenes survive restarts.
+*/
+type FileSceneStore struct {
+	dir string
+}
+
+/*
+NewFileSceneStore creates a FileSceneStore that keeps scenes in dir, which must already exist.
+*/
+func NewFileSceneStore(dir string) *FileSceneStore {
+	return &FileSceneStore{dir: dir}
+}
+
+/*
+SaveScene writes scene to the file name.json.  Names may not contain path separators.
+*/
+func (s *FileSceneStore) SaveScene(name string, scene SavedScene) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	return writeScene(path, scene)
+}
+
+/*
+LoadScene reads the scene from the file name.json.
+*/
+func (s *FileSceneStore) LoadScene(name string) (SavedScene, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return SavedScene{}, err
+	}
+	return readScene(path)
+}
+
+/*
+Internal method used to get the file used for the scene name.
+*/
+func (s *FileSceneStore) path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("Invalid scene name %q", name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
+
+/*
+Internal function used to write scene to the JSON file at path.
+*/
+func writeScene(path string, scene SavedScene) error {
+	data, err := json.Marshal(scene)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so a failure does not leave a partial scene.
+	temp := path + ".tmp"
+	if err := ioutil.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+/*
+Internal function used to read a scene from the JSON file at path, returning ErrSceneNotFound if there is no file.
+*/
+func readScene(path string) (SavedScene, error) {
+	var scene SavedScene
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scene, ErrSceneNotFound
+	}
+	if err != nil {
+		return scene, err
+	}
+	err = json.Unmarshal(data, &scene)
+	return scene, err
+}
@@ -0,0 +1,54 @@
+package dotstar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func testSceneStore(t *testing.T, store SceneStore) {
+	ctl := NewController(&bytes.Buffer{}, 3, SceneStoreConfig(store))
+	ctl.SetColours([]Colour{Red, Green, Blue})
+	ctl.SetGlobalBrightness(128)
+	if err := ctl.SaveSceneParams("party", map[string]string{"effect": "fire"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctl.Clear()
+	ctl.SetGlobalBrightness(255)
+	params, err := ctl.LoadScene("party")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["effect"] != "fire" || ctl.GetGlobalBrightness() != 128 {
+		t.Errorf("Got params %v brightness %v\n", params, ctl.GetGlobalBrightness())
+	}
+	for i, want := range []Colour{Red, Green, Blue} {
+		if got := ctl.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+
+	if _, err := ctl.LoadScene("missing"); err != ErrSceneNotFound {
+		t.Errorf("Got %v expected ErrSceneNotFound\n", err)
+	}
+}
+
+func TestMemorySceneStore(t *testing.T) {
+	testSceneStore(t, NewMemorySceneStore())
+}
+
+func TestFileSceneStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := NewFileSceneStore(dir)
+	testSceneStore(t, store)
+
+	if err := store.SaveScene("../escape", SavedScene{}); err == nil {
+		t.Errorf("Expected an error for an invalid name\n")
+	}
+}
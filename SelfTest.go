@@ -0,0 +1,108 @@
+package dotstar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// selfTestBrightness is the global brightness used by SelfTest, low enough to be safe on any power supply.
+const selfTestBrightness = 32
+
+// selfTestHold is how long each step of SelfTest is shown for.
+const selfTestHold = 250 * time.Millisecond
+
+// SelfTestMaxLatency is the longest an Update() may take during SelfTest before the test fails.
+const SelfTestMaxLatency = 100 * time.Millisecond
+
+/*
+A SelfTestStep is the result of one step of SelfTest.
+*/
+type SelfTestStep struct {
+	// Name describes what the step showed, such as "red".
+	Name string
+	// Latency is how long the Update() took.
+	Latency time.Duration
+	// Err is why the step failed, or nil if it passed.
+	Err error
+}
+
+/*
+A SelfTestReport holds the results of SelfTest.
+*/
+type SelfTestReport struct {
+	Steps []SelfTestStep
+	// MaxLatency is the slowest Update() made.
+	MaxLatency time.Duration
+}
+
+/*
+String gives a line for each step, suitable for logging.
+*/
+func (r SelfTestReport) String() string {
+	lines := make([]string, len(r.Steps))
+	for i, step := range r.Steps {
+		result := "ok"
+		if step.Err != nil {
+			result = step.Err.Error()
+		}
+		lines[i] = fmt.Sprintf("%v: %v in %v", step.Name, result, step.Latency)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selfTestColour is a colour shown by SelfTest.
+type selfTestColour struct {
+	name   string
+	colour Colour
+}
+
+/*
+SelfTest checks the path to the strip at start up, so that a service can fail fast when the hardware is missing or
+misconfigured.
+
+The strip is turned off, lit red, green, blue (and white on RGBW strips) then full white, at a low global brightness
+so that it is safe to run on any power supply.  Each step is shown for a quarter of a second and fails if Update()
+returns an error or takes longer than SelfTestMaxLatency.  The steps can be checked by eye, for example to confirm
+the colour order.  The previous colours and brightness are restored afterwards, but not sent to the strip.
+
+The report is always returned, with the error from the first failing step or the context error if ctx is done.
+*/
+func (ctl *Controller) SelfTest(ctx context.Context) (SelfTestReport, error) {
+	var report SelfTestReport
+	saved, brightness := ctl.Snapshot(), ctl.brightness
+	defer func() {
+		ctl.SetGlobalBrightness(brightness)
+		ctl.SetColours(saved)
+	}()
+	ctl.SetGlobalBrightness(selfTestBrightness)
+
+	steps := []selfTestColour{{"off", Off}, {"red", Red}, {"green", Green}, {"blue", Blue}}
+	if ctl.wOffset != 0 {
+		steps = append(steps, selfTestColour{"white channel", NewColourW(0, 0, 0, 255, 255)})
+	}
+	steps = append(steps, selfTestColour{"white", White}, selfTestColour{"off", Off})
+
+	for _, s := range steps {
+		ctl.Fill(s.colour)
+		start := ctl.now()
+		err := ctl.UpdateContext(ctx)
+		step := SelfTestStep{Name: s.name, Latency: ctl.now().Sub(start), Err: err}
+		if step.Latency > report.MaxLatency {
+			report.MaxLatency = step.Latency
+		}
+		if err == nil && step.Latency > SelfTestMaxLatency {
+			step.Err = fmt.Errorf("Update took %v, longer than %v", step.Latency, SelfTestMaxLatency)
+		}
+		report.Steps = append(report.Steps, step)
+		if step.Err != nil {
+			return report, fmt.Errorf("Self test failed showing %v: %v", s.name, step.Err)
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		ctl.sleep(selfTestHold)
+	}
+	return report, nil
+}
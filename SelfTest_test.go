@@ -0,0 +1,57 @@
+package dotstar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelfTest(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 2)
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time { return clock }
+	ctl.sleep = func(d time.Duration) { clock = clock.Add(d) }
+	ctl.SetColours([]Colour{Red, Blue})
+	ctl.SetGlobalBrightness(200)
+
+	report, err := ctl.SelfTest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Steps) != 6 || len(out.frames) != 6 {
+		t.Fatalf("Got %d steps and %d frames expected 6\n", len(report.Steps), len(out.frames))
+	}
+	// Red is shown at the low test brightness.
+	if red := out.frames[1][4:8]; red[0] != 0xE0|selfTestBrightness>>3 || red[3] != 0xFF {
+		t.Errorf("Got red packet % X\n", red)
+	}
+	if !strings.Contains(report.String(), "white: ok") {
+		t.Errorf("Got report %v\n", report)
+	}
+	if ctl.GetColour(1) != Blue || ctl.GetGlobalBrightness() != 200 {
+		t.Errorf("Previous colours and brightness were not restored\n")
+	}
+}
+
+func TestSelfTestFailures(t *testing.T) {
+	ctl := NewController(&flakyWriter{failures: 10}, 2)
+	ctl.sleep = func(time.Duration) {}
+	report, err := ctl.SelfTest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "bus glitch") || len(report.Steps) != 1 {
+		t.Errorf("Got %v with %d steps\n", err, len(report.Steps))
+	}
+
+	// Slow updates fail the test.
+	ctl = NewController(&frameRecorder{}, 2)
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time {
+		clock = clock.Add(time.Second / 10)
+		return clock
+	}
+	ctl.sleep = func(time.Duration) {}
+	if report, err = ctl.SelfTest(context.Background()); err == nil || report.MaxLatency <= SelfTestMaxLatency {
+		t.Errorf("Got %v with max latency %v\n", err, report.MaxLatency)
+	}
+}
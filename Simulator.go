@@ -0,0 +1,81 @@
+package dotstar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+/*
+A SimulatorWriter decodes the Dotstar messages written by a Controller and renders the strip as a row of
+24-bit ANSI colour blocks, redrawing the same line for each message.
+
+This allows animations to be developed without the LED strip attached.  The colours are shown as they are sent,
+so gamma correction will make them appear darker than on the strip.
+*/
+type SimulatorWriter struct {
+	out io.Writer
+	// rOffset, gOffset, bOffset and wOffset hold the position of each colour in a packet.
+	rOffset, gOffset, bOffset, wOffset int
+	packetSize                         int
+	// format is the format of the messages, as given by the Controller.
+	format FrameFormat
+	line   bytes.Buffer
+}
+
+/*
+NewSimulatorWriter creates a SimulatorWriter rendering to out.
+
+order must match the order configured on the Controller (see OrderConfig).
+*/
+func NewSimulatorWriter(out io.Writer, order string) (*SimulatorWriter, error) {
+	cfg, err := OrderConfig(order)
+	if err != nil {
+		return nil, err
+	}
+	var ctl Controller
+	cfg(&ctl)
+	return &SimulatorWriter{out: out, rOffset: ctl.rOffset, gOffset: ctl.gOffset, bOffset: ctl.bOffset,
+		wOffset: ctl.wOffset, packetSize: ctl.packetSize}, nil
+}
+
+/*
+NewTerminalSimulator returns a Controller that renders to the terminal on os.Stdout instead of an LED strip.
+*/
+func NewTerminalSimulator(ledCount int, cfgs ...ConfigFunc) *Controller {
+	sim := &SimulatorWriter{out: os.Stdout}
+	ctl := NewController(sim, ledCount, cfgs...)
+	sim.rOffset, sim.gOffset, sim.bOffset, sim.wOffset = ctl.rOffset, ctl.gOffset, ctl.bOffset, ctl.wOffset
+	sim.packetSize = ctl.packetSize
+	return ctl
+}
+
+/*
+SetFrameFormat implements FrameDecoder, it is called by the Controller.
+*/
+func (sim *SimulatorWriter) SetFrameFormat(format FrameFormat) {
+	sim.format = format
+}
+
+/*
+Write renders the Dotstar message in p.  Each call must contain a whole message.
+*/
+func (sim *SimulatorWriter) Write(p []byte) (int, error) {
+	sim.line.Reset()
+	sim.line.WriteString("\r")
+	for _, clr := range decodeColours(p, sim.packetSize, sim.rOffset, sim.gOffset, sim.bOffset, sim.wOffset, sim.format) {
+		// Show the luminosity by scaling the colour as the LED would.
+		clr = clr.Add(Colour{R: clr.W, G: clr.W, B: clr.W})
+		r := uint32(clr.R) * uint32(clr.L) / 255
+		g := uint32(clr.G) * uint32(clr.L) / 255
+		b := uint32(clr.B) * uint32(clr.L) / 255
+		fmt.Fprintf(&sim.line, "\x1b[38;2;%d;%d;%dm█", r, g, b)
+	}
+	sim.line.WriteString("\x1b[0m")
+
+	if _, err := sim.out.Write(sim.line.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,23 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSimulatorWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	sim, err := NewSimulatorWriter(out, "bgr")
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	strip := NewController(sim, 2, DisableGammaCorrectionConfig())
+	strip.SetColour(0, Red)
+	strip.SetColour(1, NewColour(0, 0, 255, 0))
+	strip.Update()
+
+	expected := "\r\x1b[38;2;255;0;0m█\x1b[38;2;0;0;0m█\x1b[0m"
+	if out.String() != expected {
+		t.Errorf("Got %q expected %q\n", out.String(), expected)
+	}
+}
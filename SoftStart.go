@@ -0,0 +1,54 @@
+package dotstar
+
+import (
+	"errors"
+	"time"
+)
+
+/*
+SoftStartConfig ramps the global brightness up from 0 over duration whenever the strip is lit after being dark,
+instead of switching straight to full brightness.
+
+Lighting a long strip all at once draws a surge of current that can brown out the power supply, resetting a Pi
+powered from it.  The ramp starts on the first Update() after the Controller is created, and again on the first
+Update() that lights an LED after a frame with every LED off, for example after Clear().  It runs as a
+FadeBrightness to the brightness set, so Update() must be called regularly for the ramp to complete.
+A soft start is not begun while another fade is running.
+*/
+func SoftStartConfig(duration time.Duration) ConfigFunc {
+	return func(ctl *Controller) {
+		if duration < 0 {
+			ctl.invalidConfig(errors.New("Soft start duration must not be negative"))
+			return
+		}
+		ctl.softStart = duration
+		ctl.dark = true
+	}
+}
+
+/*
+Internal method used to begin a soft start if the strip is being lit after being dark.
+*/
+func (ctl *Controller) checkSoftStart() {
+	lit := false
+	for _, clr := range ctl.ledColours {
+		if clr.L != 0 && (clr.R != 0 || clr.G != 0 || clr.B != 0 || clr.W != 0) {
+			lit = true
+			break
+		}
+	}
+	if !lit {
+		ctl.dark = true
+		return
+	}
+	if !ctl.dark {
+		return
+	}
+	ctl.dark = false
+	if ctl.fade != nil || ctl.brightness == 0 {
+		return
+	}
+	target := ctl.brightness
+	ctl.brightness = 0
+	ctl.FadeBrightness(target, ctl.softStart)
+}
@@ -0,0 +1,61 @@
+package dotstar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoftStart(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 2, DisableGammaCorrectionConfig(), SoftStartConfig(time.Second))
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time { return clock }
+	ctl.Fill(White)
+
+	brightness := func() byte {
+		if err := ctl.Update(); err != nil {
+			t.Fatal(err)
+		}
+		frame := out.frames[len(out.frames)-1]
+		return frame[4] &^ brightnessHeader
+	}
+	if got := brightness(); got != 0 {
+		t.Errorf("Got %v expected %v\n", got, 0)
+	}
+	clock = clock.Add(time.Second / 2)
+	if got := brightness(); got != 16 {
+		t.Errorf("Got %v expected %v\n", got, 16)
+	}
+	clock = clock.Add(time.Second)
+	if got := brightness(); got != 31 {
+		t.Errorf("Got %v expected %v\n", got, 31)
+	}
+	if ctl.GetGlobalBrightness() != 255 {
+		t.Errorf("Got %v expected %v\n", ctl.GetGlobalBrightness(), 255)
+	}
+
+	// Changing colours while lit does not ramp again.
+	ctl.Fill(Red)
+	if got := brightness(); got != 31 {
+		t.Errorf("Got %v expected %v\n", got, 31)
+	}
+
+	// Lighting the strip after a dark frame ramps again, to the brightness set.
+	ctl.SetGlobalBrightness(128)
+	ctl.Clear()
+	brightness()
+	ctl.Fill(White)
+	if got := brightness(); got != 0 {
+		t.Errorf("Got %v expected %v\n", got, 0)
+	}
+	clock = clock.Add(2 * time.Second)
+	if got := brightness(); got != 16 {
+		t.Errorf("Got %v expected %v\n", got, 16)
+	}
+}
+
+func TestSoftStartConfigInvalid(t *testing.T) {
+	if _, err := NewControllerE(&frameRecorder{}, 1, SoftStartConfig(-time.Second)); err == nil {
+		t.Errorf("Expected an error for a negative duration\n")
+	}
+}
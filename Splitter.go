@@ -0,0 +1,63 @@
+package dotstar
+
+import (
+	"errors"
+	"io"
+)
+
+/*
+NewSplitter divides a strip of LedCount LEDs into one segment per output, driven in parallel.
+
+Each output, normally a separate SPI bus, drives the next segment of the strip with the LEDs shared out as evenly
+as possible, the first segments taking any that are left over.  The returned MultiController is addressed as one
+strip, but each Update() only takes as long as sending the longest segment, halving the update time of a very long
+run with two outputs or quartering it with four.  The cfgs are applied to every segment's Controller.
+
+Settings that belong to a single bus, such as PersistStateConfig and ReopenConfig, must not be shared by the
+segments and are only applied to the first segment here, see NewSplitterFunc.
+*/
+func NewSplitter(LedCount int, outputs []io.Writer, cfgs ...ConfigFunc) *MultiController {
+	return NewSplitterFunc(LedCount, outputs, func(output int) []ConfigFunc {
+		return cfgs
+	})
+}
+
+/*
+NewSplitterFunc divides a strip into segments as NewSplitter does, applying the configs returned by cfgs for each
+output to that output's Controller.
+
+This allows each segment its own PersistStateConfig file and ReopenConfig Reopener.  A state file or Reopener
+given to more than one segment is only used by the first of them, with the others recording an invalid setting.
+*/
+func NewSplitterFunc(LedCount int, outputs []io.Writer, cfgs func(output int) []ConfigFunc) *MultiController {
+	ctls := make([]*Controller, len(outputs))
+	statePaths := make(map[string]bool)
+	reopeners := make(map[*Reopener]bool)
+	for i, out := range outputs {
+		count := LedCount / len(outputs)
+		if i < LedCount%len(outputs) {
+			count++
+		}
+		ctl := NewController(out, count, cfgs(i)...)
+		// Segments sharing a file would overwrite each other's state, and a shared Reopener would be changed by
+		// every segment at once during a parallel Update().
+		if ctl.statePath != "" {
+			if statePaths[ctl.statePath] {
+				ctl.invalidConfig(errors.New("Splitter segments must not share a state file"))
+				ctl.statePath = ""
+			} else {
+				statePaths[ctl.statePath] = true
+			}
+		}
+		if ctl.reopener != nil {
+			if reopeners[ctl.reopener] {
+				ctl.invalidConfig(errors.New("Splitter segments must not share a Reopener"))
+				ctl.reopener = nil
+			} else {
+				reopeners[ctl.reopener] = true
+			}
+		}
+		ctls[i] = ctl
+	}
+	return NewMultiController(true, ctls...)
+}
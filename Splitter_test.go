@@ -0,0 +1,124 @@
+package dotstar
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// barrierWriter records when each frame was written, failing the write if the other writers have not started.
+type barrierWriter struct {
+	lock    *sync.Mutex
+	started *int
+	total   int
+	frameRecorder
+}
+
+func (w *barrierWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	*w.started++
+	w.lock.Unlock()
+	// Give the other writers a chance to start, which they can only do if they were prepared together.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		w.lock.Lock()
+		all := *w.started >= w.total
+		w.lock.Unlock()
+		if all {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return w.frameRecorder.Write(p)
+}
+
+func TestSplitter(t *testing.T) {
+	outs := []io.Writer{&frameRecorder{}, &frameRecorder{}, &frameRecorder{}}
+	split := NewSplitter(10, outs)
+	if split.Len() != 10 {
+		t.Errorf("Got length %d expected 10\n", split.Len())
+	}
+	for i, want := range []int{4, 3, 3} {
+		if got := split.ctls[i].Len(); got != want {
+			t.Errorf("Segment %d got %d LEDs expected %d\n", i, got, want)
+		}
+	}
+
+	split.SetColour(4, Red)
+	split.SetColour(9, Blue)
+	if err := split.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if split.ctls[1].GetColour(0) != Red || split.ctls[2].GetColour(2) != Blue {
+		t.Errorf("Got %v %v expected %v %v\n", split.ctls[1].GetColour(0), split.ctls[2].GetColour(2), Red, Blue)
+	}
+	for i, out := range outs {
+		if len(out.(*frameRecorder).frames) != 1 {
+			t.Errorf("Segment %d got %d frames expected 1\n", i, len(out.(*frameRecorder).frames))
+		}
+	}
+}
+
+func TestSplitterSynchronised(t *testing.T) {
+	var lock sync.Mutex
+	started := 0
+	outs := make([]io.Writer, 4)
+	for i := range outs {
+		outs[i] = &barrierWriter{lock: &lock, started: &started, total: len(outs)}
+	}
+	split := NewSplitter(8, outs)
+	if err := split.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if started != len(outs) {
+		t.Errorf("Got %d writes expected %d\n", started, len(outs))
+	}
+	if split.Skew() > 500*time.Millisecond {
+		t.Errorf("Got skew %v\n", split.Skew())
+	}
+
+	// A closed segment must not stop the others from being written.
+	split.ctls[0].closed = true
+	if err := split.Update(); err != ErrClosed {
+		t.Errorf("Got %v expected %v\n", err, ErrClosed)
+	}
+	for i := 1; i < len(outs); i++ {
+		if len(outs[i].(*barrierWriter).frames) != 2 {
+			t.Errorf("Segment %d got %d frames expected 2\n", i, len(outs[i].(*barrierWriter).frames))
+		}
+	}
+}
+
+func TestSplitterReopen(t *testing.T) {
+	// Run with -race to check that each segment's Reopener is only used by its own goroutine.
+	outs := []io.Writer{&brokenWriter{}, &brokenWriter{}, &brokenWriter{}}
+	reopened := make([]*frameRecorder, len(outs))
+	split := NewSplitterFunc(9, outs, func(output int) []ConfigFunc {
+		return []ConfigFunc{ReopenConfig(&Reopener{
+			Reopen: func() (io.Writer, error) {
+				reopened[output] = &frameRecorder{}
+				return reopened[output], nil
+			},
+			Failures: 2,
+		})}
+	})
+	for i := 0; i < 3; i++ {
+		split.Update()
+	}
+	for i, out := range reopened {
+		if out == nil || len(out.frames) == 0 {
+			t.Errorf("Segment %d was not reopened\n", i)
+		}
+	}
+
+	// A Reopener or state file shared by the segments is only used by the first.
+	r := &Reopener{Reopen: func() (io.Writer, error) { return &frameRecorder{}, nil }}
+	split = NewSplitter(4, []io.Writer{&frameRecorder{}, &frameRecorder{}}, ReopenConfig(r), PersistStateConfig("state.json"))
+	if split.ctls[0].reopener != r || split.ctls[0].statePath != "state.json" || split.ctls[0].configErr != nil {
+		t.Errorf("First segment got %v %q %v\n", split.ctls[0].reopener, split.ctls[0].statePath, split.ctls[0].configErr)
+	}
+	if split.ctls[1].reopener != nil || split.ctls[1].statePath != "" || split.ctls[1].configErr == nil {
+		t.Errorf("Second segment got %v %q %v\n", split.ctls[1].reopener, split.ctls[1].statePath, split.ctls[1].configErr)
+	}
+}
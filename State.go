@@ -0,0 +1,55 @@
+package dotstar
+
+/*
+PersistStateConfig saves the state of the strip to the file at path when the Controller is closed, so that it
+can be put back with RestoreState when the program starts again.
+
+The state is the colours, the global brightness and any params set with SetStateParams, saved in the same JSON
+form as a FileSceneStore scene.  As the state is restored on restart, Close() leaves the LEDs lit rather than
+turning them off, so restarting a service does not visibly reset the strip.  Call SaveState to also save the
+state periodically, in case the program stops without closing the Controller.
+*/
+func PersistStateConfig(path string) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.statePath = path
+	}
+}
+
+/*
+SetStateParams sets application params, such as the name of the running scene or effect, saved with the state
+and returned by RestoreState.  The map must not be changed afterwards.
+*/
+func (ctl *Controller) SetStateParams(params map[string]string) {
+	ctl.stateParams = params
+}
+
+/*
+SaveState writes the current colours, global brightness and state params to the file at path.
+
+The file is replaced in a single step, so a failure part way through leaves the previous state in place.
+*/
+func (ctl *Controller) SaveState(path string) error {
+	brightness := ctl.brightness
+	if ctl.fade != nil {
+		brightness = ctl.fade.to
+	}
+	return writeScene(path, SavedScene{Colours: ctl.Snapshot(), Brightness: brightness, Params: ctl.stateParams})
+}
+
+/*
+RestoreState restores the colours and global brightness saved in the file at path, returning the state params
+saved with them.  These are also kept, so they are saved again unless changed with SetStateParams.
+
+ErrSceneNotFound is returned if there is no saved state, in which case nothing is changed.  Update() must be
+called to show the restored state.
+*/
+func (ctl *Controller) RestoreState(path string) (map[string]string, error) {
+	state, err := readScene(path)
+	if err != nil {
+		return nil, err
+	}
+	ctl.SetGlobalBrightness(state.Brightness)
+	ctl.SetColours(state.Colours)
+	ctl.stateParams = state.Params
+	return state.Params, nil
+}
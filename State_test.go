@@ -0,0 +1,51 @@
+package dotstar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+	out := &frameRecorder{}
+	ctl := NewController(out, 3, PersistStateConfig(path))
+	ctl.SetColours([]Colour{Red, Green, Blue})
+	ctl.SetGlobalBrightness(128)
+	ctl.SetStateParams(map[string]string{"scene": "evening"})
+	ctl.Update()
+	if err := ctl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The strip is left showing the state rather than being turned off.
+	if len(out.frames) != 1 {
+		t.Errorf("Got %d frames expected 1\n", len(out.frames))
+	}
+
+	restored := NewController(out, 3)
+	params, err := restored.RestoreState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["scene"] != "evening" {
+		t.Errorf("Got %v\n", params)
+	}
+	for i, clr := range []Colour{Red, Green, Blue} {
+		if restored.GetColour(i) != clr {
+			t.Errorf("LED %d got %v expected %v\n", i, restored.GetColour(i), clr)
+		}
+	}
+	if restored.GetGlobalBrightness() != 128 {
+		t.Errorf("Got %v expected %v\n", restored.GetGlobalBrightness(), 128)
+	}
+
+	if _, err := restored.RestoreState(filepath.Join(dir, "missing.json")); err != ErrSceneNotFound {
+		t.Errorf("Got %v expected %v\n", err, ErrSceneNotFound)
+	}
+}
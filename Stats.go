@@ -0,0 +1,61 @@
+package dotstar
+
+import (
+	"time"
+)
+
+// statsWindow is the number of recent frames used to measure the frame rate.
+const statsWindow = 32
+
+/*
+Stats holds measurements of the messages sent by a Controller.
+*/
+type Stats struct {
+	// Frames is the number of messages successfully sent by Update().
+	Frames uint64
+	// Bytes is the total size of those messages.
+	Bytes uint64
+	// LastUpdate is how long the last successful Update() took, including encoding and any retries.
+	LastUpdate time.Duration
+	// FPS is the rate of recent updates in frames per second, or 0 until there have been two updates.
+	FPS float64
+}
+
+// frameStats records the updates made by a Controller.
+type frameStats struct {
+	frames, bytes uint64
+	last          time.Duration
+	// times holds when the most recent frames were sent, as a ring indexed by the frame count.
+	times [statsWindow]time.Time
+}
+
+/*
+Internal method used to record a frame of size bytes sent between start and end.
+*/
+func (s *frameStats) record(start, end time.Time, size int) {
+	s.times[s.frames%statsWindow] = end
+	s.frames++
+	s.bytes += uint64(size)
+	s.last = end.Sub(start)
+}
+
+/*
+Stats returns the number of frames and bytes sent, the time taken by the last Update() and the recent frame rate.
+
+This is useful to tune the SPI speed and to check whether effects keep up with their target frame rate.
+*/
+func (ctl *Controller) Stats() Stats {
+	s := &ctl.stats
+	result := Stats{Frames: s.frames, Bytes: s.bytes, LastUpdate: s.last}
+	count := s.frames
+	if count > statsWindow {
+		count = statsWindow
+	}
+	if count >= 2 {
+		oldest, newest := s.times[(s.frames-count)%statsWindow], s.times[(s.frames-1)%statsWindow]
+		if elapsed := newest.Sub(oldest); elapsed > 0 {
+			result.FPS = float64(count-1) / elapsed.Seconds()
+		}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package dotstar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	out := &flakyWriter{}
+	ctl := NewController(out, 10)
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time {
+		clock = clock.Add(5 * time.Millisecond)
+		return clock
+	}
+	if stats := ctl.Stats(); stats != (Stats{}) {
+		t.Errorf("Got %+v expected no stats\n", stats)
+	}
+
+	for i := 0; i < 40; i++ {
+		ctl.Update()
+	}
+	stats := ctl.Stats()
+	// Each update takes 5ms and they are 10ms apart.
+	if stats.Frames != 40 || stats.Bytes != uint64(40*len(ctl.buffer)) || stats.LastUpdate != 5*time.Millisecond || stats.FPS < 99.9 || stats.FPS > 100.1 {
+		t.Errorf("Got %+v\n", stats)
+	}
+
+	out.failures = 1
+	ctl.Update()
+	if frames := ctl.Stats().Frames; frames != 40 {
+		t.Errorf("Got %v frames expected failed update to be left out\n", frames)
+	}
+}
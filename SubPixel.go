@@ -0,0 +1,70 @@
+package dotstar
+
+import (
+	"math"
+)
+
+/*
+DrawPixelF draws colour one LED wide at a fractional position in leds, sharing it between the two LEDs it
+overlaps.
+
+Moving a pixel by fractions of an LED each frame then glides smoothly instead of jumping from LED to LED.  Each
+LED is mixed from its current colour towards colour by how much of it is covered, so the pixel can be drawn over a
+background.  Parts of the pixel beyond leds are left out.
+*/
+func DrawPixelF(leds []Colour, pos float64, colour Colour) {
+	DrawRangeF(leds, pos, pos+1, colour)
+}
+
+/*
+DrawRangeF draws colour from the fractional position start up to end in leds, anti-aliasing the LEDs that are
+only partly covered as DrawPixelF does.
+*/
+func DrawRangeF(leds []Colour, start, end float64, colour Colour) {
+	forCoverage(len(leds), start, end, func(i int, coverage float64) {
+		leds[i] = coverColour(leds[i], colour, coverage)
+	})
+}
+
+/*
+DrawPixelF draws colour one LED wide at a fractional position, see DrawPixelF.  This does not trigger Update().
+*/
+func (ctl *Controller) DrawPixelF(pos float64, colour Colour) {
+	ctl.DrawRangeF(pos, pos+1, colour)
+}
+
+/*
+DrawRangeF draws colour from the fractional position start up to end, see DrawRangeF.  This does not trigger
+Update().
+*/
+func (ctl *Controller) DrawRangeF(start, end float64, colour Colour) {
+	forCoverage(ctl.count, start, end, func(i int, coverage float64) {
+		ctl.SetColour(i, coverColour(ctl.ledColours[i], colour, coverage))
+	})
+}
+
+/*
+Internal function used to call draw with each LED from 0 up to count that is covered by start to end, along with
+how much of it is covered.
+*/
+func forCoverage(count int, start, end float64, draw func(i int, coverage float64)) {
+	first := int(math.Max(math.Floor(start), 0))
+	last := int(math.Min(math.Ceil(end), float64(count)))
+	for i := first; i < last; i++ {
+		coverage := math.Min(end, float64(i+1)) - math.Max(start, float64(i))
+		if coverage > 0 {
+			draw(i, coverage)
+		}
+	}
+}
+
+/*
+Internal function used to mix below towards colour by coverage.  LEDs that are Off are mixed from black at the
+luminosity of colour, so that partly covered LEDs are not dimmed twice.
+*/
+func coverColour(below, colour Colour, coverage float64) Colour {
+	if below.L == 0 {
+		below = Colour{L: colour.L}
+	}
+	return below.Lerp(colour, coverage)
+}
@@ -0,0 +1,40 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDrawPixelF(t *testing.T) {
+	leds := make([]Colour, 4)
+	DrawPixelF(leds, 1.25, NewColour(200, 0, 0, 255))
+	expected := []Colour{Off, NewColour(150, 0, 0, 255), NewColour(50, 0, 0, 255), Off}
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, leds[i], expected[i])
+		}
+	}
+
+	// Drawing over a background mixes with it, and whole positions fill one LED.
+	leds = []Colour{Blue, Blue, Blue, Blue}
+	DrawPixelF(leds, 2, Red)
+	DrawPixelF(leds, -0.5, Red)
+	DrawPixelF(leds, 3.5, Red)
+	expected = []Colour{Blue.Lerp(Red, 0.5), Blue, Red, Blue.Lerp(Red, 0.5)}
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, leds[i], expected[i])
+		}
+	}
+}
+
+func TestDrawRangeF(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 5)
+	strip.DrawRangeF(0.5, 3.25, NewColour(200, 0, 0, 255))
+	checkColours(t, strip, []Colour{NewColour(100, 0, 0, 255), NewColour(200, 0, 0, 255), NewColour(200, 0, 0, 255),
+		NewColour(50, 0, 0, 255), Off})
+	strip.DrawPixelF(3.5, Blue)
+	if strip.GetColour(4) != NewColour(0, 0, 128, 255) {
+		t.Errorf("Got %v\n", strip.GetColour(4))
+	}
+}
@@ -0,0 +1,96 @@
+package dotstar
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// skyFrameInterval is the time between updates of a sunrise or sunset.
+const skyFrameInterval = time.Second / 30
+
+// Colour temperatures at the start and end of a sunrise.
+const (
+	sunriseStartKelvin = 1000
+	sunriseEndKelvin   = 6500
+)
+
+/*
+SunriseColour returns the colour of a simulated sunrise at progress, from 0 (night) to 1 (daylight).
+
+The colour warms from a dim deep red through orange to bright white, with brightness rising more slowly at
+first to match how the eye sees a dawn.
+*/
+func SunriseColour(progress float64) Colour {
+	if progress <= 0 {
+		return Off
+	}
+	if progress > 1 {
+		progress = 1
+	}
+	kelvin := sunriseStartKelvin + (sunriseEndKelvin-sunriseStartKelvin)*math.Pow(progress, 1.5)
+	luminosity := 255 * progress * progress
+	// Keep the lowest brightness step lit once the sunrise has begun.
+	if luminosity < 8 {
+		luminosity = 8
+	}
+	return NewColourFromKelvin(int(kelvin), uint8(luminosity))
+}
+
+/*
+Sunrise fills the strip with a simulated dawn over duration, ending on bright daylight white.
+
+This blocks until the sunrise is complete, updating the strip 30 times a second.
+*/
+func (ctl *Controller) Sunrise(duration time.Duration) error {
+	return ctl.SunriseContext(context.Background(), duration)
+}
+
+/*
+SunriseContext runs a Sunrise, stopping early with the context error if ctx is done.
+*/
+func (ctl *Controller) SunriseContext(ctx context.Context, duration time.Duration) error {
+	return ctl.sky(ctx, duration, false)
+}
+
+/*
+Sunset runs a Sunrise backwards over duration, ending with the strip off.
+*/
+func (ctl *Controller) Sunset(duration time.Duration) error {
+	return ctl.SunsetContext(context.Background(), duration)
+}
+
+/*
+SunsetContext runs a Sunset, stopping early with the context error if ctx is done.
+*/
+func (ctl *Controller) SunsetContext(ctx context.Context, duration time.Duration) error {
+	return ctl.sky(ctx, duration, true)
+}
+
+/*
+Internal method used to run a sunrise, or a sunset if reverse is true.
+*/
+func (ctl *Controller) sky(ctx context.Context, duration time.Duration, reverse bool) error {
+	start := ctl.now()
+	for {
+		progress := 1.0
+		if duration > 0 {
+			progress = float64(ctl.now().Sub(start)) / float64(duration)
+		}
+		if progress > 1 {
+			progress = 1
+		}
+		if reverse {
+			ctl.Fill(SunriseColour(1 - progress))
+		} else {
+			ctl.Fill(SunriseColour(progress))
+		}
+		if err := ctl.UpdateContext(ctx); err != nil {
+			return err
+		}
+		if progress >= 1 {
+			return nil
+		}
+		ctl.sleep(skyFrameInterval)
+	}
+}
@@ -0,0 +1,38 @@
+package dotstar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunriseColour(t *testing.T) {
+	if c := SunriseColour(0); c != Off {
+		t.Errorf("Got %v expected Off\n", c)
+	}
+	early, late := SunriseColour(0.1), SunriseColour(1)
+	if early.R <= early.B || early.L >= late.L || late.L != 255 {
+		t.Errorf("Got early %v late %v\n", early, late)
+	}
+}
+
+func TestSunrise(t *testing.T) {
+	out := &frameRecorder{}
+	ctl := NewController(out, 2)
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time { return clock }
+	ctl.sleep = func(d time.Duration) { clock = clock.Add(d) }
+
+	if err := ctl.Sunrise(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.frames) < 31 || len(out.frames) > 32 || ctl.GetColour(1) != SunriseColour(1) {
+		t.Errorf("Got %v frames ending on %v\n", len(out.frames), ctl.GetColour(1))
+	}
+
+	if err := ctl.Sunset(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ctl.GetColour(0) != Off {
+		t.Errorf("Got %v expected Off after sunset\n", ctl.GetColour(0))
+	}
+}
@@ -0,0 +1,102 @@
+package dotstar
+
+import (
+	"errors"
+)
+
+/*
+A PatternType is a test pattern shown by TestPattern.
+*/
+type PatternType int
+
+const (
+	// PatternRGB repeats red, green and blue along the strip.  If the first LED is not red the order is wrong.
+	PatternRGB PatternType = iota
+	// PatternChannelSweep ramps red up over the first third of the strip, green over the second and blue over
+	// the last, showing that each channel works across its whole range.
+	PatternChannelSweep
+	// PatternIndex splits the strip into groups of 8 LEDs, each starting with a dim white marker followed by the
+	// group number in binary on the other 7 LEDs, most significant bit first, green for 1 and red for 0.  The
+	// position of an LED is 8 times its group number plus its distance from the marker, which is unambiguous for
+	// strips of up to 1024 LEDs.
+	PatternIndex
+	// PatternWhiteBalance splits the strip into four bars of white at full, 3/4, 1/2 and 1/4 level, to check
+	// that white stays neutral as it dims.
+	PatternWhiteBalance
+	// PatternGradient fades white from off at the first LED to full at the last, showing the strip length.
+	PatternGradient
+)
+
+// patternNames holds the names of the test patterns, for ParsePatternType.
+var patternNames = map[string]PatternType{
+	"rgb":      PatternRGB,
+	"sweep":    PatternChannelSweep,
+	"index":    PatternIndex,
+	"balance":  PatternWhiteBalance,
+	"gradient": PatternGradient,
+}
+
+// indexMarker is the colour marking the start of each group of LEDs in PatternIndex.
+var indexMarker = Colour{R: 64, G: 64, B: 64, L: 255}
+
+/*
+ParsePatternType returns the PatternType for a name: rgb, sweep, index, balance or gradient.
+*/
+func ParsePatternType(name string) (PatternType, error) {
+	if p, ok := patternNames[name]; ok {
+		return p, nil
+	}
+	return 0, errors.New("Unknown test pattern " + name)
+}
+
+/*
+TestPattern shows a pattern for checking the wiring, colour order and length of the strip, then calls Update().
+
+Gamma correction is applied as usual, so the ramps appear to brighten slowly.
+*/
+func (ctl *Controller) TestPattern(p PatternType) error {
+	n := ctl.count
+	for i := 0; i < n; i++ {
+		var clr Colour
+		switch p {
+		case PatternRGB:
+			clr = []Colour{Red, Green, Blue}[i%3]
+		case PatternChannelSweep:
+			third := (n + 2) / 3
+			level := uint8((i%third + 1) * 255 / third)
+			clr = Colour{L: 255}
+			switch i / third {
+			case 0:
+				clr.R = level
+			case 1:
+				clr.G = level
+			default:
+				clr.B = level
+			}
+		case PatternIndex:
+			// No bit is shown as white, so the marker cannot be mistaken for one.
+			bit := 7 - i%8
+			switch {
+			case bit == 7:
+				clr = indexMarker
+			case (i/8)>>uint(bit)&1 == 1:
+				clr = Green
+			default:
+				clr = Red
+			}
+		case PatternWhiteBalance:
+			level := uint8(255 - (i*4/n)*64)
+			clr = Colour{R: level, G: level, B: level, L: 255}
+		case PatternGradient:
+			level := uint8(255)
+			if n > 1 {
+				level = uint8(i * 255 / (n - 1))
+			}
+			clr = Colour{R: level, G: level, B: level, L: 255}
+		default:
+			return errors.New("Unknown test pattern")
+		}
+		ctl.SetColour(i, clr)
+	}
+	return ctl.Update()
+}
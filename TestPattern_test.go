@@ -0,0 +1,57 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestTestPattern(t *testing.T) {
+	tests := []struct {
+		pattern  PatternType
+		expected []Colour
+	}{
+		{PatternRGB, []Colour{Red, Green, Blue, Red, Green, Blue, Red, Green, Blue}},
+		{PatternChannelSweep, []Colour{NewColour(85, 0, 0, 255), NewColour(170, 0, 0, 255), Red,
+			NewColour(0, 85, 0, 255), NewColour(0, 170, 0, 255), Green,
+			NewColour(0, 0, 85, 255), NewColour(0, 0, 170, 255), Blue}},
+		{PatternIndex, []Colour{indexMarker, Red, Red, Red, Red, Red, Red, Red, indexMarker, Red, Red, Red, Red,
+			Red, Red, Green}},
+		{PatternWhiteBalance, []Colour{White, White, White, NewColour(191, 191, 191, 255), NewColour(191, 191, 191, 255),
+			NewColour(127, 127, 127, 255), NewColour(127, 127, 127, 255), NewColour(63, 63, 63, 255),
+			NewColour(63, 63, 63, 255)}},
+		{PatternGradient, []Colour{NewColour(0, 0, 0, 255), NewColour(31, 31, 31, 255), NewColour(63, 63, 63, 255),
+			NewColour(95, 95, 95, 255), NewColour(127, 127, 127, 255), NewColour(159, 159, 159, 255),
+			NewColour(191, 191, 191, 255), NewColour(223, 223, 223, 255), White}},
+	}
+	for _, test := range tests {
+		out := &frameRecorder{}
+		ctl := NewController(out, len(test.expected))
+		if err := ctl.TestPattern(test.pattern); err != nil || len(out.frames) != 1 {
+			t.Fatalf("Pattern %v got %v after %d frames\n", test.pattern, err, len(out.frames))
+		}
+		for i, clr := range ctl.Snapshot() {
+			if clr != test.expected[i] {
+				t.Errorf("Pattern %v LED %d got %v expected %v\n", test.pattern, i, clr, test.expected[i])
+			}
+		}
+	}
+
+	// Group 5 is 0000101 in binary.
+	ctl := NewController(&frameRecorder{}, 48)
+	ctl.TestPattern(PatternIndex)
+	for i, want := range []Colour{indexMarker, Red, Red, Red, Red, Green, Red, Green} {
+		if got := ctl.GetColour(40 + i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", 40+i, got, want)
+		}
+	}
+
+	ctl = NewController(&frameRecorder{}, 3)
+	if err := ctl.TestPattern(PatternType(99)); err == nil {
+		t.Errorf("Expected an error for an unknown pattern\n")
+	}
+	if p, err := ParsePatternType("index"); err != nil || p != PatternIndex {
+		t.Errorf("Got %v %v\n", p, err)
+	}
+	if _, err := ParsePatternType("stripes"); err == nil {
+		t.Errorf("Expected an error for an unknown name\n")
+	}
+}
@@ -0,0 +1,99 @@
+package dotstar
+
+import (
+	"time"
+)
+
+// thermalInterval is the time between temperature readings.
+const thermalInterval = time.Second
+
+// thermalHysteresis is how far in degrees the temperature must fall below the limit before brightness is restored.
+const thermalHysteresis = 5
+
+// thermalStep and thermalRecovery are how much the brightness is reduced or restored after each reading.
+const (
+	thermalStep     = 0.1
+	thermalRecovery = 0.05
+)
+
+// maxDerate is the largest share of the brightness removed, so the strip is never darkened completely.
+const maxDerate = 0.75
+
+// thermalLimit holds the state of a ThermalLimiter.
+type thermalLimit struct {
+	readTemp func() (float64, error)
+	maxC     float64
+	// derate is the share of brightness currently removed, lastRead when the temperature was last read.
+	derate   float32
+	lastRead time.Time
+	// temperature is the last reading, err the error from the last attempt to read it.
+	temperature float64
+	err         error
+}
+
+/*
+ThermalLimiter returns a ConfigFunc that protects an enclosed strip from overheating by reducing the global
+brightness while the temperature returned by readTemp is above maxC degrees Celsius.
+
+The temperature is read at most once a second during Update().  Each reading over maxC takes away a further 10%
+of the brightness, down to a quarter of the brightness set.  Once the temperature is 5 degrees below maxC the
+brightness is restored 5% at a time.  Failed readings leave the brightness unchanged and can be seen with
+ThermalState.  GetGlobalBrightness still returns the brightness set, without the reduction.
+*/
+func ThermalLimiter(readTemp func() (float64, error), maxC float64) ConfigFunc {
+	return func(ctl *Controller) {
+		ctl.thermal = &thermalLimit{readTemp: readTemp, maxC: maxC}
+	}
+}
+
+/*
+ThermalState returns the last temperature read by the ThermalLimiter, the share of brightness kept (1 when the
+strip is not being limited), and the error from the last reading.  Without a ThermalLimiter it returns 0, 1 and nil.
+*/
+func (ctl *Controller) ThermalState() (temperature float64, brightness float64, err error) {
+	if ctl.thermal == nil {
+		return 0, 1, nil
+	}
+	return ctl.thermal.temperature, float64(1 - ctl.thermal.derate), ctl.thermal.err
+}
+
+/*
+Internal method used to read the temperature if it is due and change the brightness reduction to match.
+*/
+func (ctl *Controller) checkTemperature() {
+	limit := ctl.thermal
+	now := ctl.now()
+	if !limit.lastRead.IsZero() && now.Sub(limit.lastRead) < thermalInterval {
+		return
+	}
+	limit.lastRead = now
+	temperature, err := limit.readTemp()
+	limit.err = err
+	if err != nil {
+		return
+	}
+	limit.temperature = temperature
+
+	derate := limit.derate
+	switch {
+	case temperature > limit.maxC:
+		derate += thermalStep
+		if derate > maxDerate {
+			derate = maxDerate
+		}
+	case temperature < limit.maxC-thermalHysteresis:
+		derate -= thermalRecovery
+		// Allow for rounding so that full brightness is restored exactly.
+		if derate < thermalRecovery/2 {
+			derate = 0
+		}
+	}
+	if derate == limit.derate {
+		return
+	}
+	limit.derate = derate
+	ctl.tables = nil
+	for i, clr := range ctl.ledColours {
+		ctl.encode(i, clr)
+	}
+}
@@ -0,0 +1,76 @@
+package dotstar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestThermalLimiter(t *testing.T) {
+	out := &frameRecorder{}
+	temperature := 40.0
+	var readErr error
+	ctl := NewController(out, 1, DisableGammaCorrectionConfig(), ThermalLimiter(func() (float64, error) {
+		return temperature, readErr
+	}, 50))
+	clock := time.Unix(0, 0)
+	ctl.now = func() time.Time { return clock }
+	ctl.SetColour(0, White)
+
+	brightness := func() byte {
+		ctl.Update()
+		frame := out.frames[len(out.frames)-1]
+		return frame[4] &^ brightnessHeader
+	}
+	if got := brightness(); got != 31 {
+		t.Errorf("Got %v expected %v\n", got, 31)
+	}
+
+	// Readings over the limit derate one step a second, down to a quarter.
+	temperature = 60
+	clock = clock.Add(time.Second)
+	if got := brightness(); got != 28 {
+		t.Errorf("Got %v expected %v\n", got, 28)
+	}
+	clock = clock.Add(time.Second / 2)
+	if got := brightness(); got != 28 {
+		t.Errorf("Reading too soon got %v expected %v\n", got, 28)
+	}
+	for i := 0; i < 20; i++ {
+		clock = clock.Add(time.Second)
+		brightness()
+	}
+	if _, kept, _ := ctl.ThermalState(); kept < 0.249 || kept > 0.251 {
+		t.Errorf("Got %v expected %v\n", kept, 0.25)
+	}
+	if ctl.GetGlobalBrightness() != 255 {
+		t.Errorf("Got %v expected %v\n", ctl.GetGlobalBrightness(), 255)
+	}
+
+	// Within the hysteresis nothing changes, and errors leave the limit alone.
+	temperature = 48
+	clock = clock.Add(time.Second)
+	limited := brightness()
+	readErr = errors.New("Sensor failed")
+	temperature = 20
+	clock = clock.Add(time.Second)
+	if got := brightness(); got != limited {
+		t.Errorf("Got %v expected %v\n", got, limited)
+	}
+	if _, _, err := ctl.ThermalState(); err != readErr {
+		t.Errorf("Got %v expected %v\n", err, readErr)
+	}
+
+	// Cooling restores full brightness.
+	readErr = nil
+	for i := 0; i < 20; i++ {
+		clock = clock.Add(time.Second)
+		brightness()
+	}
+	if got := brightness(); got != 31 {
+		t.Errorf("Got %v expected %v\n", got, 31)
+	}
+	if temp, kept, err := ctl.ThermalState(); temp != 20 || kept != 1 || err != nil {
+		t.Errorf("Got %v %v %v\n", temp, kept, err)
+	}
+}
@@ -0,0 +1,62 @@
+package dotstar
+
+import (
+	"math"
+	"time"
+)
+
+// spiClocksPerByte is the number of SPI clock periods taken to send each byte.  Many SPI controllers, including
+// the Raspberry Pi's, leave an idle clock period between bytes.
+const spiClocksPerByte = 9
+
+// spiFrameOverhead is allowed for each message to set up the transfer and return from the system call.
+const spiFrameOverhead = 100 * time.Microsecond
+
+// spiHeadroom is the margin RecommendedSPISpeed leaves over the speed needed to reach the frame rate.
+const spiHeadroom = 1.25
+
+// spiSpeedStep is the granularity of the speeds recommended by RecommendedSPISpeed.
+const spiSpeedStep = 100000
+
+/*
+MaxFPS returns the fastest rate in frames per second at which an APA102 strip of ledCount LEDs can be updated over
+an SPI bus clocked at spiHz.
+
+The estimate includes the start and end frames, an idle clock period between bytes as many SPI controllers leave,
+and a fixed overhead for each transfer.  The rate actually reached also depends on the time taken to draw each
+frame.
+*/
+func MaxFPS(ledCount int, spiHz int) float64 {
+	if spiHz <= 0 {
+		return 0
+	}
+	return 1 / frameTime(headerSize+ledCount*ledPacketSize+endFrameSize(ledCount), spiHz).Seconds()
+}
+
+/*
+RecommendedSPISpeed returns an SPI clock speed in Hz that can update the strip targetFPS times a second, using the
+message size of the Controller's current settings.
+
+The speed includes 25% headroom and is rounded up to the next 100kHz.  Lower speeds are more reliable over long
+wires, so there is no benefit in choosing a much faster speed than this.  0 is returned if the target cannot be
+reached at any speed, as the per-transfer overhead alone takes too long.
+*/
+func (ctl *Controller) RecommendedSPISpeed(targetFPS int) int {
+	if targetFPS <= 0 {
+		return 0
+	}
+	available := time.Second/time.Duration(targetFPS) - spiFrameOverhead
+	if available <= 0 {
+		return 0
+	}
+	clocks := float64(len(ctl.buffer) * spiClocksPerByte)
+	hz := clocks / available.Seconds() * spiHeadroom
+	return int(math.Ceil(hz/spiSpeedStep)) * spiSpeedStep
+}
+
+/*
+Internal function used to estimate the time taken to send a message of size bytes at spiHz.
+*/
+func frameTime(size int, spiHz int) time.Duration {
+	return time.Duration(float64(size*spiClocksPerByte)/float64(spiHz)*float64(time.Second)) + spiFrameOverhead
+}
@@ -0,0 +1,29 @@
+package dotstar
+
+import (
+	"testing"
+)
+
+func TestMaxFPS(t *testing.T) {
+	// 144 LEDs need 4 + 576 + 11 bytes, 5319 clocks, which take 5.319ms at 1MHz plus the overhead.
+	if fps := MaxFPS(144, 1000000); fps < 184 || fps > 185 {
+		t.Errorf("Got %v expected around %v\n", fps, 184.5)
+	}
+	if MaxFPS(144, 8000000) <= MaxFPS(144, 1000000) || MaxFPS(300, 1000000) >= MaxFPS(144, 1000000) {
+		t.Errorf("Expected faster speeds and shorter strips to give higher frame rates\n")
+	}
+	if MaxFPS(10, 0) != 0 {
+		t.Errorf("Got %v expected %v\n", MaxFPS(10, 0), 0)
+	}
+}
+
+func TestRecommendedSPISpeed(t *testing.T) {
+	strip := NewController(&frameRecorder{}, 144)
+	speed := strip.RecommendedSPISpeed(60)
+	if speed%spiSpeedStep != 0 || MaxFPS(144, speed) < 60 || MaxFPS(144, speed) > 120 {
+		t.Errorf("Got %v giving %v FPS\n", speed, MaxFPS(144, speed))
+	}
+	if strip.RecommendedSPISpeed(20000) != 0 || strip.RecommendedSPISpeed(0) != 0 {
+		t.Errorf("Expected unreachable frame rates to give 0\n")
+	}
+}
@@ -0,0 +1,131 @@
+package dotstar
+
+import (
+	"io"
+)
+
+// WS2812SpiSpeed is the SPI clock rate in Hz needed to produce WS2812 timings from 3 SPI bits per data bit.
+const WS2812SpiSpeed = 2400000
+
+// ws2812ResetSize is the number of zero bytes sent after the data to latch it (300µs at 2.4MHz).
+const ws2812ResetSize = 90
+
+// ws2812Table holds the 3 SPI bytes used to send each possible data byte.
+// A 1 bit is sent as 110 and a 0 bit as 100.
+var ws2812Table [256][3]byte
+
+func init() {
+	for value := 0; value < 256; value++ {
+		var pattern uint32
+		for bit := uint(0); bit < 8; bit++ {
+			pattern <<= 3
+			if value&(0x80>>bit) != 0 {
+				pattern |= 6
+			} else {
+				pattern |= 4
+			}
+		}
+		ws2812Table[value] = [3]byte{byte(pattern >> 16), byte(pattern >> 8), byte(pattern)}
+	}
+}
+
+/*
+A WS2812Writer converts the Dotstar messages written by a Controller into the SPI bit patterns needed to drive a
+WS2812 (NeoPixel) strip from the SPI MOSI line.
+
+The SPI bus must be clocked at WS2812SpiSpeed.  Each call to Write must contain a whole message, as sent by Update().
+WS2812 LEDs have no brightness field, so the Dotstar brightness is applied by scaling the RGB values.
+RGBW strips such as the SK6812 are supported by a four colour order, for example grbw.
+*/
+type WS2812Writer struct {
+	spi    io.Writer
+	buffer []byte
+	// packetSize is the size of each Dotstar packet, as given by the Controller.  It is ledPacketSize unless the
+	// Controller uses an RGBW order.
+	packetSize int
+	// format is the format of the messages, as given by the Controller.
+	format FrameFormat
+}
+
+/*
+NewWS2812Writer returns a WS2812Writer that sends the encoded bit patterns to SpiOut.
+
+The Controller it is passed to sets the packet size through FrameDecoder, so RGBW orders work here as they do with
+NewWS2812Controller.
+*/
+func NewWS2812Writer(SpiOut io.Writer) *WS2812Writer {
+	return &WS2812Writer{spi: SpiOut, packetSize: ledPacketSize}
+}
+
+/*
+NewWS2812Controller creates a Controller that drives a WS2812 strip attached to SpiOut.
+
+SpiOut must be clocked at WS2812SpiSpeed.  The colour order defaults to grb, which is used by most WS2812 strips,
+and can be overridden with OrderConfig, including RGBW orders such as grbw.
+*/
+func NewWS2812Controller(SpiOut io.Writer, LedCount int, cfgs ...ConfigFunc) *Controller {
+	grbOrder, _ := OrderConfig("grb")
+	return NewController(NewWS2812Writer(SpiOut), LedCount, append([]ConfigFunc{grbOrder}, cfgs...)...)
+}
+
+/*
+Write encodes the Dotstar message in p and sends it to the SPI bus.
+
+The returned count is the number of bytes of p consumed, which is len(p) on success.
+*/
+func (w *WS2812Writer) Write(p []byte) (int, error) {
+	packets := decodePackets(p, w.packetSize, w.format)
+	size := len(packets)*(w.packetSize-1)*3 + ws2812ResetSize
+	if cap(w.buffer) < size {
+		w.buffer = make([]byte, size, size)
+	}
+	w.buffer = w.buffer[:size]
+
+	offset := 0
+	for _, packet := range packets {
+		brightness := uint32(packet[0] &^ brightnessHeader)
+		for _, value := range packet[1:] {
+			if brightness != 31 {
+				value = uint8(uint32(value) * brightness / 31)
+			}
+			copy(w.buffer[offset:], ws2812Table[value][:])
+			offset += 3
+		}
+	}
+	for i := offset; i < size; i++ {
+		w.buffer[i] = 0
+	}
+
+	if _, err := w.spi.Write(w.buffer); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/*
+SetFrameFormat implements FrameDecoder, it is called by the Controller.
+*/
+func (w *WS2812Writer) SetFrameFormat(format FrameFormat) {
+	w.format = format
+	if format.PacketSize > 0 {
+		w.packetSize = format.PacketSize
+	}
+}
+
+/*
+WriteFrame encodes and sends a whole Dotstar message.
+*/
+func (w *WS2812Writer) WriteFrame(frame []byte) error {
+	_, err := w.Write(frame)
+	return err
+}
+
+/*
+Close closes the SPI writer if it implements io.Closer.
+*/
+func (w *WS2812Writer) Close() error {
+	if closer, ok := w.spi.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWS2812Encoding(t *testing.T) {
+	out := &bytes.Buffer{}
+	strip := NewWS2812Controller(out, 2, DisableGammaCorrectionConfig())
+	strip.SetColour(0, NewColour(0xFF, 0x00, 0x80, 255))
+	strip.SetColour(1, NewColour(0xFF, 0xFF, 0xFF, 0))
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+
+	sent := out.Bytes()
+	if len(sent) != 2*9+ws2812ResetSize {
+		t.Fatalf("Got %d bytes expected %d\n", len(sent), 2*9+ws2812ResetSize)
+	}
+	// Green first, then red, then blue.
+	expected := []byte{0x92, 0x49, 0x24, 0xDB, 0x6D, 0xB6, 0xD2, 0x49, 0x24}
+	if !bytes.Equal(sent[:9], expected) {
+		t.Errorf("Got % X expected % X\n", sent[:9], expected)
+	}
+	// Zero brightness is sent as off.
+	off := bytes.Repeat(expected[:3], 3)
+	if !bytes.Equal(sent[9:18], off) {
+		t.Errorf("Got % X expected % X\n", sent[9:18], off)
+	}
+}
+
+func TestWS2812RGBW(t *testing.T) {
+	out := &bytes.Buffer{}
+	grbw, _ := OrderConfig("grbw")
+	// The standalone writer must be told the packet size by the Controller.
+	for _, strip := range []*Controller{
+		NewWS2812Controller(out, 1, grbw, DisableGammaCorrectionConfig()),
+		NewController(NewWS2812Writer(out), 1, grbw, DisableGammaCorrectionConfig()),
+	} {
+		out.Reset()
+		strip.SetColour(0, NewColourW(0xFF, 0x00, 0x80, 0xFF, 255))
+		if err := strip.Update(); err != nil {
+			t.Fatalf("Unexpected error %v\n", err)
+		}
+
+		sent := out.Bytes()
+		if len(sent) != 12+ws2812ResetSize {
+			t.Fatalf("Got %d bytes expected %d\n", len(sent), 12+ws2812ResetSize)
+		}
+		expected := []byte{0x92, 0x49, 0x24, 0xDB, 0x6D, 0xB6, 0xD2, 0x49, 0x24, 0xDB, 0x6D, 0xB6}
+		if !bytes.Equal(sent[:12], expected) {
+			t.Errorf("Got % X expected % X\n", sent[:12], expected)
+		}
+	}
+}
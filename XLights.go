@@ -0,0 +1,266 @@
+package dotstar
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// xlightsChannelsPerNode is the number of channels xLights uses for each RGB node.
+const xlightsChannelsPerNode = 3
+
+/*
+An XModel is a custom model from xLights, which places each node (LED) of a prop on a grid.
+
+Models are read from the .xmodel files exported by xLights, or from the models in an xLights layout
+(xlights_rgbeffects.xml), so that displays designed in xLights or for Falcon controllers can be driven from Go.
+*/
+type XModel struct {
+	Name string
+	// Width, Height and Depth are the size of the model's grid.
+	Width, Height, Depth int
+	// Nodes holds the grid position of each node, with Nodes[0] being node 1.  X is the column, Y the row from the
+	// top and Z the layer.  Nodes missing from the model are at (-1, -1, -1).
+	Nodes []Point
+	// StartChannel is the first channel of the model, counting from 1, or 0 if not known.
+	StartChannel int
+}
+
+/*
+ReadXModels reads every custom model from an xLights .xmodel file or layout file.
+
+Models using other display types, or a start channel relative to another model, are left out.
+*/
+func ReadXModels(r io.Reader) ([]XModel, error) {
+	var models []XModel
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return models, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		name := strings.ToLower(element.Name.Local)
+		if name != "custommodel" && name != "model" {
+			continue
+		}
+		attrs := make(map[string]string)
+		for _, attr := range element.Attr {
+			attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+		}
+		if _, custom := attrs["custommodel"]; !custom {
+			if _, compressed := attrs["custommodelcompressed"]; !compressed {
+				continue
+			}
+		}
+		model, err := parseXModel(attrs)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+}
+
+/*
+Internal function used to build an XModel from the attributes of a model element.
+*/
+func parseXModel(attrs map[string]string) (XModel, error) {
+	model := XModel{Name: attrs["name"], Depth: 1}
+	atoi := func(name string, value *int) error {
+		if attrs[name] == "" {
+			return nil
+		}
+		v, err := strconv.Atoi(attrs[name])
+		if err != nil || v < 1 {
+			return fmt.Errorf("Model %q has an invalid %s of %q", model.Name, name, attrs[name])
+		}
+		*value = v
+		return nil
+	}
+	for name, value := range map[string]*int{"parm1": &model.Width, "parm2": &model.Height, "depth": &model.Depth} {
+		if err := atoi(name, value); err != nil {
+			return model, err
+		}
+	}
+	if start := attrs["startchannel"]; start != "" {
+		// Starts relative to other models or controllers, such as ">Tree:1", cannot be resolved here.
+		if v, err := strconv.Atoi(start); err == nil {
+			model.StartChannel = v
+		}
+	}
+
+	// Node numbers past the number of cells are rejected, so a bad file cannot allocate a huge list of nodes.
+	cells := model.Width * model.Height * model.Depth
+	place := func(node, x, y, z int) error {
+		if node > cells {
+			return fmt.Errorf("Model %q has an invalid node %d for %d cells", model.Name, node, cells)
+		}
+		if node < 1 {
+			return nil
+		}
+		for len(model.Nodes) < node {
+			model.Nodes = append(model.Nodes, Point{-1, -1, -1})
+		}
+		// Nodes spread over several cells are placed at the first.
+		if model.Nodes[node-1].X < 0 {
+			model.Nodes[node-1] = Point{float64(x), float64(y), float64(z)}
+		}
+		return nil
+	}
+
+	if compressed, ok := attrs["custommodelcompressed"]; ok && attrs["custommodel"] == "" {
+		// Each entry is node,row,column with an optional layer.
+		for _, entry := range strings.Split(compressed, ";") {
+			if entry == "" {
+				continue
+			}
+			fields := strings.Split(entry, ",")
+			values := make([]int, 4)
+			if len(fields) < 3 || len(fields) > 4 {
+				return model, fmt.Errorf("Model %q has an invalid node entry %q", model.Name, entry)
+			}
+			for i, field := range fields {
+				v, err := strconv.Atoi(strings.TrimSpace(field))
+				if err != nil {
+					return model, fmt.Errorf("Model %q has an invalid node entry %q", model.Name, entry)
+				}
+				values[i] = v
+			}
+			if err := place(values[0], values[2], values[1], values[3]); err != nil {
+				return model, err
+			}
+		}
+		return model, nil
+	}
+
+	// Layers are separated by |, rows by ; and columns by , with empty cells having no node.
+	for z, layer := range strings.Split(attrs["custommodel"], "|") {
+		for y, row := range strings.Split(layer, ";") {
+			for x, cell := range strings.Split(row, ",") {
+				cell = strings.TrimSpace(cell)
+				if cell == "" {
+					continue
+				}
+				node, err := strconv.Atoi(cell)
+				if err != nil {
+					return model, fmt.Errorf("Model %q has an invalid node %q", model.Name, cell)
+				}
+				if err := place(node, x, y, z); err != nil {
+					return model, err
+				}
+			}
+		}
+	}
+	return model, nil
+}
+
+/*
+Points returns the grid position of each node, for use with NewLayout.
+*/
+func (m XModel) Points() []Point {
+	return append([]Point(nil), m.Nodes...)
+}
+
+/*
+Segment returns the position of the model's first LED and its number of LEDs, for a strip that starts at channel 1
+with three channels per LED.  This is where the model's LEDs are on a controller driving the whole display, for
+example to run an effect on the model with an effects.Segment.
+*/
+func (m XModel) Segment() (start, count int) {
+	if m.StartChannel > 0 {
+		start = (m.StartChannel - 1) / xlightsChannelsPerNode
+	}
+	return start, len(m.Nodes)
+}
+
+/*
+XModel exports the layout as an xLights custom model called name, so that it can be imported into xLights.
+
+Each point is rounded to the nearest grid cell, after moving the layout so that its smallest coordinates are at
+0.  An error is returned if two LEDs fall in the same cell, in which case the points should be scaled up first.
+*/
+func (l *Layout) XModel(name string) (XModel, error) {
+	model := XModel{Name: name, Depth: 1, Nodes: make([]Point, len(l.points))}
+	min, _ := l.Bounds()
+	cells := make(map[Point]int)
+	for i, p := range l.points {
+		cell := Point{math.Floor(p.X - min.X + 0.5), math.Floor(p.Y - min.Y + 0.5), math.Floor(p.Z - min.Z + 0.5)}
+		if other, ok := cells[cell]; ok {
+			return model, fmt.Errorf("LEDs %d and %d are both in cell %v", other, i, cell)
+		}
+		cells[cell] = i
+		model.Nodes[i] = cell
+		model.Width = maxInt(model.Width, int(cell.X)+1)
+		model.Height = maxInt(model.Height, int(cell.Y)+1)
+		model.Depth = maxInt(model.Depth, int(cell.Z)+1)
+	}
+	return model, nil
+}
+
+/*
+WriteXModel writes m as an xLights .xmodel file.
+*/
+func WriteXModel(w io.Writer, m XModel) error {
+	grid := make([][][]string, m.Depth)
+	for z := range grid {
+		grid[z] = make([][]string, m.Height)
+		for y := range grid[z] {
+			grid[z][y] = make([]string, m.Width)
+		}
+	}
+	for i, p := range m.Nodes {
+		x, y, z := int(p.X), int(p.Y), int(p.Z)
+		if x < 0 || y < 0 || z < 0 || x >= m.Width || y >= m.Height || z >= m.Depth {
+			continue
+		}
+		grid[z][y][x] = strconv.Itoa(i + 1)
+	}
+	layers := make([]string, m.Depth)
+	for z, layer := range grid {
+		rows := make([]string, len(layer))
+		for y, row := range layer {
+			rows[y] = strings.Join(row, ",")
+		}
+		layers[z] = strings.Join(rows, ";")
+	}
+
+	element := xml.StartElement{Name: xml.Name{Local: "custommodel"}, Attr: []xml.Attr{
+		{Name: xml.Name{Local: "name"}, Value: m.Name},
+		{Name: xml.Name{Local: "parm1"}, Value: strconv.Itoa(m.Width)},
+		{Name: xml.Name{Local: "parm2"}, Value: strconv.Itoa(m.Height)},
+		{Name: xml.Name{Local: "Depth"}, Value: strconv.Itoa(m.Depth)},
+		{Name: xml.Name{Local: "StringType"}, Value: "RGB Nodes"},
+		{Name: xml.Name{Local: "CustomModel"}, Value: strings.Join(layers, "|")},
+	}}
+	if m.StartChannel > 0 {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "StartChannel"}, Value: strconv.Itoa(m.StartChannel)})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if err := encoder.EncodeToken(element); err != nil {
+		return err
+	}
+	if err := encoder.EncodeToken(element.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package dotstar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadXModels(t *testing.T) {
+	layout := `<?xml version="1.0" encoding="UTF-8"?>
+<xrgb>
+  <models>
+    <model DisplayAs="Custom" name="Star" parm1="3" parm2="2" StartChannel="31" CustomModel="1,,2;,3,"/>
+    <model DisplayAs="Tree 360" name="Tree" parm1="16" parm2="50" StartChannel="1"/>
+    <model DisplayAs="Custom" name="Cube" parm1="2" parm2="1" Depth="2" StartChannel=">Star:1" CustomModelCompressed="1,0,0;2,0,1;3,0,0,1"/>
+  </models>
+</xrgb>`
+	models, err := ReadXModels(strings.NewReader(layout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("Got %d models expected 2\n", len(models))
+	}
+
+	star := models[0]
+	if star.Name != "Star" || star.Width != 3 || star.Height != 2 || star.Depth != 1 || star.StartChannel != 31 {
+		t.Errorf("Got %+v\n", star)
+	}
+	expected := []Point{{0, 0, 0}, {2, 0, 0}, {1, 1, 0}}
+	for i, p := range expected {
+		if star.Nodes[i] != p {
+			t.Errorf("Node %d got %v expected %v\n", i+1, star.Nodes[i], p)
+		}
+	}
+	if start, count := star.Segment(); start != 10 || count != 3 {
+		t.Errorf("Got segment %d %d expected 10 3\n", start, count)
+	}
+
+	cube := models[1]
+	if cube.StartChannel != 0 || len(cube.Nodes) != 3 || cube.Nodes[2] != (Point{0, 0, 1}) {
+		t.Errorf("Got %+v\n", cube)
+	}
+
+	if _, err := ReadXModels(strings.NewReader(`<custommodel name="Bad" CustomModel="1,x"/>`)); err == nil {
+		t.Errorf("Expected an error for an invalid node\n")
+	}
+	for _, bad := range []string{
+		`<custommodel name="Wide" parm1="-1" parm2="2" CustomModel="1"/>`,
+		`<custommodel name="Deep" parm1="1" parm2="1" Depth="-1" CustomModel="1"/>`,
+		`<custommodel name="Huge" parm1="2" parm2="2" CustomModel="1,1000000000"/>`,
+		`<custommodel name="HugeCompressed" parm1="2" parm2="2" CustomModelCompressed="5,0,0"/>`,
+	} {
+		if models, err := ReadXModels(strings.NewReader(bad)); err == nil {
+			t.Errorf("Got %+v expected an error for %s\n", models, bad)
+		}
+	}
+}
+
+func TestXModelRoundTrip(t *testing.T) {
+	strip := NewController(&bytes.Buffer{}, 4)
+	layout := NewLayout(strip, []Point{{1, 1, 0}, {2.1, 1, 0}, {3, 2.9, 0}, {1, 3, 0}})
+	model, err := layout.XModel("Frame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := WriteXModel(&out, model); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `CustomModel="1,2,;,,;4,,3"`) {
+		t.Errorf("Got %s\n", out.String())
+	}
+
+	models, err := ReadXModels(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Name != "Frame" || models[0].Width != 3 || models[0].Height != 3 {
+		t.Fatalf("Got %+v\n", models)
+	}
+	expected := []Point{{0, 0, 0}, {1, 0, 0}, {2, 2, 0}, {0, 2, 0}}
+	for i, p := range models[0].Points() {
+		if p != expected[i] {
+			t.Errorf("Node %d got %v expected %v\n", i+1, p, expected[i])
+		}
+	}
+
+	crowded := NewLayout(strip, []Point{{0, 0, 0}, {0.2, 0, 0}})
+	if _, err := crowded.XModel("Crowded"); err == nil {
+		t.Errorf("Expected an error for LEDs in the same cell\n")
+	}
+}
@@ -0,0 +1,252 @@
+/*
+The audio package analyses sound so that effects can react to music.
+
+An Analyser is fed PCM samples, either directly or from an io.Reader, and tracks the level, the energy in a set
+of frequency bands and beats.  Audio can be captured on a Raspberry Pi with ALSA's arecord and piped in:
+
+	arecord -f S16_LE -r 44100 -c 1 | ./myprogram
+
+	analyser := audio.NewAnalyser(44100, 16)
+	go analyser.ReadFrom(os.Stdin)
+	animator.Run(ctx, &audio.Spectrum{Analyser: analyser, Palette: dotstar.RainbowPalette})
+
+The VUMeter, Spectrum and BeatFlash types are effects that can be run with effects.Animator.
+*/
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/cmplx"
+	"sync"
+)
+
+// windowSize is the number of samples analysed at a time, it must be a power of 2.
+const windowSize = 1024
+
+// historySize is the number of windows averaged for beat detection, around 1 second at 44.1kHz.
+const historySize = 43
+
+// beatThreshold is how much louder than average a window must be to count as a beat.
+const beatThreshold = 1.4
+
+// minBeatEnergy stops beats being detected in near silence.
+const minBeatEnergy = 1e-4
+
+// dynamicRange is the range, in decibels, of band levels mapped onto 0 to 1.
+const dynamicRange = 60
+
+// lowestFrequency is the bottom of the lowest band in Hz.
+const lowestFrequency = 40
+
+/*
+An Analyser measures the level, frequency bands and beats of an audio stream.
+
+Samples are analysed in blocks of 1024.  Methods are safe to call from multiple goroutines, so samples can be
+written from a capture goroutine while effects read the results.
+*/
+type Analyser struct {
+	mu         sync.Mutex
+	sampleRate int
+	// edges holds the FFT bin where each band starts, with a final entry for the end of the last band.
+	edges   []int
+	window  [windowSize]float64
+	filled  int
+	level   float64
+	bands   []float64
+	history [historySize]float64
+	windows int
+	beat    bool
+}
+
+/*
+NewAnalyser creates an Analyser for mono audio at sampleRate samples per second, splitting the spectrum into
+the given number of logarithmically spaced bands.
+*/
+func NewAnalyser(sampleRate, bands int) *Analyser {
+	if bands < 1 {
+		bands = 1
+	}
+	a := &Analyser{sampleRate: sampleRate, bands: make([]float64, bands), edges: make([]int, bands+1)}
+
+	// Spread the bands evenly on a log scale from the lowest frequency to half the sample rate.
+	binHz := float64(sampleRate) / windowSize
+	low, high := math.Log(lowestFrequency), math.Log(float64(sampleRate)/2)
+	for i := range a.edges {
+		bin := int(math.Exp(low+(high-low)*float64(i)/float64(bands)) / binHz)
+		if bin < 1 {
+			bin = 1
+		}
+		if i > 0 && bin <= a.edges[i-1] {
+			bin = a.edges[i-1] + 1
+		}
+		if bin > windowSize/2 {
+			bin = windowSize / 2
+		}
+		a.edges[i] = bin
+	}
+	return a
+}
+
+/*
+Write adds mono samples in the range -1 to 1.
+*/
+func (a *Analyser) Write(samples []float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, sample := range samples {
+		a.window[a.filled] = sample
+		a.filled++
+		if a.filled == windowSize {
+			a.analyse()
+			a.filled = 0
+		}
+	}
+}
+
+/*
+WriteInt16 adds signed 16-bit mono samples.
+*/
+func (a *Analyser) WriteInt16(samples []int16) {
+	converted := make([]float64, len(samples))
+	for i, sample := range samples {
+		converted[i] = float64(sample) / 32768
+	}
+	a.Write(converted)
+}
+
+/*
+ReadFrom reads signed 16-bit little endian mono samples from r until it returns an error.
+
+The number of bytes read is returned, with a nil error if r reached io.EOF.
+*/
+func (a *Analyser) ReadFrom(r io.Reader) (int64, error) {
+	reader := bufio.NewReader(r)
+	samples := make([]int16, windowSize/4)
+	var total int64
+	for {
+		err := binary.Read(reader, binary.LittleEndian, samples)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return total, nil
+			}
+			return total, err
+		}
+		total += int64(len(samples) * 2)
+		a.WriteInt16(samples)
+	}
+}
+
+/*
+Level returns the RMS level of the last block of samples, from 0 (silence) to 1.
+*/
+func (a *Analyser) Level() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.level
+}
+
+/*
+Bands returns the level of each frequency band from lowest to highest, each from 0 to 1.
+
+The levels are on a decibel scale covering 60dB below full scale.
+*/
+func (a *Analyser) Bands() []float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]float64(nil), a.bands...)
+}
+
+/*
+Beat returns true if a beat has been detected since the last call.
+*/
+func (a *Analyser) Beat() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	beat := a.beat
+	a.beat = false
+	return beat
+}
+
+/*
+Internal method used to analyse a full window of samples.
+*/
+func (a *Analyser) analyse() {
+	var energy float64
+	for _, sample := range a.window {
+		energy += sample * sample
+	}
+	energy /= windowSize
+	a.level = math.Sqrt(energy)
+
+	// A beat is a window much louder than the recent average.
+	var average float64
+	for _, e := range a.history {
+		average += e
+	}
+	if a.windows >= historySize {
+		average /= historySize
+		if energy > minBeatEnergy && energy > beatThreshold*average {
+			a.beat = true
+		}
+	}
+	a.history[a.windows%historySize] = energy
+	a.windows++
+
+	spectrum := fft(a.window[:])
+	for band := range a.bands {
+		var magnitude float64
+		start, end := a.edges[band], a.edges[band+1]
+		for bin := start; bin < end; bin++ {
+			magnitude += cmplx.Abs(spectrum[bin])
+		}
+		if end > start {
+			magnitude /= float64(end - start)
+		}
+		// A full scale sine wave gives a magnitude of half the window size.
+		magnitude /= windowSize / 2
+		level := 0.0
+		if magnitude > 0 {
+			level = (20*math.Log10(magnitude) + dynamicRange) / dynamicRange
+		}
+		a.bands[band] = math.Max(0, math.Min(1, level))
+	}
+}
+
+/*
+Internal function used to compute the FFT of samples, the length of which must be a power of 2.
+*/
+func fft(samples []float64) []complex128 {
+	n := len(samples)
+	result := make([]complex128, n)
+	// Bit reversed ordering for the iterative algorithm.
+	bits := uint(0)
+	for 1<<bits < n {
+		bits++
+	}
+	for i, sample := range samples {
+		reversed := 0
+		for b := uint(0); b < bits; b++ {
+			if i&(1<<b) != 0 {
+				reversed |= 1 << (bits - 1 - b)
+			}
+		}
+		result[reversed] = complex(sample, 0)
+	}
+
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := result[start+k], w*result[start+k+size/2]
+				result[start+k] = even + odd
+				result[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/owlfish/dotstar"
+	"math"
+	"testing"
+	"time"
+)
+
+// sine returns count samples of a sine wave at frequency Hz.
+func sine(frequency float64, amplitude float64, count int) []float64 {
+	samples := make([]float64, count)
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*frequency*float64(i)/44100)
+	}
+	return samples
+}
+
+func TestLevelAndBands(t *testing.T) {
+	a := NewAnalyser(44100, 8)
+	a.Write(sine(1000, 0.5, windowSize))
+	// RMS of a sine wave is amplitude/sqrt(2).
+	if level := a.Level(); math.Abs(level-0.5/math.Sqrt2) > 0.01 {
+		t.Errorf("Got level %v\n", level)
+	}
+
+	bands := a.Bands()
+	loudest := 0
+	for i, level := range bands {
+		if level > bands[loudest] {
+			loudest = i
+		}
+	}
+	if a.edges[loudest] > 1000*windowSize/44100 || a.edges[loudest+1] < 1000*windowSize/44100 {
+		t.Errorf("Got loudest band %d (bins %d-%d) for 1kHz, bands %v\n", loudest, a.edges[loudest], a.edges[loudest+1], bands)
+	}
+}
+
+func TestBeat(t *testing.T) {
+	a := NewAnalyser(44100, 4)
+	a.Write(sine(200, 0.05, windowSize*historySize))
+	if a.Beat() {
+		t.Errorf("Got a beat from a steady tone\n")
+	}
+	a.Write(sine(200, 0.8, windowSize))
+	if !a.Beat() || a.Beat() {
+		t.Errorf("Expected a single beat\n")
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	samples := make([]int16, windowSize)
+	for i := range samples {
+		samples[i] = 16384
+	}
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, samples)
+	a := NewAnalyser(44100, 4)
+	if n, err := a.ReadFrom(buf); err != nil || n != windowSize*2 {
+		t.Fatalf("Got %d, %v\n", n, err)
+	}
+	if level := a.Level(); math.Abs(level-0.5) > 1e-9 {
+		t.Errorf("Got level %v expected 0.5\n", level)
+	}
+}
+
+func TestVUMeter(t *testing.T) {
+	a := NewAnalyser(44100, 4)
+	samples := make([]float64, windowSize)
+	for i := range samples {
+		samples[i] = 0.5
+	}
+	a.Write(samples)
+	leds := make([]dotstar.Colour, 10)
+	(&VUMeter{Analyser: a, Palette: dotstar.Palette{dotstar.Green}}).Render(leds, time.Second)
+	if leds[4] != dotstar.Green || leds[5] != dotstar.Off {
+		t.Errorf("Got %v\n", leds)
+	}
+}
+
+func TestSpectrum(t *testing.T) {
+	a := NewAnalyser(44100, 4)
+	for i := range a.bands {
+		a.bands[i] = 1
+	}
+	leds := make([]dotstar.Colour, 4)
+	(&Spectrum{Analyser: a, Palette: dotstar.Palette{dotstar.Red, dotstar.Blue}}).Render(leds, time.Second)
+	if leds[0] != dotstar.Red || leds[3] != dotstar.Blue {
+		t.Errorf("Got %v expected the palette from Red to Blue\n", leds)
+	}
+
+	// A single band uses the start of the palette.
+	a = NewAnalyser(44100, 1)
+	a.bands[0] = 1
+	(&Spectrum{Analyser: a, Palette: dotstar.Palette{dotstar.Red, dotstar.Blue}}).Render(leds[:1], time.Second)
+	if leds[0] != dotstar.Red {
+		t.Errorf("Got %v expected %v\n", leds[0], dotstar.Red)
+	}
+}
@@ -0,0 +1,100 @@
+package audio
+
+import (
+	"github.com/owlfish/dotstar"
+	"time"
+)
+
+/*
+VUMeter lights the strip from the start in proportion to the audio level, like a VU meter.
+
+LEDs are coloured by their position along the Palette.
+*/
+type VUMeter struct {
+	Analyser *Analyser
+	Palette  dotstar.Palette
+	// Gain scales the level before display, quiet sources may need a gain of 2 to 4.  0 is treated as 1.
+	Gain float64
+}
+
+/*
+Render draws the meter for the current level.
+*/
+func (v *VUMeter) Render(leds []dotstar.Colour, t time.Duration) {
+	gain := v.Gain
+	if gain == 0 {
+		gain = 1
+	}
+	lit := int(v.Analyser.Level() * gain * float64(len(leds)))
+	for i := range leds {
+		if i < lit {
+			leds[i] = v.Palette.At(position(i, len(leds)))
+		} else {
+			leds[i] = dotstar.Off
+		}
+	}
+}
+
+/*
+Spectrum spreads the frequency bands across the strip, lowest first, with the brightness of each LED showing
+the level of its band.  Each band is coloured by its position along the Palette.
+*/
+type Spectrum struct {
+	Analyser *Analyser
+	Palette  dotstar.Palette
+}
+
+/*
+Render draws the current spectrum.
+*/
+func (s *Spectrum) Render(leds []dotstar.Colour, t time.Duration) {
+	bands := s.Analyser.Bands()
+	for i := range leds {
+		band := i * len(bands) / len(leds)
+		clr := s.Palette.At(position(band, len(bands)))
+		leds[i] = dotstar.Off.Blend(clr, float32(bands[band]))
+		leds[i].L = clr.L
+	}
+}
+
+/*
+Internal function used to find the palette position of item i of count, from 0 for the first to 1 for the last.
+*/
+func position(i, count int) float64 {
+	if count <= 1 {
+		return 0
+	}
+	return float64(i) / float64(count-1)
+}
+
+/*
+BeatFlash fills the strip with Colour on each beat, fading away between beats.
+*/
+type BeatFlash struct {
+	Analyser *Analyser
+	Colour   dotstar.Colour
+	// Decay is how many 256ths the flash fades each frame, 0 uses 32.
+	Decay uint8
+}
+
+/*
+Render flashes on a beat and fades the previous frame otherwise.
+*/
+func (b *BeatFlash) Render(leds []dotstar.Colour, t time.Duration) {
+	if b.Analyser.Beat() {
+		for i := range leds {
+			leds[i] = b.Colour
+		}
+		return
+	}
+	decay := b.Decay
+	if decay == 0 {
+		decay = 32
+	}
+	ratio := float32(decay) / 256
+	for i := range leds {
+		faded := leds[i].Blend(dotstar.Off, ratio)
+		faded.L = leds[i].L
+		leds[i] = faded
+	}
+}
@@ -0,0 +1,285 @@
+/*
+dotstar controls a strip from the command line, for checking wiring and for quick scripts.
+
+	dotstar [flags] colour <colour>   fill the strip with a colour name, #RRGGBB or rgb(r,g,b)
+	dotstar [flags] off               switch every LED off
+	dotstar [flags] effect <name>     run a built-in effect: rainbow, fire, lava, ocean or clouds
+	dotstar [flags] test              cycle through red, green, blue and white to check the colour order
+	dotstar [flags] pattern <name>    show a test pattern: rgb, sweep, index, balance or gradient
+	dotstar [flags] stream            show frames of RGB bytes, 3 per LED, read from stdin
+
+Effects and the test run until interrupted, or for -duration if it is set.  Flags include -spi, -speed, -leds,
+-order and -brightness, run dotstar -h for the full list.  Use -sim to show the strip in the terminal instead.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"github.com/owlfish/dotstar/periph"
+	"io"
+	"os"
+	"os/signal"
+	"periph.io/x/conn/v3/physic"
+	"time"
+)
+
+// options holds the command line flags.
+type options struct {
+	spi        string
+	speed      physic.Frequency
+	leds       int
+	order      string
+	brightness uint
+	duration   time.Duration
+	fps        int
+	sim        bool
+}
+
+// command is a parsed command line command.
+type command struct {
+	name string
+	// colour, pattern and effect hold the argument of the colour, pattern and effect commands.
+	colour  dotstar.Colour
+	pattern dotstar.PatternType
+	effect  func() effects.Effect
+}
+
+// errUsage is returned when the command line is not understood.
+var errUsage = errors.New("Unknown command, run dotstar -h for usage")
+
+func main() {
+	opts, args, err := parseFlags(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		// A second interrupt stops the process if shutting down hangs.
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	if err := run(ctx, opts, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if err == errUsage {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+/*
+Internal function used to parse the command line flags in args, returning the options and remaining arguments.
+
+Errors and usage are written to output.
+*/
+func parseFlags(args []string, output io.Writer) (options, []string, error) {
+	opts := options{speed: 8 * physic.MegaHertz}
+	flags := flag.NewFlagSet("dotstar", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&opts.spi, "spi", "", "SPI port to use, the first available port by default")
+	flags.Var(&opts.speed, "speed", "SPI clock speed")
+	flags.IntVar(&opts.leds, "leds", 30, "Number of LEDs on the strip")
+	flags.StringVar(&opts.order, "order", "bgr", "Colour order of the strip, such as rgb, grb or grbw")
+	flags.UintVar(&opts.brightness, "brightness", 255, "Global brightness from 0 to 255")
+	flags.DurationVar(&opts.duration, "duration", 0, "How long to run effects and the test, 0 runs until interrupted")
+	flags.IntVar(&opts.fps, "fps", 60, "Frame rate for effects")
+	flags.BoolVar(&opts.sim, "sim", false, "Show the strip in the terminal instead of using SPI")
+	flags.Usage = func() {
+		fmt.Fprintf(output, "Usage: dotstar [flags] colour <colour> | off | effect <name> | test | pattern <name> | stream\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	if opts.brightness > 255 {
+		err := errors.New("Brightness must be from 0 to 255")
+		fmt.Fprintln(output, err)
+		return opts, nil, err
+	}
+	return opts, flags.Args(), nil
+}
+
+/*
+Internal function used to parse the command and its argument.
+*/
+func parseCommand(args []string) (command, error) {
+	var cmd command
+	var err error
+	switch {
+	case len(args) == 2 && args[0] == "colour":
+		cmd.colour, err = dotstar.ParseColour(args[1])
+	case len(args) == 2 && args[0] == "pattern":
+		cmd.pattern, err = dotstar.ParsePatternType(args[1])
+	case len(args) == 2 && args[0] == "effect":
+		var ok bool
+		if cmd.effect, ok = builtinEffects[args[1]]; !ok {
+			err = fmt.Errorf("Unknown effect %v", args[1])
+		}
+	case len(args) == 1 && (args[0] == "off" || args[0] == "test" || args[0] == "stream"):
+	default:
+		return cmd, errUsage
+	}
+	cmd.name = args[0]
+	return cmd, err
+}
+
+/*
+Internal function used to open the strip and run the command in args.
+*/
+func run(ctx context.Context, opts options, args []string) error {
+	cmd, err := parseCommand(args)
+	if err != nil {
+		return err
+	}
+	order, err := dotstar.OrderConfig(opts.order)
+	if err != nil {
+		return err
+	}
+	var strip *dotstar.Controller
+	if opts.sim {
+		strip = dotstar.NewTerminalSimulator(opts.leds, order)
+	} else if strip, err = periph.NewPeriphController(opts.spi, opts.speed, opts.leds, order); err != nil {
+		return err
+	}
+	return execute(ctx, strip, opts, cmd, os.Stdin)
+}
+
+/*
+Internal function used to run cmd on strip, reading any stream from in.
+*/
+func execute(ctx context.Context, strip *dotstar.Controller, opts options, cmd command, in io.Reader) error {
+	strip.SetGlobalBrightness(uint8(opts.brightness))
+
+	// The strip keeps showing the last frame sent, so colour, off and pattern leave it open.
+	switch cmd.name {
+	case "pattern":
+		return strip.TestPattern(cmd.pattern)
+	case "colour":
+		strip.Fill(cmd.colour)
+		return strip.Update()
+	case "off":
+		strip.Fill(dotstar.Off)
+		return strip.Update()
+	}
+
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+	var err error
+	switch cmd.name {
+	case "effect":
+		err = effects.NewAnimator(strip, opts.fps).Run(ctx, cmd.effect())
+	case "test":
+		err = colourTest(ctx, strip)
+	default:
+		err = stream(ctx, strip, in)
+	}
+	// Being interrupted, reaching the duration or the end of the stream is not an error.
+	if err == context.Canceled || err == context.DeadlineExceeded || err == io.EOF {
+		err = nil
+	}
+	// Close switches the strip off.
+	if closeErr := strip.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// builtinEffects creates the effects that can be run by name.
+var builtinEffects = map[string]func() effects.Effect{
+	"rainbow": func() effects.Effect {
+		return effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+			shift := t.Seconds() / 5
+			for i := range leds {
+				position := float64(i)/float64(len(leds)) + shift
+				leds[i] = dotstar.RainbowPalette.At(position - float64(int(position)))
+			}
+		})
+	},
+	"fire": func() effects.Effect { return effects.NewFireEffect() },
+	"lava": func() effects.Effect {
+		return &effects.NoiseEffect{Palette: dotstar.LavaPalette, Scale: 0.05, Speed: 0.3}
+	},
+	"ocean": func() effects.Effect {
+		return &effects.NoiseEffect{Palette: dotstar.OceanPalette, Scale: 0.05, Speed: 0.2}
+	},
+	"clouds": func() effects.Effect {
+		return &effects.NoiseEffect{Palette: dotstar.CloudPalette, Scale: 0.03, Speed: 0.1}
+	},
+}
+
+/*
+Internal function used to show red, green, blue and white in turn, each for a second, until ctx is done.
+*/
+func colourTest(ctx context.Context, strip *dotstar.Controller) error {
+	for {
+		for _, clr := range []dotstar.Colour{dotstar.Red, dotstar.Green, dotstar.Blue, dotstar.White} {
+			strip.Fill(clr)
+			if err := strip.UpdateContext(ctx); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+/*
+Internal function used to show frames of RGB bytes read from r until it ends or ctx is done.
+
+Frames are read on a separate goroutine so that an interrupt is seen while waiting for input.
+*/
+func stream(ctx context.Context, strip *dotstar.Controller, r io.Reader) error {
+	frames := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			frame := make([]byte, strip.Len()*3)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case frames <- frame:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	clrs := make([]dotstar.Colour, strip.Len())
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case frame := <-frames:
+			for i := range clrs {
+				clrs[i] = dotstar.NewColour(frame[i*3], frame[i*3+1], frame[i*3+2], 255)
+			}
+			strip.SetColours(clrs)
+			if err := strip.UpdateContext(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
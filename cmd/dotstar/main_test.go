@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/dotstartest"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	opts, args, err := parseFlags([]string{"-leds", "144", "-order", "grb", "-brightness", "64", "-duration", "2s", "colour", "red"}, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if opts.leds != 144 || opts.order != "grb" || opts.brightness != 64 || opts.duration != 2*time.Second || opts.fps != 60 {
+		t.Errorf("Got %+v\n", opts)
+	}
+	if len(args) != 2 || args[0] != "colour" || args[1] != "red" {
+		t.Errorf("Got arguments %v expected [colour red]\n", args)
+	}
+
+	for _, bad := range [][]string{{"-brightness", "256"}, {"-leds", "many"}, {"-unknown"}} {
+		if _, _, err := parseFlags(bad, ioutil.Discard); err == nil {
+			t.Errorf("Expected an error for %v\n", bad)
+		}
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	cmd, err := parseCommand([]string{"colour", "#00FF00"})
+	if err != nil || cmd.name != "colour" || cmd.colour != dotstar.Green {
+		t.Errorf("Got %+v, %v expected green\n", cmd, err)
+	}
+	cmd, err = parseCommand([]string{"pattern", "index"})
+	if err != nil || cmd.pattern != dotstar.PatternIndex {
+		t.Errorf("Got %+v, %v expected the index pattern\n", cmd, err)
+	}
+	if cmd, err = parseCommand([]string{"effect", "fire"}); err != nil || cmd.effect == nil {
+		t.Errorf("Got %+v, %v expected the fire effect\n", cmd, err)
+	}
+	if cmd, err = parseCommand([]string{"stream"}); err != nil || cmd.name != "stream" {
+		t.Errorf("Got %+v, %v expected stream\n", cmd, err)
+	}
+
+	for _, bad := range [][]string{{}, {"colour"}, {"off", "now"}, {"dance"}} {
+		if _, err := parseCommand(bad); err != errUsage {
+			t.Errorf("Got %v expected %v for %v\n", err, errUsage, bad)
+		}
+	}
+	for _, bad := range [][]string{{"colour", "blurple"}, {"pattern", "plaid"}, {"effect", "disco"}} {
+		if _, err := parseCommand(bad); err == nil || err == errUsage {
+			t.Errorf("Got %v expected an error for the argument of %v\n", err, bad)
+		}
+	}
+}
+
+func TestExecuteColour(t *testing.T) {
+	strip, out := dotstartest.NewController(3)
+	cmd, _ := parseCommand([]string{"colour", "blue"})
+	if err := execute(context.Background(), strip, options{brightness: 128}, cmd, nil); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	// The global brightness scales the luminosity sent.
+	if got := out.Last()[2]; got.B != 255 || got.L >= 255 || strip.GetGlobalBrightness() != 128 {
+		t.Errorf("Got %v at brightness %d expected dimmed blue\n", got, strip.GetGlobalBrightness())
+	}
+}
+
+func TestExecuteStream(t *testing.T) {
+	strip, out := dotstartest.NewController(2)
+	cmd, _ := parseCommand([]string{"stream"})
+	in := bytes.NewReader([]byte{255, 0, 0, 0, 0, 255, 0, 255, 0, 0, 255, 0})
+	if err := execute(context.Background(), strip, options{brightness: 255}, cmd, in); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	// Two frames are streamed, then Close switches the strip off.
+	frames := out.Frames()
+	if len(frames) != 3 {
+		t.Fatalf("Got %d frames expected 3\n", len(frames))
+	}
+	if frames[0][0] != dotstar.Red || frames[0][1] != dotstar.Blue || frames[1][1] != dotstar.Green {
+		t.Errorf("Got %v expected red and blue then green\n", frames[:2])
+	}
+	dotstartest.AssertLED(t, frames[2], 0, dotstar.Off)
+}
+
+func TestStreamInterrupted(t *testing.T) {
+	strip, _ := dotstartest.NewController(2)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// A pipe with no input must not stop the stream from seeing the interrupt.
+	r, w := io.Pipe()
+	defer w.Close()
+	done := make(chan error, 1)
+	go func() { done <- stream(ctx, strip, r) }()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Got %v expected %v\n", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Stream did not stop when the context was done\n")
+	}
+}
+
+func TestColourTest(t *testing.T) {
+	strip, out := dotstartest.NewController(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := colourTest(ctx, strip); err != context.DeadlineExceeded {
+		t.Errorf("Got %v expected %v\n", err, context.DeadlineExceeded)
+	}
+	dotstartest.AssertLED(t, out.Last(), 0, dotstar.Red)
+}
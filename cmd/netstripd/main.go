@@ -0,0 +1,44 @@
+/*
+netstripd receives Dotstar messages from netstrip clients and sends them to a strip on a local SPI port.
+
+	netstripd -leds 144 -spi /dev/spidev0.0 -speed 8MHz
+
+Both TCP and UDP clients are served on the listening address.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/owlfish/dotstar/netstrip"
+	"github.com/owlfish/dotstar/periph"
+	"log"
+	"os"
+	"periph.io/x/conn/v3/physic"
+)
+
+func main() {
+	listen := flag.String("listen", fmt.Sprintf(":%d", netstrip.DefaultPort), "TCP and UDP address to listen on")
+	port := flag.String("spi", "", "SPI port to use, the first available port by default")
+	leds := flag.Int("leds", 0, "Number of LEDs on the strip, 0 accepts clients with any number")
+	speed := 8 * physic.MegaHertz
+	flag.Var(&speed, "speed", "SPI clock speed")
+	flag.Parse()
+
+	driver, err := periph.NewDriver(*port, speed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := netstrip.NewServer(driver, *leds)
+	errs := make(chan error, 2)
+	go func() { errs <- server.ListenAndServe(*listen) }()
+	go func() { errs <- server.ListenAndServeUDP(*listen) }()
+	log.Printf("Listening on %v", *listen)
+	log.Print(<-errs)
+	// log.Fatal would exit without closing the SPI port.
+	if err := driver.Close(); err != nil {
+		log.Print(err)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,237 @@
+/*
+The dmx package connects Dotstar strips to DMX lighting control networks.
+
+A Receiver listens for Art-Net or sACN (E1.31) packets and maps the channels of each DMX universe onto
+LEDs of a Controller, three channels (red, green and blue) per LED.  This allows lighting software such as
+xLights, Resolume or QLC+ to drive the strip:
+
+	strip := dotstar.NewController(spiBus, 170)
+	receiver := dmx.NewReceiver(strip, dmx.Mapping{Universe: 1, Channel: 1, Start: 0, Count: 170})
+	receiver.ListenAndServe(fmt.Sprintf(":%d", dmx.ArtNetPort))
+
+Receivers can also read DMX512 straight from a lighting console through a USB-RS485 adapter with ServeSerial.
+
+A Sender does the reverse, sending the messages written by a Controller to a remote sACN pixel controller.
+*/
+package dmx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"net"
+	"sync"
+)
+
+// ArtNetPort is the UDP port used by Art-Net.
+const ArtNetPort = 6454
+
+// SACNPort is the UDP port used by sACN (E1.31).
+const SACNPort = 5568
+
+// channelsPerLed is the number of DMX channels used for each LED.
+const channelsPerLed = 3
+
+// universeSize is the number of channels in a DMX universe.
+const universeSize = 512
+
+// artNetID starts every Art-Net packet.
+var artNetID = []byte("Art-Net\x00")
+
+// artNetOpDmx is the op code of an ArtDmx packet.
+const artNetOpDmx = 0x5000
+
+// artNetHeaderSize is the offset of the DMX data in an ArtDmx packet.
+const artNetHeaderSize = 18
+
+// sacnID is the ACN packet identifier found at offset 4 of an E1.31 packet.
+var sacnID = []byte("ASC-E1.17\x00\x00\x00")
+
+// sacnHeaderSize is the offset of the DMX start code in an E1.31 data packet.
+const sacnHeaderSize = 125
+
+// ErrUnknownPacket is returned by HandlePacket for packets that are not Art-Net or sACN DMX data.
+var ErrUnknownPacket = errors.New("Packet is not Art-Net or sACN DMX data")
+
+/*
+A Mapping assigns the channels of a DMX universe to a range of LEDs.
+*/
+type Mapping struct {
+	// Universe is the DMX universe.  For Art-Net this is the 15-bit port address.
+	Universe uint16
+	// Channel is the first channel (1-512) holding the red value of the first LED.
+	Channel int
+	// Start is the position of the first LED.
+	Start int
+	// Count is the number of LEDs.
+	Count int
+}
+
+/*
+SACNMulticastAddr returns the multicast group address that sACN uses to send the given universe.
+*/
+func SACNMulticastAddr(universe uint16) *net.UDPAddr {
+	return &net.UDPAddr{IP: net.IPv4(239, 255, byte(universe>>8), byte(universe)), Port: SACNPort}
+}
+
+/*
+A Receiver applies DMX data to a Controller.
+
+An Update() is made after every packet that changes LEDs.  Receiver methods are safe to call from multiple
+goroutines, but the Controller must not be used elsewhere while the Receiver is running.
+*/
+type Receiver struct {
+	mu       sync.Mutex
+	ctl      *dotstar.Controller
+	mappings []Mapping
+	// onUpdateError is called with Update() errors while serving, if set.
+	onUpdateError func(err error)
+}
+
+/*
+NewReceiver creates a Receiver that maps universes onto ctl using the given mappings.
+*/
+func NewReceiver(ctl *dotstar.Controller, mappings ...Mapping) *Receiver {
+	return &Receiver{ctl: ctl, mappings: mappings}
+}
+
+/*
+OnUpdateError sets a function to be called with each Update() error while serving, replacing any set before.
+
+Serve and ServeSerial keep running after an Update() fails, so that a transient error does not stop the node.
+*/
+func (r *Receiver) OnUpdateError(handler func(err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onUpdateError = handler
+}
+
+/*
+ListenAndServe listens for UDP packets on addr and applies them to the Controller.
+*/
+func (r *Receiver) ListenAndServe(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return r.Serve(conn)
+}
+
+/*
+Serve reads packets from conn until it returns an error.  Packets that are not DMX data are ignored, and
+Update() errors are given to the OnUpdateError function.
+*/
+func (r *Receiver) Serve(conn net.PacketConn) error {
+	packet := make([]byte, 1024)
+	for {
+		n, _, err := conn.ReadFrom(packet)
+		if err != nil {
+			return err
+		}
+		if err := r.HandlePacket(packet[:n]); err != nil && err != ErrUnknownPacket {
+			r.updateFailed(err)
+		}
+	}
+}
+
+/*
+HandlePacket decodes an Art-Net or sACN packet and applies the DMX data to the Controller.
+
+Any error from Update() is returned.
+*/
+func (r *Receiver) HandlePacket(packet []byte) error {
+	universe, data, err := decodePacket(packet)
+	if err != nil {
+		return err
+	}
+	return r.Apply(universe, data)
+}
+
+/*
+Apply sets the LEDs mapped to universe from the DMX channel data and calls Update().
+
+data[0] is the value of channel 1.
+*/
+func (r *Receiver) Apply(universe uint16, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := false
+	for _, m := range r.mappings {
+		if m.Universe != universe {
+			continue
+		}
+		for i := 0; i < m.Count; i++ {
+			channel := m.Channel - 1 + i*channelsPerLed
+			if channel < 0 || channel+channelsPerLed > len(data) {
+				break
+			}
+			r.ctl.SetColour(m.Start+i, dotstar.NewColour(data[channel], data[channel+1], data[channel+2], 255))
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return r.ctl.Update()
+}
+
+/*
+Internal method used to report an Update() error made while serving.
+*/
+func (r *Receiver) updateFailed(err error) {
+	r.mu.Lock()
+	handler := r.onUpdateError
+	r.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+/*
+Internal function used to find the universe and DMX channel data in an Art-Net or sACN packet.
+*/
+func decodePacket(packet []byte) (uint16, []byte, error) {
+	switch {
+	case len(packet) >= artNetHeaderSize && bytes.Equal(packet[:len(artNetID)], artNetID):
+		if binary.LittleEndian.Uint16(packet[8:10]) != artNetOpDmx {
+			return 0, nil, ErrUnknownPacket
+		}
+		universe := binary.LittleEndian.Uint16(packet[14:16]) & 0x7FFF
+		length := int(binary.BigEndian.Uint16(packet[16:18]))
+		return universe, clip(packet[artNetHeaderSize:], length), nil
+
+	case len(packet) > sacnHeaderSize && bytes.Equal(packet[4:16], sacnID):
+		// Root vector 4 is E1.31 data, framing vector 2 is a data packet and the start code must be 0.
+		if binary.BigEndian.Uint32(packet[18:22]) != 4 || binary.BigEndian.Uint32(packet[40:44]) != 2 || packet[sacnHeaderSize] != 0 {
+			return 0, nil, ErrUnknownPacket
+		}
+		universe := binary.BigEndian.Uint16(packet[113:115])
+		// The property value count includes the start code, so a count of 0 is malformed.
+		length := int(binary.BigEndian.Uint16(packet[123:125])) - 1
+		if length < 0 {
+			return 0, nil, ErrUnknownPacket
+		}
+		return universe, clip(packet[sacnHeaderSize+1:], length), nil
+	}
+	return 0, nil, ErrUnknownPacket
+}
+
+/*
+Internal function used to limit DMX data to the length given in the packet.
+*/
+func clip(data []byte, length int) []byte {
+	if length > universeSize {
+		length = universeSize
+	}
+	if length < 0 {
+		length = 0
+	}
+	if length < len(data) {
+		return data[:length]
+	}
+	return data
+}
@@ -0,0 +1,128 @@
+package dmx
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func artNetPacket(universe uint16, data []byte) []byte {
+	packet := make([]byte, artNetHeaderSize+len(data))
+	copy(packet, artNetID)
+	binary.LittleEndian.PutUint16(packet[8:], artNetOpDmx)
+	packet[11] = 14
+	binary.LittleEndian.PutUint16(packet[14:], universe)
+	binary.BigEndian.PutUint16(packet[16:], uint16(len(data)))
+	copy(packet[artNetHeaderSize:], data)
+	return packet
+}
+
+func sacnPacket(universe uint16, data []byte) []byte {
+	packet := make([]byte, sacnHeaderSize+1+len(data))
+	binary.BigEndian.PutUint16(packet[0:], 0x0010)
+	copy(packet[4:], sacnID)
+	binary.BigEndian.PutUint32(packet[18:], 4)
+	binary.BigEndian.PutUint32(packet[40:], 2)
+	binary.BigEndian.PutUint16(packet[113:], universe)
+	binary.BigEndian.PutUint16(packet[123:], uint16(len(data)+1))
+	copy(packet[sacnHeaderSize+1:], data)
+	return packet
+}
+
+func TestReceiverMapping(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 4)
+	receiver := NewReceiver(strip,
+		Mapping{Universe: 1, Channel: 4, Start: 0, Count: 2},
+		Mapping{Universe: 2, Channel: 1, Start: 2, Count: 2})
+
+	if err := receiver.HandlePacket(artNetPacket(1, []byte{9, 9, 9, 255, 0, 0, 0, 255, 0})); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if err := receiver.HandlePacket(sacnPacket(2, []byte{0, 0, 255, 1, 2, 3})); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+
+	expected := []dotstar.Colour{dotstar.Red, dotstar.Green, dotstar.Blue, dotstar.NewColour(1, 2, 3, 255)}
+	for i, want := range expected {
+		if got := strip.GetColour(i); got != want {
+			t.Errorf("LED %d got %v expected %v\n", i, got, want)
+		}
+	}
+}
+
+func TestReceiverIgnoresOtherPackets(t *testing.T) {
+	receiver := NewReceiver(dotstar.NewController(ioutil.Discard, 1))
+	if err := receiver.HandlePacket([]byte("hello")); err != ErrUnknownPacket {
+		t.Errorf("Got %v expected ErrUnknownPacket\n", err)
+	}
+}
+
+func TestReceiverEmptyPackets(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 1)
+	strip.SetColour(0, dotstar.Blue)
+	receiver := NewReceiver(strip, Mapping{Universe: 1, Channel: 1, Start: 0, Count: 1})
+
+	if err := receiver.HandlePacket(artNetPacket(1, nil)); err != nil {
+		t.Errorf("Unexpected error %v\n", err)
+	}
+	// A property value count of 0 leaves no room for the start code.
+	packet := sacnPacket(1, []byte{255, 0, 0})
+	binary.BigEndian.PutUint16(packet[123:], 0)
+	if err := receiver.HandlePacket(packet); err != ErrUnknownPacket {
+		t.Errorf("Got %v expected ErrUnknownPacket\n", err)
+	}
+	if got := strip.GetColour(0); got != dotstar.Blue {
+		t.Errorf("Got %v expected %v\n", got, dotstar.Blue)
+	}
+	if got := clip([]byte{1, 2}, -1); len(got) != 0 {
+		t.Errorf("Got %v expected no data\n", got)
+	}
+}
+
+// packetConn returns each of its packets from ReadFrom, then io.EOF.
+type packetConn struct {
+	net.PacketConn
+	packets [][]byte
+}
+
+func (c *packetConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	if len(c.packets) == 0 {
+		return 0, nil, io.EOF
+	}
+	n := copy(p, c.packets[0])
+	c.packets = c.packets[1:]
+	return n, nil, nil
+}
+
+// failingWriter fails its first write.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == 1 {
+		return 0, errors.New("Write failed")
+	}
+	return len(p), nil
+}
+
+func TestReceiverServeUpdateError(t *testing.T) {
+	out := &failingWriter{}
+	strip := dotstar.NewController(out, 1)
+	receiver := NewReceiver(strip, Mapping{Universe: 1, Channel: 1, Start: 0, Count: 1})
+	var errs []error
+	receiver.OnUpdateError(func(err error) { errs = append(errs, err) })
+
+	conn := &packetConn{packets: [][]byte{artNetPacket(1, []byte{0, 255, 0}), artNetPacket(1, []byte{0, 0, 255})}}
+	if err := receiver.Serve(conn); err != io.EOF {
+		t.Errorf("Got %v expected %v\n", err, io.EOF)
+	}
+	if len(errs) != 1 || out.writes != 2 || strip.GetColour(0) != dotstar.Blue {
+		t.Errorf("Got errors %v after %d writes showing %v\n", errs, out.writes, strip.GetColour(0))
+	}
+}
@@ -0,0 +1,170 @@
+package dmx
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"io"
+	"net"
+)
+
+// ledsPerUniverse is the number of LEDs sent in each universe, leaving the last 2 channels unused.
+const ledsPerUniverse = universeSize / channelsPerLed
+
+// defaultPriority is the sACN priority used for sent data.
+const defaultPriority = 100
+
+/*
+A Sender packages Dotstar messages written by a Controller into sACN (E1.31) universes and sends them over UDP.
+
+This allows remote pixel controllers, such as Falcon or WLED, to be driven in place of a local SPI bus.
+Each universe carries 170 LEDs, the first LED being sent in the starting universe.  The Dotstar brightness is
+applied by scaling the RGB values.
+*/
+type Sender struct {
+	conn     io.Writer
+	universe uint16
+	// order is the colour order used to decode messages, format their layout as given by the Controller.
+	order      string
+	format     dotstar.FrameFormat
+	cid        [16]byte
+	sourceName string
+	sequence   uint8
+	packet     []byte
+}
+
+/*
+DialSender connects to the sACN receiver at addr (host:port, the port defaults to 5568) and returns a Sender.
+
+order must match the order configured on the Controller.
+*/
+func DialSender(addr string, order string, universe uint16) (*Sender, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(SACNPort))
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := NewSender(conn, order, universe)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return sender, nil
+}
+
+/*
+NewSender creates a Sender that writes one packet per universe to conn, starting at the given universe.
+*/
+func NewSender(conn io.Writer, order string, universe uint16) (*Sender, error) {
+	if _, err := dotstar.OrderConfig(order); err != nil {
+		return nil, err
+	}
+	sender := &Sender{
+		conn:       conn,
+		universe:   universe,
+		order:      order,
+		sourceName: "dotstar",
+	}
+	if _, err := rand.Read(sender.cid[:]); err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+/*
+SetFrameFormat implements dotstar.FrameDecoder, it is called by the Controller.
+*/
+func (s *Sender) SetFrameFormat(format dotstar.FrameFormat) {
+	s.format = format
+}
+
+/*
+WriteFrame sends the Dotstar message as one or more universes.
+*/
+func (s *Sender) WriteFrame(frame []byte) error {
+	clrs, err := dotstar.DecodeFrameFormat(frame, s.order, s.format)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 0, universeSize)
+	universe := s.universe
+	for _, clr := range clrs {
+		clr = clr.Flatten()
+		data = append(data, clr.R, clr.G, clr.B)
+		if len(data) == ledsPerUniverse*channelsPerLed {
+			if err := s.send(universe, data); err != nil {
+				return err
+			}
+			data = data[:0]
+			universe++
+		}
+	}
+	if len(data) > 0 {
+		return s.send(universe, data)
+	}
+	return nil
+}
+
+/*
+Write sends the Dotstar message in p.  Each call must contain a whole message.
+*/
+func (s *Sender) Write(p []byte) (int, error) {
+	if err := s.WriteFrame(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/*
+Close closes the connection if it implements io.Closer.
+*/
+func (s *Sender) Close() error {
+	if closer, ok := s.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+/*
+Internal method used to build and send an E1.31 data packet for a universe.
+*/
+func (s *Sender) send(universe uint16, data []byte) error {
+	size := sacnHeaderSize + 1 + len(data)
+	if cap(s.packet) < size {
+		s.packet = make([]byte, size, size)
+	}
+	p := s.packet[:size]
+	for i := range p {
+		p[i] = 0
+	}
+
+	// Root layer
+	binary.BigEndian.PutUint16(p[0:], 0x0010)
+	copy(p[4:], sacnID)
+	binary.BigEndian.PutUint16(p[16:], 0x7000|uint16(size-16))
+	binary.BigEndian.PutUint32(p[18:], 4)
+	copy(p[22:38], s.cid[:])
+
+	// Framing layer
+	binary.BigEndian.PutUint16(p[38:], 0x7000|uint16(size-38))
+	binary.BigEndian.PutUint32(p[40:], 2)
+	copy(p[44:108], s.sourceName)
+	p[108] = defaultPriority
+	p[111] = s.sequence
+	binary.BigEndian.PutUint16(p[113:], universe)
+
+	// DMP layer
+	binary.BigEndian.PutUint16(p[115:], 0x7000|uint16(size-115))
+	p[117] = 0x02
+	p[118] = 0xA1
+	binary.BigEndian.PutUint16(p[121:], 1)
+	binary.BigEndian.PutUint16(p[123:], uint16(len(data)+1))
+	copy(p[sacnHeaderSize+1:], data)
+
+	s.sequence++
+	_, err := s.conn.Write(p)
+	return err
+}
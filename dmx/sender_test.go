@@ -0,0 +1,63 @@
+package dmx
+
+import (
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"testing"
+)
+
+// packetWriter keeps a copy of each packet written.
+type packetWriter struct {
+	packets [][]byte
+}
+
+func (pw *packetWriter) Write(p []byte) (int, error) {
+	pw.packets = append(pw.packets, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func TestSenderToReceiver(t *testing.T) {
+	out := &packetWriter{}
+	sender, err := NewSender(out, "bgr", 5)
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	strip := dotstar.NewController(sender, 200, dotstar.DisableGammaCorrectionConfig())
+	strip.SetColour(0, dotstar.Red)
+	strip.SetColour(199, dotstar.Blue)
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if len(out.packets) != 2 {
+		t.Fatalf("Got %d packets expected 2\n", len(out.packets))
+	}
+
+	remote := dotstar.NewController(ioutil.Discard, 200)
+	receiver := NewReceiver(remote,
+		Mapping{Universe: 5, Channel: 1, Start: 0, Count: 170},
+		Mapping{Universe: 6, Channel: 1, Start: 170, Count: 30})
+	for _, packet := range out.packets {
+		if err := receiver.HandlePacket(packet); err != nil {
+			t.Fatalf("Unexpected error %v\n", err)
+		}
+	}
+	if remote.GetColour(0) != dotstar.Red || remote.GetColour(199) != dotstar.Blue || remote.GetColour(1) != dotstar.NewColour(0, 0, 0, 255) {
+		t.Errorf("Got %v %v %v\n", remote.GetColour(0), remote.GetColour(199), remote.GetColour(1))
+	}
+}
+
+func TestSenderEndFrameOnes(t *testing.T) {
+	out := &packetWriter{}
+	sender, err := NewSender(out, "bgr", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	strip := dotstar.NewController(sender, 200, dotstar.EndFrameConfig(dotstar.EndFrameOnes, 8))
+	if err := strip.Update(); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	// The 0xFF end frame must not be sent as extra white LEDs.
+	if len(out.packets) != 2 || len(out.packets[1]) != sacnHeaderSize+1+30*channelsPerLed {
+		t.Errorf("Got %d packets, the last of %d bytes\n", len(out.packets), len(out.packets[len(out.packets)-1]))
+	}
+}
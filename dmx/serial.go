@@ -0,0 +1,84 @@
+package dmx
+
+import (
+	"bufio"
+	"io"
+)
+
+// dmxStartCode is the start code of a frame carrying dimmer levels, other start codes are for other data.
+const dmxStartCode = 0
+
+/*
+ServeSerial reads DMX512 frames from a serial port attached to a DMX line, such as a USB-RS485 adapter, and
+applies the channels to the LEDs mapped to universe.  It returns when reading from port fails, returning nil at
+the end of the input.  Update() errors are given to the OnUpdateError function.
+
+This lets a strip be patched straight into a lighting console, without an Art-Net node.  DMX marks the start of
+each frame with a break, which a serial port can only report as a marked error.  The port must be set to 250000
+baud, 8 data bits, 2 stop bits and no parity, with parity marking turned on (PARMRK in termios, -ignbrk and
+-brkint) so that a break is read as the bytes 0xFF 0x00 0x00 and a 0xFF data byte as 0xFF 0xFF.  Frames with
+any other marked error are dropped, as are frames with a start code other than 0.
+
+Each frame is applied once all 512 channels have been read, or when the next break arrives for shorter frames.
+*/
+func (r *Receiver) ServeSerial(port io.Reader, universe uint16) error {
+	in := bufio.NewReader(port)
+	frame := make([]byte, 0, universeSize+1)
+	// synced is set once the first break has been seen, valid while the current frame has had no errors.
+	synced, valid := false, false
+	finish := func() {
+		if !synced || !valid || len(frame) < 2 || frame[0] != dmxStartCode {
+			return
+		}
+		if err := r.Apply(universe, frame[1:]); err != nil {
+			r.updateFailed(err)
+		}
+	}
+	for {
+		b, err := in.ReadByte()
+		if err == io.EOF {
+			finish()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b == 0xFF {
+			if b, err = in.ReadByte(); err != nil {
+				return ignoreEOF(err)
+			}
+			if b == 0x00 {
+				// A marked error, which is a break if the byte read in error was 0.
+				if b, err = in.ReadByte(); err != nil {
+					return ignoreEOF(err)
+				}
+				if b != 0x00 {
+					valid = false
+					continue
+				}
+				finish()
+				synced, valid, frame = true, true, frame[:0]
+				continue
+			}
+		}
+		if !valid || len(frame) == cap(frame) {
+			continue
+		}
+		frame = append(frame, b)
+		if len(frame) == cap(frame) {
+			finish()
+			// Only apply the frame once, waiting for the next break.
+			valid = false
+		}
+	}
+}
+
+/*
+Internal function used to treat the end of the input as a clean finish.
+*/
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package dmx
+
+import (
+	"bytes"
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"testing"
+)
+
+// serialBreak is how a break is read from a serial port with parity marking.
+var serialBreak = []byte{0xFF, 0x00, 0x00}
+
+func TestServeSerial(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	receiver := NewReceiver(strip, Mapping{Universe: 1, Channel: 1, Start: 0, Count: 3})
+
+	var line bytes.Buffer
+	// Data before the first break is ignored.
+	line.Write([]byte{1, 2, 3})
+	// A frame with a 0xFF data byte, which is escaped.
+	line.Write(serialBreak)
+	line.Write([]byte{0, 0xFF, 0xFF, 0, 0, 0, 0xFF, 0xFF, 0})
+	// A frame with another start code is ignored.
+	line.Write(serialBreak)
+	line.Write([]byte{0xCC, 9, 9, 9})
+	// A frame with a framing error is dropped.
+	line.Write(serialBreak)
+	line.Write([]byte{0, 9, 0xFF, 0x00, 0x55, 9, 9})
+	// The last frame is applied at the end of the input.
+	line.Write(serialBreak)
+	line.Write([]byte{0, 0xFF, 0xFF, 0, 0, 0, 0xFF, 0xFF, 0, 0, 0, 0xFF, 0xFF})
+
+	if err := receiver.ServeSerial(&line, 1); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	expected := []dotstar.Colour{dotstar.Red, dotstar.Green, dotstar.Blue}
+	for i, clr := range expected {
+		if strip.GetColour(i) != clr {
+			t.Errorf("LED %d got %v expected %v\n", i, strip.GetColour(i), clr)
+		}
+	}
+}
+
+func TestServeSerialFullFrame(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 1)
+	receiver := NewReceiver(strip, Mapping{Universe: 1, Channel: 510, Start: 0, Count: 1})
+	var line bytes.Buffer
+	line.Write(serialBreak)
+	line.WriteByte(0)
+	line.Write(make([]byte, 509))
+	line.Write([]byte{7, 8, 9})
+	// Bytes beyond the universe are ignored.
+	line.Write([]byte{1, 1})
+	if err := receiver.ServeSerial(&line, 1); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if strip.GetColour(0) != dotstar.NewColour(7, 8, 9, 255) {
+		t.Errorf("Got %v expected %v\n", strip.GetColour(0), dotstar.NewColour(7, 8, 9, 255))
+	}
+}
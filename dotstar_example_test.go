@@ -3,10 +3,13 @@ package dotstar_test
 import (
 	"github.com/kidoman/embd"
 	"github.com/owlfish/dotstar"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-func ExampleRedBlue() {
+func Example_redBlue() {
 	ledCount := 30
 
 	if err := embd.InitSPI(); err != nil {
@@ -33,3 +36,31 @@ func ExampleRedBlue() {
 	strip.Update()
 	time.Sleep(time.Hour * 1)
 }
+
+func Example_shutdown() {
+	if err := embd.InitSPI(); err != nil {
+		panic(err)
+	}
+	defer embd.CloseSPI()
+
+	spiBus := embd.NewSPIBus(embd.SPIMode0, 0, 31200000, 8, 0)
+	strip := dotstar.NewController(spiBus, 30)
+	// Close blanks the strip and closes the SPI bus.
+	defer strip.Close()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	ticker := time.NewTicker(time.Second / 30)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		strip.Clear()
+		strip.SetColour(i%30, dotstar.White)
+		strip.Update()
+	}
+}
@@ -0,0 +1,155 @@
+/*
+The dotstartest package helps test code that drives a dotstar.Controller without any hardware.
+
+A Writer decodes each message sent by Update() back into colours and keeps a history of frames:
+
+	strip, out := dotstartest.NewController(10)
+	strip.SetColour(3, dotstar.Red)
+	strip.Update()
+	dotstartest.AssertLED(t, out.Last(), 3, dotstar.Red)
+
+AssertGolden compares frames with ones stored in the testdata directory of the package under test, for regression
+testing effects.
+*/
+package dotstartest
+
+import (
+	"github.com/owlfish/dotstar"
+	"sync"
+	"testing"
+)
+
+/*
+A Writer records the frames written to it by a Controller.  It is safe to use from multiple goroutines.
+*/
+type Writer struct {
+	mu    sync.Mutex
+	order string
+	// format is the layout of the messages, as given by the Controller.
+	format dotstar.FrameFormat
+	frames [][]dotstar.Colour
+	raw    [][]byte
+}
+
+/*
+NewWriter creates a Writer that decodes messages using the given colour order.
+
+order must match the order configured on the Controller, the default being "bgr".
+*/
+func NewWriter(order string) (*Writer, error) {
+	if _, err := dotstar.OrderConfig(order); err != nil {
+		return nil, err
+	}
+	return &Writer{order: order}, nil
+}
+
+/*
+NewController creates a Controller writing to a new Writer, with gamma correction disabled so that the recorded
+colours match those set.  Any cfgs are applied afterwards, but must leave the default bgr order.
+*/
+func NewController(ledCount int, cfgs ...dotstar.ConfigFunc) (*dotstar.Controller, *Writer) {
+	w, _ := NewWriter("bgr")
+	cfgs = append([]dotstar.ConfigFunc{dotstar.DisableGammaCorrectionConfig()}, cfgs...)
+	return dotstar.NewController(w, ledCount, cfgs...), w
+}
+
+/*
+Write decodes and records the Dotstar message in p, returning an error if it is not a valid message.
+*/
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	frame, err := dotstar.DecodeFrameFormat(p, w.order, w.format)
+	if err != nil {
+		return 0, err
+	}
+	w.frames = append(w.frames, frame)
+	w.raw = append(w.raw, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+/*
+SetFrameFormat implements dotstar.FrameDecoder, it is called by the Controller.
+*/
+func (w *Writer) SetFrameFormat(format dotstar.FrameFormat) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.format = format
+}
+
+/*
+Frames returns every frame written so far, oldest first.
+*/
+func (w *Writer) Frames() [][]dotstar.Colour {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([][]dotstar.Colour(nil), w.frames...)
+}
+
+/*
+Raw returns the bytes of every message written so far, oldest first.
+*/
+func (w *Writer) Raw() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([][]byte(nil), w.raw...)
+}
+
+/*
+Last returns the most recent frame, or nil if nothing has been written.
+*/
+func (w *Writer) Last() []dotstar.Colour {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.frames) == 0 {
+		return nil
+	}
+	return w.frames[len(w.frames)-1]
+}
+
+/*
+Reset forgets all recorded frames.
+*/
+func (w *Writer) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.frames, w.raw = nil, nil
+}
+
+/*
+Equivalent returns true if got is what the strip shows for want.
+
+Only 32 luminosity levels are sent to the LEDs, so luminosities are compared to that precision.
+*/
+func Equivalent(got, want dotstar.Colour) bool {
+	return got.R == want.R && got.G == want.G && got.B == want.B && got.L>>3 == want.L>>3
+}
+
+/*
+AssertLED reports an error if the LED at pos in frame does not show want.
+*/
+func AssertLED(t testing.TB, frame []dotstar.Colour, pos int, want dotstar.Colour) {
+	t.Helper()
+	if pos < 0 || pos >= len(frame) {
+		t.Errorf("LED %d is outside the frame of %d LEDs\n", pos, len(frame))
+		return
+	}
+	if !Equivalent(frame[pos], want) {
+		t.Errorf("LED %d got %v expected %v\n", pos, frame[pos], want)
+	}
+}
+
+/*
+AssertFrame reports an error for each LED in frame that does not show the colour in want.
+*/
+func AssertFrame(t testing.TB, frame []dotstar.Colour, want []dotstar.Colour) {
+	t.Helper()
+	if len(frame) != len(want) {
+		t.Errorf("Got %d LEDs expected %d\n", len(frame), len(want))
+	}
+	for i := range want {
+		if i < len(frame) && !Equivalent(frame[i], want[i]) {
+			t.Errorf("LED %d got %v expected %v\n", i, frame[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package dotstartest
+
+import (
+	"github.com/owlfish/dotstar"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	strip, out := NewController(3)
+	strip.SetColour(1, dotstar.NewColour(10, 20, 30, 128))
+	strip.Update()
+	strip.SetColour(2, dotstar.Blue)
+	strip.Update()
+
+	frames := out.Frames()
+	if len(frames) != 2 || len(out.Raw()) != 2 {
+		t.Fatalf("Got %d frames expected 2\n", len(frames))
+	}
+	AssertFrame(t, frames[0], []dotstar.Colour{dotstar.Off, dotstar.NewColour(10, 20, 30, 128), dotstar.Off})
+	AssertLED(t, out.Last(), 2, dotstar.Blue)
+
+	out.Reset()
+	if out.Last() != nil {
+		t.Errorf("Got %v after Reset\n", out.Last())
+	}
+}
+
+func TestWriterOrder(t *testing.T) {
+	out, err := NewWriter("RGB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rgb, _ := dotstar.OrderConfig("rgb")
+	strip := dotstar.NewController(out, 1, rgb, dotstar.DisableGammaCorrectionConfig())
+	strip.SetColour(0, dotstar.NewColour(1, 2, 3, 255))
+	strip.Update()
+	AssertLED(t, out.Last(), 0, dotstar.NewColour(1, 2, 3, 255))
+
+	if _, err := NewWriter("rgg"); err == nil {
+		t.Errorf("Expected an error for an invalid order\n")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	frames := [][]dotstar.Colour{{dotstar.Red, dotstar.Off}, {dotstar.NewColour(0, 255, 0, 128), dotstar.NewColourW(0, 0, 0, 64, 255)}}
+	AssertGolden(t, "example", frames)
+
+	parsed, err := parseGolden(formatGolden(frames))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 || !dotstar.ColoursEqual(parsed[0], frames[0], 0) || !dotstar.ColoursEqual(parsed[1], frames[1], 0) {
+		t.Errorf("Got %v expected %v\n", parsed, frames)
+	}
+	if _, err := parseGolden([]byte("#FF0000FF\nnot a colour\n")); err == nil {
+		t.Errorf("Expected an error for an invalid line\n")
+	}
+}
+
+func TestWriterEndFrameOnes(t *testing.T) {
+	strip, out := NewController(4, dotstar.EndFrameConfig(dotstar.EndFrameOnes, 8))
+	strip.Update()
+	if frame := out.Last(); len(frame) != 4 {
+		t.Errorf("Got %d LEDs expected 4\n", len(frame))
+	}
+}
@@ -0,0 +1,116 @@
+package dotstartest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden write the frames it is given as the new golden
+// frames, for example by running DOTSTARTEST_UPDATE=1 go test ./...
+const UpdateEnv = "DOTSTARTEST_UPDATE"
+
+/*
+AssertGolden compares frames with the golden frames stored in testdata/name.golden, reporting an error for each LED
+that does not show the stored colour.
+
+This is a regression test for effects: render a few frames, check them by eye once, then store them by running
+the tests with DOTSTARTEST_UPDATE=1 set.  The file holds one line per LED with a blank line between frames, each
+colour written as #RRGGBBLL followed by W and the white value for RGBW colours, so changes show clearly in a diff.
+*/
+func AssertGolden(t testing.TB, name string, frames [][]dotstar.Colour) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, formatGolden(frames), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading golden frames: %v, set %s=1 to create them\n", err, UpdateEnv)
+	}
+	golden, err := parseGolden(data)
+	if err != nil {
+		t.Fatalf("Reading %v: %v\n", path, err)
+	}
+	if len(frames) != len(golden) {
+		t.Errorf("Got %d frames expected %d\n", len(frames), len(golden))
+	}
+	for f := 0; f < len(frames) && f < len(golden); f++ {
+		if len(frames[f]) != len(golden[f]) {
+			t.Errorf("Frame %d got %d LEDs expected %d\n", f, len(frames[f]), len(golden[f]))
+		}
+		for _, i := range dotstar.DiffColours(frames[f], golden[f]) {
+			if i < len(frames[f]) && i < len(golden[f]) {
+				t.Errorf("Frame %d LED %d got %v expected %v\n", f, i, frames[f][i], golden[f][i])
+			}
+		}
+	}
+}
+
+/*
+Internal function used to write frames in the golden file format.
+*/
+func formatGolden(frames [][]dotstar.Colour) []byte {
+	var out bytes.Buffer
+	for f, frame := range frames {
+		if f > 0 {
+			out.WriteString("\n")
+		}
+		for _, c := range frame {
+			fmt.Fprintf(&out, "#%02X%02X%02X%02X", c.R, c.G, c.B, c.L)
+			if c.W != 0 {
+				fmt.Fprintf(&out, " W %02X", c.W)
+			}
+			out.WriteString("\n")
+		}
+	}
+	return out.Bytes()
+}
+
+/*
+Internal function used to read frames from the golden file format.
+*/
+func parseGolden(data []byte) ([][]dotstar.Colour, error) {
+	var frames [][]dotstar.Colour
+	var frame []dotstar.Colour
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			frames = append(frames, frame)
+			frame = nil
+			continue
+		}
+		var c dotstar.Colour
+		var err error
+		if hex := strings.SplitN(text, " W ", 2); len(hex) == 2 {
+			c, err = dotstar.ParseColour(hex[0])
+			if err == nil {
+				_, err = fmt.Sscanf(hex[1], "%02X", &c.W)
+			}
+		} else {
+			c, err = dotstar.ParseColour(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", line, err)
+		}
+		frame = append(frame, c)
+	}
+	if frame != nil {
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
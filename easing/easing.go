@@ -0,0 +1,112 @@
+/*
+The easing package provides standard easing curves for animations.
+
+Each curve takes a progress value t from 0 (start) to 1 (end) and returns the eased progress, which is 0 at
+the start and 1 at the end but may overshoot in between.  The curves can be passed to Colour.BlendEased:
+
+	clr := dotstar.Red.BlendEased(dotstar.Blue, t, easing.CubicInOut)
+*/
+package easing
+
+import (
+	"math"
+)
+
+/*
+A Func maps linear progress to eased progress.
+*/
+type Func func(t float64) float64
+
+// Linear moves at a constant rate.
+func Linear(t float64) float64 {
+	return t
+}
+
+// QuadIn starts slowly and accelerates.
+func QuadIn(t float64) float64 {
+	return t * t
+}
+
+// QuadOut starts quickly and decelerates.
+func QuadOut(t float64) float64 {
+	return t * (2 - t)
+}
+
+// QuadInOut accelerates until half way and then decelerates.
+func QuadInOut(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// CubicIn starts slowly and accelerates, more sharply than QuadIn.
+func CubicIn(t float64) float64 {
+	return t * t * t
+}
+
+// CubicOut starts quickly and decelerates, more sharply than QuadOut.
+func CubicOut(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// CubicInOut accelerates until half way and then decelerates, more sharply than QuadInOut.
+func CubicInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// SineIn follows a quarter sine wave, starting slowly.
+func SineIn(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+// SineOut follows a quarter sine wave, finishing slowly.
+func SineOut(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+// SineInOut follows a half sine wave, starting and finishing slowly.
+func SineInOut(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+// BounceOut bounces to a stop at the end, like a dropped ball.
+func BounceOut(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// BounceIn bounces away from the start, the reverse of BounceOut.
+func BounceIn(t float64) float64 {
+	return 1 - BounceOut(1-t)
+}
+
+// ElasticOut overshoots the end and oscillates into place, like a spring.
+func ElasticOut(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*2*math.Pi/3) + 1
+}
+
+// ElasticIn winds up with growing oscillations before leaving the start, the reverse of ElasticOut.
+func ElasticIn(t float64) float64 {
+	return 1 - ElasticOut(1-t)
+}
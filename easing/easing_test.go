@@ -0,0 +1,28 @@
+package easing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEndPoints(t *testing.T) {
+	curves := map[string]Func{
+		"Linear": Linear, "QuadIn": QuadIn, "QuadOut": QuadOut, "QuadInOut": QuadInOut,
+		"CubicIn": CubicIn, "CubicOut": CubicOut, "CubicInOut": CubicInOut,
+		"SineIn": SineIn, "SineOut": SineOut, "SineInOut": SineInOut,
+		"BounceIn": BounceIn, "BounceOut": BounceOut, "ElasticIn": ElasticIn, "ElasticOut": ElasticOut,
+	}
+	for name, curve := range curves {
+		if start, end := curve(0), curve(1); math.Abs(start) > 1e-9 || math.Abs(end-1) > 1e-9 {
+			t.Errorf("%s got %v at 0 and %v at 1\n", name, start, end)
+		}
+	}
+}
+
+func TestInOutSymmetry(t *testing.T) {
+	for _, curve := range []Func{QuadInOut, CubicInOut, SineInOut} {
+		if mid := curve(0.5); math.Abs(mid-0.5) > 1e-9 {
+			t.Errorf("Got %v half way expected 0.5\n", mid)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"math/rand"
+	"time"
+)
+
+// candleColour is the warm orange of a candle flame, used when CandleEffect.Colour is not set.
+var candleColour = dotstar.NewColour(255, 147, 41, 255)
+
+/*
+CandleEffect gives the gentle flicker of candle light, for warm ambient lighting such as shelves.
+
+Every LED flickers independently.  The brightness of each follows its own random walk, with occasional deeper
+dips as if in a draught, and its hue wanders slightly between orange and red.  Unlike FireEffect nothing moves
+along the strip, so it works as well on a single LED as on a long strip.
+*/
+type CandleEffect struct {
+	// Colour is the colour of the flame at full brightness.  If its luminosity is 0, a warm orange is used.
+	Colour dotstar.Colour
+	// Intensity is how strong the flicker is, from 0 (steady) to 1 (the flame nearly goes out).
+	Intensity float64
+	// Rand is the source of randomness.  If nil, a source seeded from the time is created.
+	Rand *rand.Rand
+
+	// level is the brightness of each LED from 0 to 1, and hue how far it has moved towards red.
+	level, hue []float64
+}
+
+/*
+NewCandleEffect creates a CandleEffect with a warm orange flame and a moderate flicker intensity of 0.5.
+*/
+func NewCandleEffect() *CandleEffect {
+	return &CandleEffect{Colour: candleColour, Intensity: 0.5}
+}
+
+/*
+Render advances the flicker of every LED by one frame.
+*/
+func (c *CandleEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	if c.Rand == nil {
+		c.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if len(c.level) != len(leds) {
+		c.level = make([]float64, len(leds))
+		c.hue = make([]float64, len(leds))
+		for i := range c.level {
+			c.level[i] = 1
+		}
+	}
+	colour := c.Colour
+	if colour.L == 0 {
+		colour = candleColour
+	}
+	intensity := c.Intensity
+	if intensity < 0 {
+		intensity = 0
+	}
+	if intensity > 1 {
+		intensity = 1
+	}
+	lowest := 1 - 0.8*intensity
+
+	for i := range leds {
+		// Wander randomly, drawn back towards full brightness, with the odd draught.
+		level := c.level[i] + (c.Rand.Float64()-0.5)*0.3*intensity + (1-c.level[i])*0.1
+		if c.Rand.Intn(100) == 0 {
+			level -= 0.5 * intensity
+		}
+		if level < lowest {
+			level = lowest
+		}
+		if level > 1 {
+			level = 1
+		}
+		c.level[i] = level
+
+		hue := c.hue[i] + (c.Rand.Float64()-0.5)*0.2 - c.hue[i]*0.1
+		if hue < 0 {
+			hue = 0
+		}
+		if hue > 1 {
+			hue = 1
+		}
+		c.hue[i] = hue
+
+		// Dimmer flames burn redder, so shift the green down as well as the overall level.
+		flame := colour
+		flame.G = uint8(float64(flame.G) * (1 - 0.3*intensity*(hue+1-level)/2))
+		leds[i] = flame.Scale(float32(level))
+	}
+}
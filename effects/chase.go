@@ -0,0 +1,41 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"math"
+	"time"
+)
+
+/*
+ChaseEffect runs repeating bands of colour along the strip, like theatre marquee lights.
+*/
+type ChaseEffect struct {
+	// Colours are the colours of the bands, repeated along the strip in order.
+	Colours []dotstar.Colour
+	// Width is the number of LEDs in each band, 0 uses 1.
+	Width int
+	// Speed is how many LEDs the bands move each second, negative values run them backwards.
+	Speed float64
+}
+
+/*
+Render draws the bands at time t.
+*/
+func (c *ChaseEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	if len(c.Colours) == 0 {
+		return
+	}
+	width := c.Width
+	if width < 1 {
+		width = 1
+	}
+	period := width * len(c.Colours)
+	offset := int(math.Floor(c.Speed * t.Seconds()))
+	for i := range leds {
+		position := (i - offset) % period
+		if position < 0 {
+			position += period
+		}
+		leds[i] = c.Colours[position/width]
+	}
+}
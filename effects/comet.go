@@ -0,0 +1,89 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"math"
+	"math/rand"
+	"time"
+)
+
+/*
+CometEffect sends a bright head along the strip, leaving a tail that fades away behind it.
+
+The tail is made by fading the previous frame, with each LED fading at random so the tail breaks up like a meteor
+burning out.  When the head reaches the end of the strip it carries on out of sight for a strip's length, letting
+the tail fade, before starting again from the beginning.  Use a Segment to run a comet along part of the strip.
+*/
+type CometEffect struct {
+	// Colour is the colour of the head, used if Palette is nil.
+	Colour dotstar.Colour
+	// Palette, if set, gives the colour of the head as it moves, from the start of the strip to the end.
+	Palette dotstar.Palette
+	// Speed is how many LEDs the head moves each second.
+	Speed float64
+	// Size is the length of the head in LEDs.
+	Size int
+	// Decay is how much of the tail fades each frame, out of 256.  Higher values give shorter tails.
+	Decay uint8
+	// Reverse makes the comet run from the end of the strip instead.
+	Reverse bool
+	// Rand is the source of randomness.  If nil, a source seeded from the time is created.
+	Rand *rand.Rand
+
+	buffer []dotstar.Colour
+}
+
+/*
+NewCometEffect creates a white CometEffect with a head of 4 LEDs, moving at 30 LEDs a second.
+*/
+func NewCometEffect() *CometEffect {
+	return &CometEffect{Colour: dotstar.White, Speed: 30, Size: 4, Decay: 64}
+}
+
+/*
+Render draws the comet at time t.
+*/
+func (c *CometEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	if c.Rand == nil {
+		c.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if len(c.buffer) != len(leds) {
+		c.buffer = make([]dotstar.Colour, len(leds))
+		for i := range c.buffer {
+			c.buffer[i] = dotstar.Off
+		}
+	}
+	count := len(c.buffer)
+	if count == 0 {
+		return
+	}
+	size := c.Size
+	if size < 1 {
+		size = 1
+	}
+
+	// Break up the tail by fading each LED at random.
+	for i := range c.buffer {
+		if c.Rand.Intn(2) == 0 {
+			dotstar.FadeColours(c.buffer[i:i+1], c.Decay)
+		}
+	}
+
+	// The head runs a strip's length past the end, giving the tail time to fade before it starts again.
+	cycle := float64(2*count + size)
+	head := math.Mod(c.Speed*t.Seconds(), cycle)
+	colour := c.Colour
+	if c.Palette != nil {
+		colour = c.Palette.At(head / float64(count))
+	}
+	// Draw the head at its exact position, so slow comets glide rather than stepping.
+	dotstar.DrawRangeF(c.buffer, head-float64(size)+1, head+1, colour)
+
+	for i, clr := range c.buffer {
+		position := i
+		if c.Reverse {
+			position = count - 1 - i
+		}
+		leds[position] = clr
+	}
+}
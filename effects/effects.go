@@ -0,0 +1,201 @@
+/*
+The effects package provides animated effects for Dotstar strips and an Animator to run them.
+
+An Effect draws each frame into a slice of colours.  The Animator holds the frame between calls, so an effect
+can build on the previous frame (for example fading it to leave trails), and sends each frame to the strip:
+
+	animator := effects.NewAnimator(strip, 60)
+	animator.Run(ctx, &effects.NoiseEffect{Palette: dotstar.LavaPalette, Scale: 0.05, Speed: 0.3})
+*/
+package effects
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"time"
+)
+
+/*
+An Effect draws the frames of an animation.
+*/
+type Effect interface {
+	// Render draws the frame at time t since the animation started into leds.
+	Render(leds []dotstar.Colour, t time.Duration)
+}
+
+/*
+EffectFunc adapts a function to the Effect interface.
+*/
+type EffectFunc func(leds []dotstar.Colour, t time.Duration)
+
+/*
+Render calls the function.
+*/
+func (f EffectFunc) Render(leds []dotstar.Colour, t time.Duration) {
+	f(leds, t)
+}
+
+/*
+A Segment runs an Effect on part of the strip, from LED Start for Count LEDs.
+
+Segments are themselves Effects, so several can be combined with Effects.  Each segment can run at its own frame
+rate by setting FPS, for example a slow ambient effect over a whole staircase with a fast chase on one step.  The
+Animator still sends a single Update() for each of its frames, so it must run at least as fast as the fastest
+segment.
+*/
+type Segment struct {
+	Start, Count int
+	Effect       Effect
+	// FPS, if set, is how many times a second the effect is rendered, with the last frame held in between.
+	// 0 renders the effect on every frame of the Animator.
+	FPS int
+
+	// buffer holds the last frame rendered when FPS is set, with next the time the following frame is due.
+	buffer []dotstar.Colour
+	next   time.Duration
+}
+
+/*
+Render draws the segment's effect into its part of leds.  Parts of the segment beyond leds are left out.
+*/
+func (s *Segment) Render(leds []dotstar.Colour, t time.Duration) {
+	start, end := s.Start, s.Start+s.Count
+	if start < 0 {
+		start = 0
+	}
+	if end > len(leds) {
+		end = len(leds)
+	}
+	if start >= end {
+		return
+	}
+	if s.FPS <= 0 {
+		s.Effect.Render(leds[start:end], t)
+		return
+	}
+	if len(s.buffer) != end-start {
+		s.buffer = make([]dotstar.Colour, end-start)
+		copy(s.buffer, leds[start:end])
+		s.next = t
+	}
+	interval := time.Second / time.Duration(s.FPS)
+	// A frame is rendered when due, or straight away if time has gone back, for example with a new Animator.
+	if t >= s.next || s.next-t > interval {
+		s.Effect.Render(s.buffer, t)
+		// Keep to the segment's own schedule, unless it has fallen behind.
+		if s.next += interval; s.next <= t || s.next-t > interval {
+			s.next = t + interval
+		}
+	}
+	copy(leds[start:end], s.buffer)
+}
+
+/*
+Effects combines several effects into one, rendering each in turn.
+*/
+type Effects []Effect
+
+/*
+Render draws each effect in order, so later effects draw over earlier ones.
+*/
+func (es Effects) Render(leds []dotstar.Colour, t time.Duration) {
+	for _, e := range es {
+		e.Render(leds, t)
+	}
+}
+
+/*
+An Animator runs an Effect on a Controller at a fixed frame rate.
+
+Methods are NOT safe to call from multiple goroutines concurrently, and the Controller should not be changed
+elsewhere while an animation is running.
+*/
+type Animator struct {
+	ctl   *dotstar.Controller
+	fps   int
+	frame []dotstar.Colour
+	// lastFrame is when the previous frame was sent, interval the smoothed time between frames in seconds.
+	lastFrame time.Time
+	interval  float64
+}
+
+/*
+NewAnimator creates an Animator that updates ctl fps times a second.
+*/
+func NewAnimator(ctl *dotstar.Controller, fps int) *Animator {
+	if fps <= 0 {
+		fps = 30
+	}
+	return &Animator{ctl: ctl, fps: fps, frame: ctl.Snapshot()}
+}
+
+/*
+Run renders the effect and updates the strip every frame until ctx is done or an Update() fails.
+
+The context error is returned when ctx is done.
+*/
+func (a *Animator) Run(ctx context.Context, e Effect) error {
+	ticker := time.NewTicker(time.Second / time.Duration(a.fps))
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		if err := a.RenderFrame(ctx, e, time.Since(start)); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+RenderFrame renders a single frame of the effect at time t and updates the strip.
+*/
+func (a *Animator) RenderFrame(ctx context.Context, e Effect, t time.Duration) error {
+	if len(a.frame) != a.ctl.Len() {
+		a.frame = a.ctl.Snapshot()
+	}
+	e.Render(a.frame, t)
+	a.ctl.SetColours(a.frame)
+	if err := a.ctl.UpdateContext(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !a.lastFrame.IsZero() {
+		elapsed := now.Sub(a.lastFrame).Seconds()
+		if a.interval == 0 {
+			a.interval = elapsed
+		} else {
+			a.interval += (elapsed - a.interval) * fpsSmoothing
+		}
+	}
+	a.lastFrame = now
+	return nil
+}
+
+// fpsSmoothing is the weight given to each new frame when measuring the achieved frame rate.
+const fpsSmoothing = 0.1
+
+/*
+TargetFPS returns the frame rate the Animator aims for.
+*/
+func (a *Animator) TargetFPS() int {
+	return a.fps
+}
+
+/*
+FPS returns the frame rate recently achieved, or 0 before two frames have been rendered.
+
+If this is well below TargetFPS the effect or the SPI bus cannot keep up; dotstar.Controller.Stats shows how
+long each Update() takes.
+*/
+func (a *Animator) FPS() float64 {
+	if a.interval <= 0 {
+		return 0
+	}
+	return 1 / a.interval
+}
@@ -0,0 +1,574 @@
+package effects
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnimatorRenderFrame(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	animator := NewAnimator(strip, 30)
+	count := 0
+	effect := EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+		count++
+		leds[count%3] = dotstar.Red
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := animator.RenderFrame(context.Background(), effect, 0); err != nil {
+			t.Fatalf("Unexpected error %v\n", err)
+		}
+	}
+	// The frame is kept between renders.
+	if strip.GetColour(0) != dotstar.Off || strip.GetColour(1) != dotstar.Red || strip.GetColour(2) != dotstar.Red {
+		t.Errorf("Got %v\n", strip.Snapshot())
+	}
+}
+
+func TestAnimatorRunStops(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	frames := 0
+	animator := NewAnimator(strip, 100)
+	err := animator.Run(ctx, EffectFunc(func(leds []dotstar.Colour, t time.Duration) { frames++ }))
+	if err != context.DeadlineExceeded || frames == 0 {
+		t.Errorf("Got %v after %d frames\n", err, frames)
+	}
+	// Allow for slow test machines, the achieved rate cannot be much above the target.
+	if fps := animator.FPS(); animator.TargetFPS() != 100 || fps <= 0 || fps > 150 {
+		t.Errorf("Got %v FPS\n", fps)
+	}
+}
+
+func TestNoiseEffect(t *testing.T) {
+	leds := make([]dotstar.Colour, 50)
+	effect := &NoiseEffect{Palette: dotstar.Palette{dotstar.Red, dotstar.Blue}, Scale: 0.1, Speed: 1}
+	effect.Render(leds, time.Second)
+	for i, clr := range leds {
+		if clr.G != 0 || clr.R+clr.B < 250 {
+			t.Errorf("LED %d got %v expected a blend of red and blue\n", i, clr)
+		}
+	}
+	first := leds[0]
+	effect.Render(leds, 2*time.Second)
+	if leds[0] == first {
+		t.Errorf("Noise did not move over time\n")
+	}
+}
+
+func TestSegment(t *testing.T) {
+	leds := make([]dotstar.Colour, 5)
+	fill := EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+		for i := range leds {
+			leds[i] = dotstar.Green
+		}
+	})
+	Effects{&Segment{Start: 1, Count: 2, Effect: fill}, &Segment{Start: 4, Count: 10, Effect: fill}}.Render(leds, 0)
+	expected := []dotstar.Colour{dotstar.Off, dotstar.Green, dotstar.Green, dotstar.Off, dotstar.Green}
+	for i, want := range expected {
+		if leds[i] != want {
+			t.Errorf("LED %d got %v expected %v\n", i, leds[i], want)
+		}
+	}
+}
+
+func TestSegmentFPS(t *testing.T) {
+	leds := make([]dotstar.Colour, 4)
+	slow, fast := 0, 0
+	count := func(counter *int) Effect {
+		return EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+			*counter++
+			for i := range leds {
+				leds[i] = dotstar.NewColour(uint8(*counter), 0, 0, 255)
+			}
+		})
+	}
+	e := Effects{&Segment{Start: 0, Count: 2, Effect: count(&slow), FPS: 10}, &Segment{Start: 2, Count: 2, Effect: count(&fast)}}
+	for frame := 0; frame < 60; frame++ {
+		e.Render(leds, time.Duration(frame)*time.Second/60)
+	}
+	if slow != 10 || fast != 60 {
+		t.Errorf("Got %d and %d frames expected 10 and 60\n", slow, fast)
+	}
+	// The slow segment holds its last frame between renders.
+	if leds[0].R != 10 || leds[3].R != 60 {
+		t.Errorf("Got %v\n", leds)
+	}
+
+	// Starting again from zero renders straight away.
+	e.Render(leds, 0)
+	if slow != 11 {
+		t.Errorf("Got %d frames expected 11\n", slow)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	s := &Sequence{Colour: dotstar.Red, StepSize: 2, Reveal: time.Second, Hold: time.Second, FadeOut: time.Second}
+	leds := make([]dotstar.Colour, 4)
+	check := func(at time.Duration, expected ...uint8) {
+		s.Render(leds, at)
+		for i, r := range expected {
+			if leds[i].R != r {
+				t.Errorf("At %v LED %d got %v expected red of %v\n", at, i, leds[i], r)
+			}
+		}
+	}
+
+	check(0, 0, 0, 0, 0)
+	if s.Running() {
+		t.Errorf("Running before being triggered\n")
+	}
+	s.Trigger()
+	check(10*time.Second, 0, 0, 0, 0)
+	check(10*time.Second+time.Second/2, 255, 255, 0, 0)
+	check(10*time.Second+time.Second*3/4, 255, 255, 128, 128)
+	check(11500*time.Millisecond, 255, 255, 255, 255)
+	check(12500*time.Millisecond, 128, 128, 128, 128)
+
+	// Triggering again while fading out restarts the hold.
+	s.Trigger()
+	check(12600*time.Millisecond, 255, 255, 255, 255)
+	check(13500*time.Millisecond, 255, 255, 255, 255)
+	check(15*time.Second, 0, 0, 0, 0)
+	if s.Running() || leds[0] != dotstar.Off {
+		t.Errorf("Sequence did not finish, got %v\n", leds[0])
+	}
+
+	// Reversed sequences light from the end.
+	s.TriggerReverse()
+	check(20*time.Second, 0, 0, 0, 0)
+	check(20*time.Second+time.Second/2, 0, 0, 255, 255)
+}
+
+func TestVirtualStrip(t *testing.T) {
+	renders := 0
+	gradient := EffectFunc(func(leds []dotstar.Colour, at time.Duration) {
+		renders++
+		if len(leds) != 3 {
+			t.Fatalf("Got %d virtual LEDs expected 3\n", len(leds))
+		}
+		leds[0], leds[1], leds[2] = dotstar.NewColour(0, 0, 0, 255), dotstar.NewColour(100, 0, 0, 255), dotstar.NewColour(200, 0, 0, 255)
+	})
+	v := NewVirtualStrip(3, gradient)
+	leds := make([]dotstar.Colour, 9)
+	v.Render(leds, 0)
+	for i, clr := range leds {
+		if clr.R != uint8(i*25) || clr.L != 255 {
+			t.Errorf("LED %d got %v expected red of %v\n", i, clr, i*25)
+		}
+	}
+
+	// Shorter strips are sampled.
+	leds = make([]dotstar.Colour, 2)
+	v.Render(leds, 0)
+	if leds[0].R != 0 || leds[1].R != 200 || renders != 2 {
+		t.Errorf("Got %v\n", leds)
+	}
+}
+
+func TestFireEffect(t *testing.T) {
+	fire := NewFireEffect()
+	fire.Sparking = 255
+	fire.Rand = rand.New(rand.NewSource(1))
+	fire.Reverse = true
+	leds := make([]dotstar.Colour, 30)
+	for i := 0; i < 50; i++ {
+		fire.Render(leds, 0)
+	}
+	// The flames start at the end of the strip and cool as they rise.
+	if leds[29] == dotstar.HeatPalette[0] || leds[29].R < leds[0].R {
+		t.Errorf("Got %v\n", leds)
+	}
+}
+
+func TestCandleEffect(t *testing.T) {
+	candle := NewCandleEffect()
+	candle.Intensity = 1
+	candle.Rand = rand.New(rand.NewSource(1))
+	leds := make([]dotstar.Colour, 20)
+	varied := false
+	for i := 0; i < 100; i++ {
+		candle.Render(leds, 0)
+		for _, clr := range leds {
+			// Flames never go out and never get brighter or greener than the flame colour.
+			if clr.R < 40 || clr.R > candleColour.R || clr.G > candleColour.G || clr.B > candleColour.B {
+				t.Fatalf("Got %v\n", clr)
+			}
+			if clr != leds[0] {
+				varied = true
+			}
+		}
+	}
+	if !varied {
+		t.Errorf("Expected every LED to flicker independently\n")
+	}
+
+	candle.Intensity = 0
+	candle.Render(leds, 0)
+	candle.Render(leds, 0)
+	for _, clr := range leds {
+		if clr.G != candleColour.G {
+			t.Errorf("Steady flame got %v\n", clr)
+		}
+	}
+}
+
+func TestCometEffect(t *testing.T) {
+	comet := NewCometEffect()
+	comet.Colour = dotstar.Red
+	comet.Speed = 10
+	comet.Rand = rand.New(rand.NewSource(1))
+	leds := make([]dotstar.Colour, 20)
+	for frame := 0; frame <= 10; frame++ {
+		comet.Render(leds, time.Duration(frame)*time.Second/10)
+	}
+	// The head is at LED 10 after a second, with a fading tail behind it and nothing ahead.
+	for i := 7; i <= 10; i++ {
+		if leds[i] != dotstar.Red {
+			t.Errorf("Head LED %d got %v\n", i, leds[i])
+		}
+	}
+	if leds[0].R == 0 || leds[0].R == 255 || leds[0].R > leds[6].R {
+		t.Errorf("Tail got %v\n", leds[:7])
+	}
+	if leds[11].R != 0 {
+		t.Errorf("Ahead got %v\n", leds[11])
+	}
+
+	comet.Reverse = true
+	comet.Render(leds, time.Second)
+	if leds[9] != dotstar.Red || leds[12] != dotstar.Red || leds[8].R != 0 {
+		t.Errorf("Reversed got %v\n", leds)
+	}
+}
+
+func TestTwinkleEffect(t *testing.T) {
+	twinkle := NewTwinkleEffect(dotstar.Palette{dotstar.Red, dotstar.Blue})
+	twinkle.Density = 0.5
+	twinkle.Background = dotstar.Green
+	twinkle.Rand = rand.New(rand.NewSource(1))
+	leds := make([]dotstar.Colour, 20)
+	twinkle.Render(leds, 0)
+	lit := 0
+	for _, clr := range leds {
+		switch clr {
+		case dotstar.Red, dotstar.Blue:
+			lit++
+		case dotstar.Green:
+		default:
+			t.Errorf("Got %v\n", clr)
+		}
+	}
+	if lit == 0 || lit == len(leds) {
+		t.Errorf("Got %d twinkles\n", lit)
+	}
+
+	twinkle.Density = 0
+	twinkle.Decay = 255
+	twinkle.Render(leds, 0)
+	for _, clr := range leds {
+		if clr != dotstar.Green {
+			t.Errorf("Got %v expected %v\n", clr, dotstar.Green)
+		}
+	}
+}
+
+func TestChaseEffect(t *testing.T) {
+	chase := &ChaseEffect{Colours: []dotstar.Colour{dotstar.Red, dotstar.Blue}, Width: 2, Speed: 1}
+	leds := make([]dotstar.Colour, 6)
+	chase.Render(leds, time.Second)
+	expected := []dotstar.Colour{dotstar.Blue, dotstar.Red, dotstar.Red, dotstar.Blue, dotstar.Blue, dotstar.Red}
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, leds[i], expected[i])
+		}
+	}
+}
+
+func TestHolidayPresets(t *testing.T) {
+	for name, e := range map[string]Effect{
+		"ChristmasTwinkle": ChristmasTwinkle(),
+		"ChristmasChase":   ChristmasChase(),
+		"HalloweenCreep":   HalloweenCreep(),
+		"DiwaliTwinkle":    DiwaliTwinkle(),
+	} {
+		leds := make([]dotstar.Colour, 30)
+		for i := 0; i < 100; i++ {
+			e.Render(leds, time.Duration(i)*time.Second/30)
+		}
+		lit := false
+		for _, clr := range leds {
+			if clr.L != 0 && (clr.R != 0 || clr.G != 0 || clr.B != 0) {
+				lit = true
+			}
+		}
+		if !lit {
+			t.Errorf("%v left the strip dark\n", name)
+		}
+	}
+}
+
+func TestClock(t *testing.T) {
+	ctl := dotstar.NewController(ioutil.Discard, 17*5)
+	m := dotstar.NewMatrix(ctl, 17, 5, false)
+	leds := make([]dotstar.Colour, 17*5)
+	clock := &Clock{Matrix: m, Colour: dotstar.Red, Now: func() time.Time {
+		return time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)
+	}}
+	clock.Render(leds, 0)
+
+	expected := make([]dotstar.Colour, 17*5)
+	m.DrawTextInto(expected, 0, 0, "11:00", dotstar.Red)
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Fatalf("Got %v expected %v at %v\n", leds[i], expected[i], i)
+		}
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	m := dotstar.NewMatrix(dotstar.NewController(ioutil.Discard, 13*5), 13, 5, false)
+	countdown := &Countdown{Matrix: m, Duration: 90 * time.Second, Colour: dotstar.Green}
+	leds := make([]dotstar.Colour, 13*5)
+	countdown.Render(leds, 30*time.Second)
+
+	expected := make([]dotstar.Colour, 13*5)
+	m.DrawTextInto(expected, 0, 0, "1:00", dotstar.Green)
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Fatalf("Got %v expected %v at %v\n", leds[i], expected[i], i)
+		}
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	m := dotstar.NewMatrix(dotstar.NewController(ioutil.Discard, 8), 4, 2, true)
+	bar := &ProgressBar{Matrix: m, Colour: dotstar.Blue, Progress: func() float64 { return 0.5 }}
+	leds := make([]dotstar.Colour, 8)
+	bar.Render(leds, 0)
+	expected := []dotstar.Colour{dotstar.Blue, dotstar.Blue, dotstar.Off, dotstar.Off, dotstar.Off, dotstar.Off, dotstar.Blue, dotstar.Blue}
+	for i := range leds {
+		if leds[i] != expected[i] {
+			t.Errorf("Got %v expected %v\n", leds, expected)
+			break
+		}
+	}
+}
+
+func TestLoadScene(t *testing.T) {
+	doc := `{
+		"brightness": 64,
+		"palettes": {"mine": ["red", "#00f"]},
+		"segments": [
+			{"start": 0, "count": 2, "colour": "tomato"},
+			{"name": "fire", "start": 2, "count": 4, "effect": "fire", "palette": "mine", "params": {"cooling": 80}}
+		]
+	}`
+	scene, err := LoadScene(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scene.Brightness != 64 || len(scene.Segments) != 2 || scene.Segments[1].Palette[1] != dotstar.Blue {
+		t.Fatalf("Got %+v\n", scene)
+	}
+	if fire, ok := scene.Effect.(Effects)[1].(*Segment).Effect.(*FireEffect); !ok || fire.Cooling != 80 || fire.Sparking != 120 {
+		t.Errorf("Fire effect not configured\n")
+	}
+
+	ctl := dotstar.NewController(ioutil.Discard, 6)
+	if err := scene.Apply(ctl); err != nil {
+		t.Fatal(err)
+	}
+	if ctl.GetGlobalBrightness() != 64 || ctl.GetColour(1) != dotstar.NewColour(255, 99, 71, 255) {
+		t.Errorf("Got brightness %v colour %v\n", ctl.GetGlobalBrightness(), ctl.GetColour(1))
+	}
+}
+
+func TestLoadSceneDefaultPalette(t *testing.T) {
+	scene, err := LoadScene(strings.NewReader(`{"segments": [{"count": 4, "effect": "fire"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scene.Segments[0].Palette != nil {
+		t.Errorf("Got palette %v expected nil\n", scene.Segments[0].Palette)
+	}
+	fire := scene.Effect.(Effects)[0].(*Segment).Effect.(*FireEffect)
+	if fire.Palette != nil {
+		t.Errorf("Got fire palette %v expected nil\n", fire.Palette)
+	}
+	// Without a palette the fire maps its heat through the heat palette rather than the rainbow.
+	leds := make([]dotstar.Colour, 4)
+	for i := 0; i < 50; i++ {
+		fire.Render(leds, time.Duration(i)*time.Second/30)
+	}
+	for i, clr := range leds {
+		if clr != dotstar.HeatPalette.At(float64(fire.heat[i])/255) {
+			t.Errorf("LED %d got %v which is not from the heat palette\n", i, clr)
+		}
+	}
+}
+
+func TestLoadSceneComet(t *testing.T) {
+	scene, err := LoadScene(strings.NewReader(`{"segments": [{"count": 10, "effect": "comet", "colour": "red"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comet := scene.Effect.(Effects)[0].(*Segment).Effect.(*CometEffect)
+	if comet.Palette != nil || comet.Colour != dotstar.Red {
+		t.Fatalf("Got palette %v colour %v\n", comet.Palette, comet.Colour)
+	}
+	leds := make([]dotstar.Colour, 10)
+	lit := false
+	for i := 0; i < 10; i++ {
+		scene.Effect.Render(leds, time.Duration(i)*100*time.Millisecond)
+		for _, clr := range leds {
+			if clr.G != 0 || clr.B != 0 {
+				t.Fatalf("Got %v expected a red comet\n", clr)
+			}
+			lit = lit || clr.R != 0
+		}
+	}
+	if !lit {
+		t.Errorf("No LEDs were lit\n")
+	}
+}
+
+func TestLoadSceneOneColour(t *testing.T) {
+	doc := `{"segments": [
+		{"start": 0, "count": 4, "effect": "chase", "colour": "red"},
+		{"start": 4, "count": 20, "effect": "twinkle", "colour": "blue", "params": {"density": 0.5}}
+	]}`
+	scene, err := LoadScene(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	effects := scene.Effect.(Effects)
+	chase := effects[0].(*Segment).Effect.(*ChaseEffect)
+	if len(chase.Colours) != 2 || chase.Colours[0] != dotstar.Red || chase.Colours[1] != dotstar.Off {
+		t.Errorf("Got chase colours %v expected red and off\n", chase.Colours)
+	}
+	twinkle := effects[1].(*Segment).Effect.(*TwinkleEffect)
+	if len(twinkle.Palette) != 1 || twinkle.Palette[0] != dotstar.Blue {
+		t.Errorf("Got twinkle palette %v expected blue\n", twinkle.Palette)
+	}
+
+	leds := make([]dotstar.Colour, 24)
+	scene.Effect.Render(leds, 0)
+	for i, clr := range leds[:4] {
+		if clr != dotstar.Red && clr != dotstar.Off {
+			t.Errorf("Chase LED %d got %v\n", i, clr)
+		}
+	}
+	lit := false
+	for i, clr := range leds[4:] {
+		if clr.R != 0 || clr.G != 0 {
+			t.Errorf("Twinkle LED %d got %v expected blue\n", i, clr)
+		}
+		lit = lit || clr.B != 0
+	}
+	if !lit {
+		t.Errorf("No twinkles were lit\n")
+	}
+}
+
+func TestLoadSceneErrors(t *testing.T) {
+	docs := []string{
+		`{"segments": [{"colour": "nope"}]}`,
+		`{"segments": [{"effect": "missing"}]}`,
+		`{"segments": [{"effect": "noise", "palette": "missing"}]}`,
+		`{"brightness": 300}`,
+		`{"unknown": 1}`,
+		`not json`,
+	}
+	for _, doc := range docs {
+		if _, err := LoadScene(strings.NewReader(doc)); err == nil {
+			t.Errorf("Expected an error for %v\n", doc)
+		}
+	}
+}
+
+func TestCompositor(t *testing.T) {
+	background := &Layer{Effect: EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+		for i := range leds {
+			leds[i] = dotstar.NewColour(0, 0, 100, 255)
+		}
+	})}
+	var started time.Duration = -1
+	pulse := &Layer{Mode: BlendAlpha, Opacity: 0.5, Effect: EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+		if started < 0 {
+			started = t
+		}
+		leds[1] = dotstar.NewColour(200, 0, 0, 255)
+	})}
+	compositor := NewCompositor(background)
+	leds := make([]dotstar.Colour, 3)
+	compositor.Render(leds, time.Second)
+	compositor.AddLayer(pulse)
+	compositor.Render(leds, 2*time.Second)
+
+	// The layer's time starts when it is first drawn.
+	if started != 0 {
+		t.Errorf("Got %v expected 0\n", started)
+	}
+	if leds[0] != dotstar.NewColour(0, 0, 100, 255) || leds[1] != dotstar.NewColour(100, 0, 50, 255) {
+		t.Errorf("Got %v\n", leds)
+	}
+
+	pulse.Mode = BlendAdd
+	compositor.Render(leds, 3*time.Second)
+	if leds[1] != dotstar.NewColour(200, 0, 100, 255) {
+		t.Errorf("Got %v\n", leds[1])
+	}
+
+	pulse.Blend = dotstar.Colour.Multiply
+	compositor.Render(leds, 4*time.Second)
+	if leds[1] != dotstar.NewColour(0, 0, 0, 255) {
+		t.Errorf("Got %v\n", leds[1])
+	}
+
+	if !compositor.RemoveLayer(pulse) || compositor.RemoveLayer(pulse) || compositor.Len() != 1 {
+		t.Errorf("Expected the layer to be removed once\n")
+	}
+	compositor.Render(leds, 5*time.Second)
+	if leds[1] != dotstar.NewColour(0, 0, 100, 255) {
+		t.Errorf("Got %v\n", leds[1])
+	}
+}
+
+func TestLevelMeter(t *testing.T) {
+	meter := &LevelMeter{}
+	leds := make([]dotstar.Colour, 10)
+	meter.Set(0.9)
+	meter.Render(leds, 0)
+	if leds[0] != dotstar.Green || leds[6] != yellow || leds[8] != yellow || leds[9] != dotstar.Off {
+		t.Errorf("Got %v\n", leds)
+	}
+
+	// The level falls but the peak is held.
+	meter.Set(0.2)
+	meter.Render(leds, 500*time.Millisecond)
+	if level, peak := meter.Level(); level != 0.2 || peak != 0.9 {
+		t.Errorf("Got level %v peak %v\n", level, peak)
+	}
+	if leds[1] != dotstar.Green || leds[2] != dotstar.Off || leds[8] != yellow {
+		t.Errorf("Got %v\n", leds)
+	}
+
+	// After the hold the peak falls back to the level.
+	meter.Render(leds, 2*time.Second)
+	if level, peak := meter.Level(); level != 0.2 || peak != 0.2 {
+		t.Errorf("Got level %v peak %v\n", level, peak)
+	}
+
+	meter.Set(2)
+	meter.Render(leds, 3*time.Second)
+	if leds[9] != dotstar.Red {
+		t.Errorf("Got %v expected Red\n", leds[9])
+	}
+}
@@ -0,0 +1,99 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"math/rand"
+	"time"
+)
+
+/*
+FireEffect simulates flames rising from the start of the strip, using the heat diffusion model from FastLED's
+Fire2012.
+
+Each frame every LED cools a little, heat drifts up the strip and new sparks are randomly added near the start.
+The heat of each LED is then mapped through the Palette.  Use a Segment to run a fire on part of the strip.
+*/
+type FireEffect struct {
+	// Cooling controls how quickly the flames cool as they rise, 20 to 100 works well.  Higher values give
+	// shorter flames.
+	Cooling uint8
+	// Sparking is the chance out of 255 of a new spark each frame.  Higher values give a roaring fire.
+	Sparking uint8
+	// Palette maps heat onto colour.  If nil, dotstar.HeatPalette is used.
+	Palette dotstar.Palette
+	// Reverse makes the flames rise from the end of the strip instead.
+	Reverse bool
+	// Rand is the source of randomness.  If nil, a source seeded from the time is created.
+	Rand *rand.Rand
+
+	heat []uint8
+}
+
+/*
+NewFireEffect creates a FireEffect using Fire2012's default cooling of 55 and sparking of 120.
+*/
+func NewFireEffect() *FireEffect {
+	return &FireEffect{Cooling: 55, Sparking: 120}
+}
+
+/*
+Render advances the fire by one frame.
+*/
+func (f *FireEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	if f.Rand == nil {
+		f.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if len(f.heat) != len(leds) {
+		f.heat = make([]uint8, len(leds))
+	}
+	count := len(f.heat)
+	if count == 0 {
+		return
+	}
+
+	// Cool down every cell a little.
+	maxCooling := int(f.Cooling)*10/count + 2
+	for i, heat := range f.heat {
+		cooling := f.Rand.Intn(maxCooling + 1)
+		if int(heat) < cooling {
+			f.heat[i] = 0
+		} else {
+			f.heat[i] = heat - uint8(cooling)
+		}
+	}
+
+	// Heat drifts up and diffuses a little.
+	for k := count - 1; k >= 2; k-- {
+		f.heat[k] = uint8((int(f.heat[k-1]) + 2*int(f.heat[k-2])) / 3)
+	}
+
+	// Randomly ignite new sparks of heat near the bottom.
+	if f.Rand.Intn(256) < int(f.Sparking) {
+		y := f.Rand.Intn(minInt(7, count))
+		heat := int(f.heat[y]) + 160 + f.Rand.Intn(96)
+		if heat > 255 {
+			heat = 255
+		}
+		f.heat[y] = uint8(heat)
+	}
+
+	palette := f.Palette
+	if palette == nil {
+		palette = dotstar.HeatPalette
+	}
+	for i, heat := range f.heat {
+		position := i
+		if f.Reverse {
+			position = count - 1 - i
+		}
+		leds[position] = palette.At(float64(heat) / 255)
+	}
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+)
+
+// ChristmasPalette is red, green, gold and warm white.
+var ChristmasPalette = dotstar.Palette{
+	dotstar.Red, dotstar.NewColour(0, 160, 0, 255), dotstar.NewColour(255, 180, 0, 255), dotstar.NewColour(255, 220, 160, 255),
+}
+
+// HalloweenPalette moves between orange, purple and darkness.
+var HalloweenPalette = dotstar.Palette{
+	dotstar.NewColour(0, 0, 0, 255), dotstar.NewColour(255, 80, 0, 255), dotstar.NewColour(0, 0, 0, 255),
+	dotstar.NewColour(100, 0, 160, 255), dotstar.NewColour(255, 120, 0, 255), dotstar.NewColour(0, 0, 0, 255),
+}
+
+// DiwaliPalette is the gold, saffron, magenta and deep red of diyas and rangoli.
+var DiwaliPalette = dotstar.Palette{
+	dotstar.NewColour(255, 180, 0, 255), dotstar.NewColour(255, 100, 0, 255), dotstar.NewColour(230, 0, 120, 255),
+	dotstar.NewColour(180, 0, 0, 255),
+}
+
+/*
+ChristmasTwinkle returns fairy lights twinkling in the colours of the ChristmasPalette.
+*/
+func ChristmasTwinkle() Effect {
+	return NewTwinkleEffect(ChristmasPalette)
+}
+
+/*
+ChristmasChase returns bands of red, green and white chasing along the strip.
+*/
+func ChristmasChase() Effect {
+	return &ChaseEffect{Colours: []dotstar.Colour{dotstar.Red, dotstar.NewColour(0, 160, 0, 255), dotstar.White}, Width: 3, Speed: 6}
+}
+
+/*
+HalloweenCreep returns slowly creeping patches of orange and purple through the dark, with the odd flicker of
+orange.
+*/
+func HalloweenCreep() Effect {
+	sparks := NewTwinkleEffect(dotstar.Palette{dotstar.NewColour(255, 80, 0, 255)})
+	sparks.Density = 0.005
+	sparks.Decay = 48
+	return NewCompositor(
+		&Layer{Effect: &NoiseEffect{Palette: HalloweenPalette, Scale: 0.08, Speed: 0.15}},
+		&Layer{Effect: sparks, Mode: BlendLighten},
+	)
+}
+
+/*
+DiwaliTwinkle returns dense, slowly fading twinkles of gold, saffron and magenta over a warm glow, like rows of
+diyas.
+*/
+func DiwaliTwinkle() Effect {
+	twinkle := NewTwinkleEffect(DiwaliPalette)
+	twinkle.Density = 0.05
+	twinkle.Decay = 8
+	twinkle.Background = dotstar.NewColour(40, 15, 0, 255)
+	return twinkle
+}
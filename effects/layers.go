@@ -0,0 +1,168 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"sync"
+	"time"
+)
+
+/*
+A BlendMode sets how a Layer is combined with the layers below it.
+*/
+type BlendMode int
+
+const (
+	// BlendOverwrite replaces the pixels below with every pixel the layer lights.
+	BlendOverwrite BlendMode = iota
+	// BlendAdd adds the layer to the pixels below, saturating at full brightness.
+	BlendAdd
+	// BlendAlpha mixes the pixels the layer lights with those below by the layer's Opacity.
+	BlendAlpha
+	// BlendMultiply darkens the pixels below, see dotstar.Colour.Multiply.
+	BlendMultiply
+	// BlendScreen lightens the pixels below, see dotstar.Colour.Screen.
+	BlendScreen
+	// BlendLighten keeps the brighter of each channel, see dotstar.Colour.Lighten.
+	BlendLighten
+)
+
+/*
+A Layer is an Effect drawn by a Compositor into its own buffer, then blended onto the layers below it.
+
+Pixels the effect leaves with a luminosity of 0, such as Off, are transparent for BlendOverwrite and BlendAlpha.
+*/
+type Layer struct {
+	Effect Effect
+	Mode   BlendMode
+	// Opacity is used by BlendAlpha, from 0 (invisible) to 1 (the same as BlendOverwrite).
+	Opacity float64
+	// Blend, if set, is used instead of Mode to combine each pixel with the pixel below.
+	Blend dotstar.BlendFunc
+
+	buffer []dotstar.Colour
+	// started is set once the layer has been drawn, with start the compositor time it was first drawn at.
+	started bool
+	start   time.Duration
+}
+
+/*
+A Compositor is an Effect that draws a stack of layers and flattens them into a single frame.
+
+Layers can be added and removed from other goroutines while the Compositor is running in an Animator, for example
+to put a notification over a background effect.  Each layer keeps its own buffer, so effects that build on the
+previous frame are not disturbed by the layers around them.
+*/
+type Compositor struct {
+	lock   sync.Mutex
+	layers []*Layer
+}
+
+/*
+NewCompositor creates a Compositor drawing layers, the first at the bottom.
+*/
+func NewCompositor(layers ...*Layer) *Compositor {
+	return &Compositor{layers: append([]*Layer(nil), layers...)}
+}
+
+/*
+AddLayer adds a layer on top of the existing layers.
+
+The time passed to the layer's effect starts from zero on the first frame it is drawn in.
+*/
+func (c *Compositor) AddLayer(l *Layer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	l.started = false
+	c.layers = append(c.layers, l)
+}
+
+/*
+RemoveLayer removes a layer, returning false if it was not in the Compositor.
+*/
+func (c *Compositor) RemoveLayer(l *Layer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, layer := range c.layers {
+		if layer == l {
+			c.layers = append(c.layers[:i], c.layers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+/*
+SetOpacity changes the opacity of a layer while the Compositor is running.
+*/
+func (c *Compositor) SetOpacity(l *Layer, opacity float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	l.Opacity = opacity
+}
+
+/*
+Len returns the number of layers.
+*/
+func (c *Compositor) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.layers)
+}
+
+/*
+Render draws every layer into its buffer and blends them, bottom first, into leds.
+
+LEDs not lit by any layer are set to Off.
+*/
+func (c *Compositor) Render(leds []dotstar.Colour, t time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i := range leds {
+		leds[i] = dotstar.Off
+	}
+	for _, l := range c.layers {
+		if len(l.buffer) != len(leds) {
+			l.buffer = make([]dotstar.Colour, len(leds), len(leds))
+		}
+		if !l.started {
+			l.started, l.start = true, t
+		}
+		l.Effect.Render(l.buffer, t-l.start)
+		for i, clr := range l.buffer {
+			leds[i] = l.blend(leds[i], clr)
+		}
+	}
+}
+
+/*
+Internal method used to blend a pixel of the layer over the pixel below it.
+*/
+func (l *Layer) blend(below, above dotstar.Colour) dotstar.Colour {
+	if l.Blend != nil {
+		return l.Blend(below, above)
+	}
+	switch l.Mode {
+	case BlendAdd:
+		sum := below.Add(above)
+		if above.L > sum.L {
+			sum.L = above.L
+		}
+		return sum
+	case BlendAlpha:
+		if above.L == 0 {
+			return below
+		}
+		return below.AlphaOver(above, l.Opacity)
+	case BlendMultiply:
+		return below.Multiply(above)
+	case BlendScreen:
+		return below.Screen(above)
+	case BlendLighten:
+		return below.Lighten(above)
+	default:
+		if above.L == 0 {
+			return below
+		}
+		return above
+	}
+}
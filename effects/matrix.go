@@ -0,0 +1,113 @@
+package effects
+
+import (
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"time"
+)
+
+/*
+Clock shows the time as hours and minutes (or hours, minutes and seconds) centred on a Matrix.
+
+The default 24 hour clock needs a matrix at least 17 LEDs wide and 5 high.
+*/
+type Clock struct {
+	Matrix *dotstar.Matrix
+	Colour dotstar.Colour
+	// Seconds adds the seconds to the display.
+	Seconds bool
+	// Now returns the time to show, time.Now is used if it is nil.
+	Now func() time.Time
+}
+
+/*
+Render draws the current time.
+*/
+func (c *Clock) Render(leds []dotstar.Colour, t time.Duration) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	format := "15:04"
+	if c.Seconds {
+		format = "15:04:05"
+	}
+	drawCentred(c.Matrix, leds, now().Format(format), c.Colour)
+}
+
+/*
+Countdown counts down from Duration to zero as minutes and seconds, using the time since the animation started.
+
+Once the countdown reaches zero the display flashes Finished, which defaults to Colour.
+*/
+type Countdown struct {
+	Matrix   *dotstar.Matrix
+	Duration time.Duration
+	Colour   dotstar.Colour
+	Finished dotstar.Colour
+}
+
+/*
+Render draws the time remaining.
+*/
+func (c *Countdown) Render(leds []dotstar.Colour, t time.Duration) {
+	remaining := c.Duration - t
+	colour := c.Colour
+	if remaining <= 0 {
+		remaining = 0
+		if c.Finished != dotstar.Off {
+			colour = c.Finished
+		}
+		// Flash twice a second once finished.
+		if t/(time.Second/2)%2 == 1 {
+			colour = dotstar.Off
+		}
+	}
+	// Round up so the display reaches 0:00 as the countdown ends.
+	seconds := int((remaining + time.Second - 1) / time.Second)
+	drawCentred(c.Matrix, leds, fmt.Sprintf("%d:%02d", seconds/60, seconds%60), colour)
+}
+
+/*
+ProgressBar fills the Matrix from left to right in proportion to Progress, which ranges from 0 to 1.
+
+Unfilled columns are set to Background.
+*/
+type ProgressBar struct {
+	Matrix     *dotstar.Matrix
+	Colour     dotstar.Colour
+	Background dotstar.Colour
+	Progress   func() float64
+}
+
+/*
+Render draws the bar for the current progress.
+*/
+func (p *ProgressBar) Render(leds []dotstar.Colour, t time.Duration) {
+	filled := int(p.Progress()*float64(p.Matrix.Width()) + 0.5)
+	for y := 0; y < p.Matrix.Height(); y++ {
+		for x := 0; x < p.Matrix.Width(); x++ {
+			index := p.Matrix.Index(x, y)
+			if index >= len(leds) {
+				continue
+			}
+			if x < filled {
+				leds[index] = p.Colour
+			} else {
+				leds[index] = p.Background
+			}
+		}
+	}
+}
+
+/*
+Internal function used to clear leds and draw text in the centre of the matrix.
+*/
+func drawCentred(m *dotstar.Matrix, leds []dotstar.Colour, text string, colour dotstar.Colour) {
+	for i := range leds {
+		leds[i] = dotstar.Off
+	}
+	x := (m.Width() - dotstar.TextWidth(text)) / 2
+	y := (m.Height() - dotstar.FontHeight) / 2
+	m.DrawTextInto(leds, x, y, text, colour)
+}
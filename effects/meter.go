@@ -0,0 +1,148 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"sync"
+	"time"
+)
+
+// Default settings for a LevelMeter.
+const (
+	defaultYellowAt = 0.6
+	defaultRedAt    = 0.85
+	defaultPeakHold = time.Second
+	defaultDecay    = 1.5
+)
+
+// yellow is used for the middle zone of a LevelMeter.
+var yellow = dotstar.NewColour(255, 200, 0, 255)
+
+/*
+A LevelMeter shows a level from 0 to 1 as a bar of LEDs from the start of the strip, with green, yellow and red
+zones and a peak-hold indicator.
+
+The level is given by calling Set, which may be done from another goroutine while the meter is being rendered.
+Rises are shown straight away, while falls decay smoothly.  This suits audio levels as well as dashboards such as
+CPU load or temperature.
+*/
+type LevelMeter struct {
+	// YellowAt and RedAt are the levels at which the yellow and red zones start, 0 uses 0.6 and 0.85.
+	YellowAt, RedAt float64
+	// PeakHold is how long the peak indicator is held before falling, 0 uses one second.
+	PeakHold time.Duration
+	// Decay is how much of the full scale the level and peak fall each second, 0 uses 1.5.
+	Decay float64
+
+	lock sync.Mutex
+	// target is the last level Set, level and peak the levels shown.
+	target, level, peak float64
+	// peakAt is when the peak was last raised, last the time of the previous frame.
+	peakAt, last time.Duration
+}
+
+/*
+Set changes the level shown by the meter, clamped to the range 0 to 1.
+*/
+func (m *LevelMeter) Set(level float64) {
+	if level < 0 {
+		level = 0
+	}
+	if level > 1 {
+		level = 1
+	}
+	m.lock.Lock()
+	m.target = level
+	m.lock.Unlock()
+}
+
+/*
+Level returns the level currently shown by the meter and the position of the peak indicator.
+*/
+func (m *LevelMeter) Level() (level, peak float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.level, m.peak
+}
+
+/*
+Render draws the meter across leds.
+*/
+func (m *LevelMeter) Render(leds []dotstar.Colour, t time.Duration) {
+	m.lock.Lock()
+	m.advance(t)
+	level, peak := m.level, m.peak
+	m.lock.Unlock()
+
+	if len(leds) == 0 {
+		return
+	}
+	lit := int(level*float64(len(leds)) + 0.5)
+	for i := range leds {
+		if i < lit {
+			leds[i] = m.zoneColour(float64(i) / float64(len(leds)))
+		} else {
+			leds[i] = dotstar.Off
+		}
+	}
+	if peak > 0 {
+		position := int(peak*float64(len(leds))+0.5) - 1
+		if position < 0 {
+			position = 0
+		}
+		leds[position] = m.zoneColour(float64(position) / float64(len(leds)))
+	}
+}
+
+/*
+Internal method used to move the shown level and peak towards the target at time t.
+*/
+func (m *LevelMeter) advance(t time.Duration) {
+	decay := m.Decay
+	if decay == 0 {
+		decay = defaultDecay
+	}
+	hold := m.PeakHold
+	if hold == 0 {
+		hold = defaultPeakHold
+	}
+	fall := 0.0
+	if t > m.last {
+		fall = decay * (t - m.last).Seconds()
+	}
+	m.last = t
+
+	if m.target >= m.level {
+		m.level = m.target
+	} else if m.level -= fall; m.level < m.target {
+		m.level = m.target
+	}
+
+	if m.level >= m.peak {
+		m.peak, m.peakAt = m.level, t
+	} else if t-m.peakAt > hold {
+		if m.peak -= fall; m.peak < m.level {
+			m.peak = m.level
+		}
+	}
+}
+
+/*
+Internal method used to find the colour of the zone containing level.
+*/
+func (m *LevelMeter) zoneColour(level float64) dotstar.Colour {
+	yellowAt, redAt := m.YellowAt, m.RedAt
+	if yellowAt == 0 {
+		yellowAt = defaultYellowAt
+	}
+	if redAt == 0 {
+		redAt = defaultRedAt
+	}
+	switch {
+	case level >= redAt:
+		return dotstar.Red
+	case level >= yellowAt:
+		return yellow
+	default:
+		return dotstar.Green
+	}
+}
@@ -0,0 +1,34 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/noise"
+	"time"
+)
+
+/*
+NoiseEffect colours the strip by mapping simplex noise through a Palette.
+
+With a slow moving palette such as dotstar.LavaPalette or dotstar.CloudPalette this gives organic, gently
+changing patterns.
+*/
+type NoiseEffect struct {
+	// Palette maps the noise value onto a colour.
+	Palette dotstar.Palette
+	// Scale is the distance through the noise between adjacent LEDs.  Smaller values give larger features,
+	// 0.05 is a good start.
+	Scale float64
+	// Speed is how far through the noise the pattern moves each second.
+	Speed float64
+}
+
+/*
+Render draws the noise at time t.
+*/
+func (n *NoiseEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	z := t.Seconds() * n.Speed
+	for i := range leds {
+		value := noise.Simplex2D(float64(i)*n.Scale, z)
+		leds[i] = n.Palette.At((value + 1) / 2)
+	}
+}
@@ -0,0 +1,258 @@
+package effects
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"io"
+	"time"
+)
+
+/*
+A Scene is a set of effects on segments of a strip, loaded from a JSON document with LoadScene.
+
+A scene document looks like:
+
+	{
+		"brightness": 128,
+		"palettes": {"sunset": ["#ff4500", "gold", "rgb(128, 0, 128)"]},
+		"segments": [
+			{"name": "shelf", "start": 0, "count": 30, "effect": "solid", "colour": "antiquewhite"},
+			{"name": "wall", "start": 30, "count": 60, "effect": "noise", "palette": "sunset",
+				"params": {"scale": 0.05, "speed": 0.3}}
+		]
+	}
+
+Colours are anything accepted by dotstar.ParseColour.  Palettes are either defined in the scene or one of the
+built in rainbow, heat, lava, cloud, ocean, christmas, halloween or diwali palettes.  The effects available are
+those registered with RegisterSceneEffect, which include solid, noise, fire, candle, comet, twinkle and chase.
+*/
+type Scene struct {
+	// Brightness is the global brightness to set, or -1 to leave it unchanged.
+	Brightness int
+	Segments   []SceneSegment
+	// Effect draws all of the segments, run it with an Animator.
+	Effect Effect
+}
+
+/*
+A SceneSegment holds the settings of one segment of a scene.
+*/
+type SceneSegment struct {
+	Name         string
+	Start, Count int
+	EffectName   string
+	Colour       dotstar.Colour
+	// Palette is nil unless the scene names one, leaving the effect to use its own default.
+	Palette dotstar.Palette
+	// Params holds effect specific settings, such as the scale and speed of the noise effect.
+	Params map[string]float64
+}
+
+/*
+A SceneEffectFactory creates the effect for a segment of a scene.
+*/
+type SceneEffectFactory func(segment SceneSegment) (Effect, error)
+
+// sceneEffects holds the effects that can be named in a scene.
+var sceneEffects = map[string]SceneEffectFactory{
+	"solid": func(segment SceneSegment) (Effect, error) {
+		return EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+			for i := range leds {
+				leds[i] = segment.Colour
+			}
+		}), nil
+	},
+	"noise": func(segment SceneSegment) (Effect, error) {
+		palette := segment.Palette
+		if palette == nil {
+			palette = dotstar.RainbowPalette
+		}
+		return &NoiseEffect{Palette: palette, Scale: segment.param("scale", 0.05), Speed: segment.param("speed", 0.3)}, nil
+	},
+	"fire": func(segment SceneSegment) (Effect, error) {
+		fire := NewFireEffect()
+		fire.Cooling = uint8(segment.param("cooling", float64(fire.Cooling)))
+		fire.Sparking = uint8(segment.param("sparking", float64(fire.Sparking)))
+		fire.Reverse = segment.param("reverse", 0) != 0
+		fire.Palette = segment.Palette
+		return fire, nil
+	},
+	"candle": func(segment SceneSegment) (Effect, error) {
+		candle := NewCandleEffect()
+		if segment.Colour.L != 0 {
+			candle.Colour = segment.Colour
+		}
+		candle.Intensity = segment.param("intensity", candle.Intensity)
+		return candle, nil
+	},
+	"comet": func(segment SceneSegment) (Effect, error) {
+		comet := NewCometEffect()
+		if segment.Colour.L != 0 {
+			comet.Colour = segment.Colour
+		}
+		if segment.Palette != nil {
+			comet.Palette = segment.Palette
+		}
+		comet.Speed = segment.param("speed", comet.Speed)
+		comet.Size = int(segment.param("size", float64(comet.Size)))
+		comet.Decay = uint8(segment.param("decay", float64(comet.Decay)))
+		comet.Reverse = segment.param("reverse", 0) != 0
+		return comet, nil
+	},
+	"twinkle": func(segment SceneSegment) (Effect, error) {
+		palette := segment.Palette
+		if palette == nil {
+			palette = dotstar.Palette{segment.Colour}
+		}
+		twinkle := NewTwinkleEffect(palette)
+		twinkle.Density = segment.param("density", twinkle.Density)
+		twinkle.Decay = uint8(segment.param("decay", float64(twinkle.Decay)))
+		return twinkle, nil
+	},
+	"chase": func(segment SceneSegment) (Effect, error) {
+		colours := []dotstar.Colour(segment.Palette)
+		if colours == nil {
+			colours = []dotstar.Colour{segment.Colour, dotstar.Off}
+		}
+		return &ChaseEffect{Colours: colours, Width: int(segment.param("width", 1)), Speed: segment.param("speed", 5)}, nil
+	},
+}
+
+// builtinPalettes are the palettes that can be named in a scene without defining them.
+var builtinPalettes = map[string]dotstar.Palette{
+	"rainbow": dotstar.RainbowPalette,
+	"heat":    dotstar.HeatPalette,
+	"lava":    dotstar.LavaPalette,
+	"cloud":   dotstar.CloudPalette,
+	"ocean":   dotstar.OceanPalette,
+
+	"christmas": ChristmasPalette,
+	"halloween": HalloweenPalette,
+	"diwali":    DiwaliPalette,
+}
+
+/*
+RegisterSceneEffect makes an effect available to scenes under name, replacing any existing effect of that name.
+
+This is not safe to call while scenes are being loaded.
+*/
+func RegisterSceneEffect(name string, factory SceneEffectFactory) {
+	sceneEffects[name] = factory
+}
+
+// sceneDocument is the JSON form of a scene.
+type sceneDocument struct {
+	Brightness *int                `json:"brightness"`
+	Palettes   map[string][]string `json:"palettes"`
+	Segments   []struct {
+		Name    string             `json:"name"`
+		Start   int                `json:"start"`
+		Count   int                `json:"count"`
+		Effect  string             `json:"effect"`
+		Colour  string             `json:"colour"`
+		Palette string             `json:"palette"`
+		Params  map[string]float64 `json:"params"`
+	} `json:"segments"`
+}
+
+/*
+LoadScene reads a scene from the JSON document in r.
+
+An error is returned if the document is invalid or refers to unknown colours, palettes or effects.
+*/
+func LoadScene(r io.Reader) (*Scene, error) {
+	var doc sceneDocument
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("Invalid scene: %v", err)
+	}
+
+	scene := &Scene{Brightness: -1}
+	if doc.Brightness != nil {
+		if *doc.Brightness < 0 || *doc.Brightness > 255 {
+			return nil, fmt.Errorf("Scene brightness %d must be from 0 to 255", *doc.Brightness)
+		}
+		scene.Brightness = *doc.Brightness
+	}
+
+	palettes := make(map[string]dotstar.Palette)
+	for name, palette := range builtinPalettes {
+		palettes[name] = palette
+	}
+	for name, colours := range doc.Palettes {
+		palette := make(dotstar.Palette, len(colours))
+		for i, text := range colours {
+			clr, err := dotstar.ParseColour(text)
+			if err != nil {
+				return nil, fmt.Errorf("Palette %q: %v", name, err)
+			}
+			palette[i] = clr
+		}
+		palettes[name] = palette
+	}
+
+	var effects Effects
+	for i, s := range doc.Segments {
+		segment := SceneSegment{Name: s.Name, Start: s.Start, Count: s.Count, EffectName: s.Effect, Params: s.Params}
+		if segment.Name == "" {
+			segment.Name = fmt.Sprintf("segment %d", i)
+		}
+		if segment.EffectName == "" {
+			segment.EffectName = "solid"
+		}
+		if s.Colour != "" {
+			clr, err := dotstar.ParseColour(s.Colour)
+			if err != nil {
+				return nil, fmt.Errorf("Segment %q: %v", segment.Name, err)
+			}
+			segment.Colour = clr
+		}
+		if s.Palette != "" {
+			palette, ok := palettes[s.Palette]
+			if !ok {
+				return nil, fmt.Errorf("Segment %q uses unknown palette %q", segment.Name, s.Palette)
+			}
+			segment.Palette = palette
+		}
+
+		factory, ok := sceneEffects[segment.EffectName]
+		if !ok {
+			return nil, fmt.Errorf("Segment %q uses unknown effect %q", segment.Name, segment.EffectName)
+		}
+		effect, err := factory(segment)
+		if err != nil {
+			return nil, fmt.Errorf("Segment %q: %v", segment.Name, err)
+		}
+		scene.Segments = append(scene.Segments, segment)
+		effects = append(effects, &Segment{Start: segment.Start, Count: segment.Count, Effect: effect})
+	}
+	scene.Effect = effects
+	return scene, nil
+}
+
+/*
+Apply sets the scene's brightness on ctl and shows the first frame of its effects.
+
+Run the scene's Effect with an Animator to animate it.
+*/
+func (s *Scene) Apply(ctl *dotstar.Controller) error {
+	if s.Brightness >= 0 {
+		ctl.SetGlobalBrightness(uint8(s.Brightness))
+	}
+	frame := ctl.Snapshot()
+	s.Effect.Render(frame, 0)
+	ctl.SetColours(frame)
+	return ctl.Update()
+}
+
+/*
+Internal method used to get a parameter from the segment, or def if it is not set.
+*/
+func (s SceneSegment) param(name string, def float64) float64 {
+	if value, ok := s.Params[name]; ok {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,156 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"sync"
+	"time"
+)
+
+/*
+A Sequence is an Effect that stays idle until triggered, then lights the strip step by step, holds it lit and
+fades it out again.  This is the usual pattern for stair and hallway lighting driven by a motion sensor.
+
+The strip is divided into steps of StepSize LEDs, lit one after another over Reveal.  Once every step is lit the
+strip is held for Hold, then faded back to idle over FadeOut.  Trigger can be called from any goroutine, such as a
+motion sensor bound with an input.Binder or an HTTP handler:
+
+	stairs := &effects.Sequence{Colour: dotstar.White, StepSize: 12, Reveal: 2 * time.Second,
+		Hold: 30 * time.Second, FadeOut: 3 * time.Second}
+	binder.BindButton(pir, stairs.Trigger)
+	http.HandleFunc("/motion", func(w http.ResponseWriter, r *http.Request) { stairs.Trigger() })
+	go effects.NewAnimator(strip, 30).Run(ctx, stairs)
+
+Triggering the sequence again while it is running keeps the strip lit, restarting the hold.
+*/
+type Sequence struct {
+	// Effect, if set, is drawn on the lit steps, otherwise they are lit with Colour.
+	Effect Effect
+	Colour dotstar.Colour
+	// Idle, if set, is drawn while the sequence is not running and under steps that are not yet lit, otherwise
+	// the strip is left Off.
+	Idle Effect
+	// StepSize is the number of LEDs in each step, 0 lights one LED at a time.
+	StepSize int
+	// Reveal is the time taken to light every step, Hold how long the strip is held lit and FadeOut the time
+	// taken to fade back to idle.
+	Reveal, Hold, FadeOut time.Duration
+
+	lock sync.Mutex
+	// pending is set by Trigger until the next frame, with reverse set to light the steps from the end.
+	pending, reverse bool
+	// running is set while the sequence is running, start is the time it was started.
+	running bool
+	start   time.Duration
+	lit     []dotstar.Colour
+}
+
+/*
+Trigger starts the sequence, lighting the steps from the start of the strip.
+*/
+func (s *Sequence) Trigger() {
+	s.trigger(false)
+}
+
+/*
+TriggerReverse starts the sequence, lighting the steps from the end of the strip, for example when motion is
+seen at the top of the stairs.
+*/
+func (s *Sequence) TriggerReverse() {
+	s.trigger(true)
+}
+
+/*
+Internal method used to record a trigger for the next frame.
+*/
+func (s *Sequence) trigger(reverse bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.pending && !s.running {
+		s.reverse = reverse
+	}
+	s.pending = true
+}
+
+/*
+Running returns true while the sequence is lighting, holding or fading the strip.
+*/
+func (s *Sequence) Running() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.running || s.pending
+}
+
+/*
+Render draws the sequence at time t.
+*/
+func (s *Sequence) Render(leds []dotstar.Colour, t time.Duration) {
+	s.lock.Lock()
+	if s.pending {
+		s.pending = false
+		if !s.running || t-s.start < 0 {
+			s.running, s.start = true, t
+		} else if t-s.start > s.Reveal {
+			// Keep the strip lit, restarting the hold.
+			s.start = t - s.Reveal
+		}
+	}
+	elapsed := t - s.start
+	if s.running && elapsed >= s.Reveal+s.Hold+s.FadeOut {
+		s.running = false
+	}
+	running, reverse := s.running, s.reverse
+	s.lock.Unlock()
+
+	if s.Idle != nil {
+		s.Idle.Render(leds, t)
+	} else {
+		for i := range leds {
+			leds[i] = dotstar.Off
+		}
+	}
+	if !running || len(leds) == 0 {
+		return
+	}
+
+	if len(s.lit) != len(leds) {
+		s.lit = make([]dotstar.Colour, len(leds))
+	}
+	if s.Effect != nil {
+		s.Effect.Render(s.lit, elapsed)
+	} else {
+		for i := range s.lit {
+			s.lit[i] = s.Colour
+		}
+	}
+
+	size := s.StepSize
+	if size < 1 {
+		size = 1
+	}
+	steps := (len(leds) + size - 1) / size
+	fade := 1.0
+	if out := elapsed - s.Reveal - s.Hold; out > 0 {
+		fade = 1 - float64(out)/float64(s.FadeOut)
+	}
+	for i := range leds {
+		step := i / size
+		if reverse {
+			step = steps - 1 - step
+		}
+		// Each step fades in over its share of the reveal.
+		level := 1.0
+		if s.Reveal > 0 {
+			slot := float64(s.Reveal) / float64(steps)
+			level = (float64(elapsed) - float64(step)*slot) / slot
+		}
+		if level > fade {
+			level = fade
+		}
+		below := leds[i]
+		if below.L == 0 {
+			// Fade up from black at the lit brightness rather than from Off.
+			below = dotstar.NewColourW(0, 0, 0, 0, s.lit[i].L)
+		}
+		leds[i] = below.Lerp(s.lit[i], level)
+	}
+}
@@ -0,0 +1,57 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"math/rand"
+	"time"
+)
+
+/*
+TwinkleEffect lights LEDs at random in colours picked from a Palette, each fading away after it appears, like
+fairy lights.
+*/
+type TwinkleEffect struct {
+	// Palette holds the colours of the twinkles, each is picked from its entries rather than the blends between.
+	Palette dotstar.Palette
+	// Density is the chance of each LED starting a twinkle each frame, 0.02 gives a gentle sparkle.
+	Density float64
+	// Decay is how much each twinkle fades each frame, out of 256.  Lower values give longer twinkles.
+	Decay uint8
+	// Background is shown where no twinkle is lit, Off if not set.
+	Background dotstar.Colour
+	// Rand is the source of randomness.  If nil, a source seeded from the time is created.
+	Rand *rand.Rand
+
+	twinkles []dotstar.Colour
+}
+
+/*
+NewTwinkleEffect creates a TwinkleEffect with a density of 0.02 and a decay of 16.
+*/
+func NewTwinkleEffect(palette dotstar.Palette) *TwinkleEffect {
+	return &TwinkleEffect{Palette: palette, Density: 0.02, Decay: 16}
+}
+
+/*
+Render advances the twinkles by one frame.
+*/
+func (tw *TwinkleEffect) Render(leds []dotstar.Colour, t time.Duration) {
+	if tw.Rand == nil {
+		tw.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if len(tw.twinkles) != len(leds) {
+		tw.twinkles = make([]dotstar.Colour, len(leds))
+	}
+	dotstar.FadeColours(tw.twinkles, tw.Decay)
+	for i, clr := range tw.twinkles {
+		if len(tw.Palette) > 0 && tw.Rand.Float64() < tw.Density {
+			clr = tw.Palette[tw.Rand.Intn(len(tw.Palette))]
+			tw.twinkles[i] = clr
+		}
+		if clr.R == 0 && clr.G == 0 && clr.B == 0 && clr.W == 0 {
+			leds[i] = tw.Background
+		} else {
+			leds[i] = clr
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package effects
+
+import (
+	"github.com/owlfish/dotstar"
+	"time"
+)
+
+/*
+A VirtualStrip runs an Effect on a strip of Count virtual LEDs, then stretches it across the real strip by
+interpolating between neighbouring virtual LEDs.
+
+This lets effects that are costly per LED, or written for a particular length, run smoothly on long strips, for
+example rendering 30 virtual LEDs across 300 real ones.  If the real strip is shorter than Count the virtual LEDs are
+sampled instead.  VirtualStrips are themselves Effects, so can be used in a Segment.
+*/
+type VirtualStrip struct {
+	Count  int
+	Effect Effect
+
+	buffer []dotstar.Colour
+}
+
+/*
+NewVirtualStrip creates a VirtualStrip running effect on virtualCount virtual LEDs.
+*/
+func NewVirtualStrip(virtualCount int, effect Effect) *VirtualStrip {
+	return &VirtualStrip{Count: virtualCount, Effect: effect}
+}
+
+/*
+Render draws the effect at time t onto the virtual LEDs and interpolates them onto leds.
+*/
+func (v *VirtualStrip) Render(leds []dotstar.Colour, t time.Duration) {
+	if v.Count < 1 || len(leds) == 0 {
+		return
+	}
+	if len(v.buffer) != v.Count {
+		v.buffer = make([]dotstar.Colour, v.Count)
+	}
+	v.Effect.Render(v.buffer, t)
+	resample(leds, v.buffer)
+}
+
+/*
+Internal function used to stretch or shrink src onto dst, linearly interpolating between the colours of src so
+that the first and last LEDs of each line up.
+*/
+func resample(dst, src []dotstar.Colour) {
+	if len(src) == 1 || len(dst) == 1 {
+		for i := range dst {
+			dst[i] = src[0]
+		}
+		return
+	}
+	step := float64(len(src)-1) / float64(len(dst)-1)
+	for i := range dst {
+		position := float64(i) * step
+		index := int(position)
+		if index >= len(src)-1 {
+			dst[i] = src[len(src)-1]
+			continue
+		}
+		dst[i] = src[index].Lerp(src[index+1], position-float64(index))
+	}
+}
@@ -0,0 +1,167 @@
+/*
+The input package binds buttons and keys to actions, for building standalone appliances without writing an event
+loop.
+
+A Binder waits for button presses and key strokes and runs the bound Action for each one, in turn, on the goroutine
+that called Run.  A Player runs a list of effects on a strip and provides actions to change between them, step the
+brightness and toggle the power:
+
+	player := input.NewPlayer(strip, 30, rainbow, fire, &effects.NoiseEffect{Palette: dotstar.OceanPalette})
+	button, _ := periph.NewButton("GPIO17")
+	binder := &input.Binder{}
+	binder.BindButton(button, player.Next)
+	binder.BindKey('+', player.BrightnessUp)
+	binder.BindKey('-', player.BrightnessDown)
+	binder.BindKey('p', player.TogglePower)
+	go binder.Run(ctx, os.Stdin)
+	player.Run(ctx)
+
+Buttons on GPIO pins are provided by the periph package.  Keys are read from an io.Reader, a terminal in its
+normal mode only passes key strokes on once Enter is pressed.
+*/
+package input
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"time"
+)
+
+/*
+An Action is run in response to an input.
+*/
+type Action func()
+
+/*
+A Button is an input that can be pressed, such as a push button on a GPIO pin.
+*/
+type Button interface {
+	// WaitForPress blocks until the button is pressed, returning false if timeout passes first.
+	WaitForPress(timeout time.Duration) bool
+}
+
+// defaultDebounce is the time after a press during which further presses of the same button are ignored.
+const defaultDebounce = 200 * time.Millisecond
+
+// buttonPoll is how long a wait for a button press lasts before checking whether Run has finished.
+const buttonPoll = 100 * time.Millisecond
+
+/*
+A Binder maps buttons and keys to actions.  The zero value has no bindings and is ready to use.
+
+Bindings must be made before calling Run.
+*/
+type Binder struct {
+	// Debounce is the time after a press during which further presses of the same button are ignored.
+	// 0 uses 200ms.
+	Debounce time.Duration
+
+	buttons []buttonBinding
+	keys    map[rune]Action
+}
+
+// buttonBinding holds the action for a button.
+type buttonBinding struct {
+	button Button
+	action Action
+}
+
+/*
+BindButton runs action each time button is pressed.
+*/
+func (b *Binder) BindButton(button Button, action Action) {
+	b.buttons = append(b.buttons, buttonBinding{button: button, action: action})
+}
+
+/*
+BindKey runs action each time key is read from the keyboard passed to Run.
+*/
+func (b *Binder) BindKey(key rune, action Action) {
+	if b.keys == nil {
+		b.keys = make(map[rune]Action)
+	}
+	b.keys[key] = action
+}
+
+/*
+Run waits for inputs and runs the bound actions until ctx is done, returning the context error.
+
+Keys are read from keyboard, which may be nil if no keys are bound.  Reading stops at the end of keyboard, while
+buttons continue to be watched.  Actions are run one at a time on the calling goroutine.
+
+A read from keyboard cannot be interrupted, so if keyboard implements io.Closer, such as os.Stdin, it is closed
+when Run returns to stop the goroutine reading it.  Other readers must reach their end, or the goroutine stays
+blocked until the next key.
+*/
+func (b *Binder) Run(ctx context.Context, keyboard io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	actions := make(chan Action)
+
+	for _, binding := range b.buttons {
+		go b.watchButton(ctx, binding, actions)
+	}
+	if keyboard != nil && len(b.keys) > 0 {
+		go b.readKeys(ctx, keyboard, actions)
+		if closer, ok := keyboard.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case action := <-actions:
+			action()
+		}
+	}
+}
+
+/*
+Internal method used to send the action of a button each time it is pressed, until ctx is done.
+*/
+func (b *Binder) watchButton(ctx context.Context, binding buttonBinding, actions chan<- Action) {
+	debounce := b.Debounce
+	if debounce == 0 {
+		debounce = defaultDebounce
+	}
+	var last time.Time
+	for ctx.Err() == nil {
+		if !binding.button.WaitForPress(buttonPoll) {
+			continue
+		}
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) < debounce {
+			continue
+		}
+		last = now
+		select {
+		case actions <- binding.action:
+		case <-ctx.Done():
+		}
+	}
+}
+
+/*
+Internal method used to send the action of each bound key read from keyboard.
+*/
+func (b *Binder) readKeys(ctx context.Context, keyboard io.Reader, actions chan<- Action) {
+	reader := bufio.NewReader(keyboard)
+	for {
+		key, _, err := reader.ReadRune()
+		if err != nil {
+			return
+		}
+		action, ok := b.keys[key]
+		if !ok {
+			continue
+		}
+		select {
+		case actions <- action:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
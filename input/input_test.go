@@ -0,0 +1,142 @@
+package input
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeButton is pressed by sending on presses.
+type fakeButton struct {
+	presses chan bool
+}
+
+func (b *fakeButton) WaitForPress(timeout time.Duration) bool {
+	select {
+	case <-b.presses:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestBinder(t *testing.T) {
+	button := &fakeButton{presses: make(chan bool)}
+	got := make(chan string, 10)
+	binder := &Binder{Debounce: time.Nanosecond}
+	binder.BindButton(button, func() { got <- "button" })
+	binder.BindKey('+', func() { got <- "up" })
+	binder.BindKey('-', func() { got <- "down" })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- binder.Run(ctx, strings.NewReader("+x-")) }()
+
+	for _, expected := range []string{"up", "down"} {
+		if action := <-got; action != expected {
+			t.Errorf("Got %v expected %v\n", action, expected)
+		}
+	}
+	button.presses <- true
+	if action := <-got; action != "button" {
+		t.Errorf("Got %v expected button\n", action)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Got %v expected %v\n", err, context.Canceled)
+	}
+}
+
+func TestBinderClosesKeyboard(t *testing.T) {
+	binder := &Binder{}
+	binder.BindKey('+', func() {})
+	// A pipe with no input blocks the key reader until the pipe is closed.
+	r, w := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := binder.Run(ctx, r); err != context.DeadlineExceeded {
+		t.Errorf("Got %v expected %v\n", err, context.DeadlineExceeded)
+	}
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("+"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != io.ErrClosedPipe {
+			t.Errorf("Got %v expected %v\n", err, io.ErrClosedPipe)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Keyboard was not closed when Run returned\n")
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 2)
+	solid := func(c dotstar.Colour) effects.Effect {
+		return effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+			for i := range leds {
+				leds[i] = c
+			}
+		})
+	}
+	player := NewPlayer(strip, 30, solid(dotstar.Red), solid(dotstar.Blue))
+	ctx := context.Background()
+
+	player.frame(ctx, 0)
+	if strip.GetColour(0) != dotstar.Red {
+		t.Errorf("Got %v expected Red\n", strip.GetColour(0))
+	}
+	player.Next()
+	player.BrightnessDown()
+	player.frame(ctx, time.Second)
+	if strip.GetColour(0) != dotstar.Blue || strip.GetGlobalBrightness() != 255-brightnessStep {
+		t.Errorf("Got %v at %v\n", strip.GetColour(0), strip.GetGlobalBrightness())
+	}
+
+	player.TogglePower()
+	player.frame(ctx, 2*time.Second)
+	if strip.GetColour(0) != dotstar.Off {
+		t.Errorf("Got %v expected Off\n", strip.GetColour(0))
+	}
+	player.Previous()
+	player.BrightnessUp()
+	player.BrightnessUp()
+	player.frame(ctx, 3*time.Second)
+	if strip.GetColour(0) != dotstar.Red || strip.GetGlobalBrightness() != 255 {
+		t.Errorf("Got %v at %v\n", strip.GetColour(0), strip.GetGlobalBrightness())
+	}
+}
+
+func TestPlayerNotRunning(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 2)
+	player := NewPlayer(strip, 30)
+
+	// Actions must not block while Run is not taking them.
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			player.TogglePower()
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Actions blocked while the Player was not running")
+	}
+
+	player.BrightnessDown()
+	player.frame(context.Background(), 0)
+	if strip.GetGlobalBrightness() != 255-brightnessStep || !player.on {
+		t.Errorf("Got %v with power %v\n", strip.GetGlobalBrightness(), player.on)
+	}
+}
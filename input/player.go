@@ -0,0 +1,174 @@
+package input
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"sync"
+	"time"
+)
+
+// brightnessStep is the change in global brightness made by BrightnessUp and BrightnessDown.
+const brightnessStep = 32
+
+// minBrightness is the lowest global brightness BrightnessDown goes to, keeping the strip visible.
+const minBrightness = 8
+
+/*
+A Player runs a list of effects on a strip, one at a time, with actions to control it.
+
+The actions are safe to call from any goroutine, for example from a Binder, and take effect on the next frame.
+They never block, so may be called before Run starts or after it returns.
+*/
+type Player struct {
+	ctl      *dotstar.Controller
+	animator *effects.Animator
+	fps      int
+	effects  []effects.Effect
+	// current is the index of the effect being shown, on is false when the power is off.
+	current int
+	on      bool
+	// start is the animation time at which the current effect started, restart is set to reset it on the next frame.
+	start   time.Duration
+	restart bool
+	// pending holds the actions waiting for the next frame, guarded by mu.
+	mu      sync.Mutex
+	pending []func()
+}
+
+/*
+NewPlayer creates a Player showing effects on ctl at fps frames a second, starting with the first effect.
+*/
+func NewPlayer(ctl *dotstar.Controller, fps int, list ...effects.Effect) *Player {
+	if fps <= 0 {
+		fps = 30
+	}
+	return &Player{
+		ctl:      ctl,
+		animator: effects.NewAnimator(ctl, fps),
+		fps:      fps,
+		effects:  list,
+		on:       true,
+	}
+}
+
+/*
+Run shows the effects until ctx is done or an Update() fails.
+
+The context error is returned when ctx is done.
+*/
+func (p *Player) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Second / time.Duration(p.fps))
+	defer ticker.Stop()
+
+	begin := time.Now()
+	for {
+		t := time.Since(begin)
+		if err := p.frame(ctx, t); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+Internal method used to apply any pending actions and render the frame at time t.
+*/
+func (p *Player) frame(ctx context.Context, t time.Duration) error {
+	p.mu.Lock()
+	actions := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+	for _, action := range actions {
+		action()
+	}
+	changed := len(actions) > 0
+	if p.restart {
+		p.start, p.restart = t, false
+	}
+
+	if !p.on || len(p.effects) == 0 {
+		if changed {
+			return p.ctl.UpdateContext(ctx)
+		}
+		return nil
+	}
+	return p.animator.RenderFrame(ctx, p.effects[p.current], t-p.start)
+}
+
+/*
+Internal method used to queue a change to be made by Run before the next frame.
+*/
+func (p *Player) queue(change func()) {
+	p.mu.Lock()
+	p.pending = append(p.pending, change)
+	p.mu.Unlock()
+}
+
+/*
+Next changes to the next effect, returning to the first after the last.  The power is switched on if it was off.
+*/
+func (p *Player) Next() {
+	p.queue(func() {
+		if len(p.effects) > 0 {
+			p.current = (p.current + 1) % len(p.effects)
+		}
+		p.on = true
+		p.restart = true
+	})
+}
+
+/*
+Previous changes to the previous effect, going to the last from the first.
+*/
+func (p *Player) Previous() {
+	p.queue(func() {
+		if len(p.effects) > 0 {
+			p.current = (p.current + len(p.effects) - 1) % len(p.effects)
+		}
+		p.on = true
+		p.restart = true
+	})
+}
+
+/*
+BrightnessUp raises the global brightness of the strip by one step.
+*/
+func (p *Player) BrightnessUp() {
+	p.queue(func() {
+		level := int(p.ctl.GetGlobalBrightness()) + brightnessStep
+		if level > 255 {
+			level = 255
+		}
+		p.ctl.SetGlobalBrightness(uint8(level))
+	})
+}
+
+/*
+BrightnessDown lowers the global brightness of the strip by one step, stopping while the strip is still lit.
+*/
+func (p *Player) BrightnessDown() {
+	p.queue(func() {
+		level := int(p.ctl.GetGlobalBrightness()) - brightnessStep
+		if level < minBrightness {
+			level = minBrightness
+		}
+		p.ctl.SetGlobalBrightness(uint8(level))
+	})
+}
+
+/*
+TogglePower switches the strip off, or back on to the effect that was showing.
+*/
+func (p *Player) TogglePower() {
+	p.queue(func() {
+		p.on = !p.on
+		if !p.on {
+			p.ctl.Fill(dotstar.Off)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// defaultDiscoveryPrefix is the topic prefix Home Assistant uses for discovery unless configured otherwise.
+const defaultDiscoveryPrefix = "homeassistant"
+
+// Payloads published to the availability topic.
+const (
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+/*
+A Discovery describes the light to Home Assistant, so that it is added automatically through MQTT discovery
+without any YAML configuration.
+*/
+type Discovery struct {
+	// Prefix is the Home Assistant discovery prefix, "" uses "homeassistant".
+	Prefix string
+	// UniqueID identifies the light in Home Assistant and forms part of the discovery topic.  It is required.
+	UniqueID string
+	// Name is the name shown in Home Assistant.
+	Name string
+	// Effects lists the effects offered in Home Assistant, each is passed to Config.Effect when selected.
+	Effects []string
+	// AvailabilityTopic, if set, has "online" published to it, retained, when the light is announced and
+	// "offline" published by Offline.  The MQTT client's last will should publish "offline" to it as well.
+	AvailabilityTopic string
+	// Device, if set, groups the light under a device in Home Assistant.
+	Device *Device
+}
+
+/*
+A Device describes the device the light belongs to in Home Assistant.
+*/
+type Device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+// discoveryConfig is the JSON published to the discovery topic.
+type discoveryConfig struct {
+	Schema              string   `json:"schema"`
+	Name                string   `json:"name,omitempty"`
+	UniqueID            string   `json:"unique_id"`
+	CommandTopic        string   `json:"command_topic"`
+	StateTopic          string   `json:"state_topic,omitempty"`
+	Brightness          bool     `json:"brightness"`
+	SupportedColorModes []string `json:"supported_color_modes"`
+	Effect              bool     `json:"effect"`
+	EffectList          []string `json:"effect_list,omitempty"`
+	AvailabilityTopic   string   `json:"availability_topic,omitempty"`
+	PayloadAvailable    string   `json:"payload_available,omitempty"`
+	PayloadNotAvailable string   `json:"payload_not_available,omitempty"`
+	Device              *Device  `json:"device,omitempty"`
+}
+
+/*
+Topic returns the topic the discovery config is published to.
+*/
+func (d Discovery) Topic() string {
+	return d.prefix() + "/light/" + d.UniqueID + "/config"
+}
+
+/*
+Internal method used to get the discovery prefix.
+*/
+func (d Discovery) prefix() string {
+	if d.Prefix == "" {
+		return defaultDiscoveryPrefix
+	}
+	return d.Prefix
+}
+
+/*
+Announce publishes the discovery config for the light, retained, and marks it as available.
+
+The config is published again whenever Home Assistant reports that it has come online, so the light reappears
+after Home Assistant restarts.
+*/
+func (b *Bridge) Announce(d Discovery) error {
+	if d.UniqueID == "" {
+		return errors.New("A unique ID is required for discovery")
+	}
+	b.mu.Lock()
+	b.discovery = &d
+	b.mu.Unlock()
+
+	err := b.client.Subscribe(d.prefix()+"/status", func(topic string, payload []byte) {
+		if string(payload) == payloadOnline {
+			b.publishDiscovery()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return b.publishDiscovery()
+}
+
+/*
+Offline marks the light as unavailable in Home Assistant, for use when shutting down.
+*/
+func (b *Bridge) Offline() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.discovery == nil || b.discovery.AvailabilityTopic == "" {
+		return nil
+	}
+	return b.client.Publish(b.discovery.AvailabilityTopic, true, []byte(payloadOffline))
+}
+
+/*
+Internal method used to publish the discovery config, the availability and the current state.
+*/
+func (b *Bridge) publishDiscovery() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	d := b.discovery
+	cfg := discoveryConfig{
+		Schema:              "json",
+		Name:                d.Name,
+		UniqueID:            d.UniqueID,
+		CommandTopic:        b.cfg.CommandTopic,
+		StateTopic:          b.cfg.StateTopic,
+		Brightness:          true,
+		SupportedColorModes: []string{"rgb"},
+		Effect:              len(d.Effects) > 0,
+		EffectList:          d.Effects,
+		Device:              d.Device,
+	}
+	if d.AvailabilityTopic != "" {
+		cfg.AvailabilityTopic = d.AvailabilityTopic
+		cfg.PayloadAvailable = payloadOnline
+		cfg.PayloadNotAvailable = payloadOffline
+	}
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := b.client.Publish(d.Topic(), true, payload); err != nil {
+		return err
+	}
+	if d.AvailabilityTopic != "" {
+		if err := b.client.Publish(d.AvailabilityTopic, true, []byte(payloadOnline)); err != nil {
+			return err
+		}
+	}
+	return b.publishState()
+}
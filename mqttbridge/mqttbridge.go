@@ -0,0 +1,191 @@
+/*
+The mqttbridge package controls a Dotstar strip from MQTT messages using the Home Assistant MQTT Light JSON schema.
+
+The bridge works with any MQTT client through the Client interface.  For the Eclipse Paho client an adapter is:
+
+	type pahoClient struct{ mqtt.Client }
+
+	func (c pahoClient) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+		token := c.Client.Subscribe(topic, 0, func(_ mqtt.Client, m mqtt.Message) { handler(m.Topic(), m.Payload()) })
+		token.Wait()
+		return token.Error()
+	}
+
+	func (c pahoClient) Publish(topic string, retained bool, payload []byte) error {
+		token := c.Client.Publish(topic, 0, retained, payload)
+		token.Wait()
+		return token.Error()
+	}
+
+Calling Announce on a Bridge adds the strip to Home Assistant through MQTT discovery, with its brightness, colour
+and effects, so no YAML configuration is needed.
+*/
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"sync"
+)
+
+/*
+A Client is the subset of an MQTT client used by the Bridge.
+*/
+type Client interface {
+	// Subscribe calls handler for every message received on topic.
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+	// Publish sends payload to topic.
+	Publish(topic string, retained bool, payload []byte) error
+}
+
+/*
+A Config holds the topics and effect handling for a Bridge.
+*/
+type Config struct {
+	// CommandTopic receives JSON commands, for example "home/strip/set".
+	CommandTopic string
+	// StateTopic has the current state published to it after each command, retained.  It may be empty.
+	StateTopic string
+	// Effect is called when a command selects an effect.  It may be nil if no effects are supported.
+	Effect func(name string) error
+}
+
+/*
+A Bridge applies MQTT commands to a Controller.
+
+Colour commands set every LED to the colour.  Turning the light off sets the global brightness to 0, leaving
+the colours in place for when it is turned back on.  The Controller must not be used elsewhere while the
+Bridge is running, except from the Effect function.
+*/
+type Bridge struct {
+	mu     sync.Mutex
+	ctl    *dotstar.Controller
+	client Client
+	cfg    Config
+	state  State
+	// discovery is set once the light has been announced to Home Assistant.
+	discovery *Discovery
+}
+
+/*
+State is the JSON schema used for commands and state in Home Assistant.
+
+Fields missing from a command are left unchanged.
+*/
+type State struct {
+	State      string `json:"state,omitempty"`
+	Brightness *uint8 `json:"brightness,omitempty"`
+	ColorMode  string `json:"color_mode,omitempty"`
+	Color      *RGB   `json:"color,omitempty"`
+	Effect     string `json:"effect,omitempty"`
+}
+
+/*
+RGB is a colour in the Home Assistant JSON schema.
+*/
+type RGB struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+/*
+New creates a Bridge, publishes the initial state and subscribes to the command topic.
+*/
+func New(ctl *dotstar.Controller, client Client, cfg Config) (*Bridge, error) {
+	if cfg.CommandTopic == "" {
+		return nil, errors.New("A command topic is required")
+	}
+	brightness := ctl.GetGlobalBrightness()
+	// Colour commands fill the strip, so the first LED gives the colour being shown.
+	colour := &RGB{}
+	if clrs := ctl.Snapshot(); len(clrs) > 0 {
+		colour = &RGB{R: clrs[0].R, G: clrs[0].G, B: clrs[0].B}
+	}
+	b := &Bridge{
+		ctl:    ctl,
+		client: client,
+		cfg:    cfg,
+		state: State{
+			State:      "ON",
+			Brightness: &brightness,
+			ColorMode:  "rgb",
+			Color:      colour,
+		},
+	}
+	// Publish before subscribing, so that the first state cannot overwrite the result of a command.
+	b.mu.Lock()
+	err := b.publishState()
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Subscribe(cfg.CommandTopic, b.handleMessage); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+/*
+Internal method used as the MQTT message handler.  Errors are not reported as there is no caller to return them to.
+*/
+func (b *Bridge) handleMessage(topic string, payload []byte) {
+	b.HandleCommand(payload)
+}
+
+/*
+HandleCommand applies a JSON command, updates the strip and publishes the new state.
+*/
+func (b *Bridge) HandleCommand(payload []byte) error {
+	var cmd State
+	if err := json.Unmarshal(payload, &cmd); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cmd.State == "ON" || cmd.State == "OFF" {
+		b.state.State = cmd.State
+	}
+	if cmd.Brightness != nil {
+		brightness := *cmd.Brightness
+		b.state.Brightness = &brightness
+	}
+	if cmd.Color != nil {
+		clr := *cmd.Color
+		b.state.Color = &clr
+		b.ctl.Fill(dotstar.NewColour(clr.R, clr.G, clr.B, 255))
+	}
+	if cmd.Effect != "" && b.cfg.Effect != nil {
+		if err := b.cfg.Effect(cmd.Effect); err != nil {
+			return err
+		}
+		b.state.Effect = cmd.Effect
+	}
+
+	if b.state.State == "OFF" {
+		b.ctl.SetGlobalBrightness(0)
+	} else {
+		b.ctl.SetGlobalBrightness(*b.state.Brightness)
+	}
+	if err := b.ctl.Update(); err != nil {
+		return err
+	}
+	return b.publishState()
+}
+
+/*
+Internal method used to publish the current state, b.mu must be held.
+*/
+func (b *Bridge) publishState() error {
+	if b.cfg.StateTopic == "" {
+		return nil
+	}
+	payload, err := json.Marshal(b.state)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(b.cfg.StateTopic, true, payload)
+}
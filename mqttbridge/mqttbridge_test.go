@@ -0,0 +1,126 @@
+package mqttbridge
+
+import (
+	"errors"
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"testing"
+)
+
+// fakeClient records subscriptions and published messages.
+type fakeClient struct {
+	handlers  map[string]func(topic string, payload []byte)
+	published map[string]string
+	// publishErr, if set, is returned by Publish.
+	publishErr error
+}
+
+func (fc *fakeClient) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+	fc.handlers[topic] = handler
+	return nil
+}
+
+func (fc *fakeClient) Publish(topic string, retained bool, payload []byte) error {
+	if fc.publishErr != nil {
+		return fc.publishErr
+	}
+	fc.published[topic] = string(payload)
+	return nil
+}
+
+func TestBridgeCommands(t *testing.T) {
+	client := &fakeClient{handlers: map[string]func(string, []byte){}, published: map[string]string{}}
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	var effect string
+	_, err := New(strip, client, Config{
+		CommandTopic: "strip/set",
+		StateTopic:   "strip/state",
+		Effect:       func(name string) error { effect = name; return nil },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+
+	client.handlers["strip/set"]("strip/set", []byte(`{"state":"ON","brightness":128,"color":{"r":255,"g":0,"b":0},"effect":"fire"}`))
+	if strip.GetColour(2) != dotstar.Red || strip.GetGlobalBrightness() != 128 || effect != "fire" {
+		t.Errorf("Got colour %v brightness %d effect %q\n", strip.GetColour(2), strip.GetGlobalBrightness(), effect)
+	}
+	expected := `{"state":"ON","brightness":128,"color_mode":"rgb","color":{"r":255,"g":0,"b":0},"effect":"fire"}`
+	if client.published["strip/state"] != expected {
+		t.Errorf("Got state %s expected %s\n", client.published["strip/state"], expected)
+	}
+
+	client.handlers["strip/set"]("strip/set", []byte(`{"state":"OFF"}`))
+	if strip.GetGlobalBrightness() != 0 || strip.GetColour(0) != dotstar.Red {
+		t.Errorf("Got colour %v brightness %d after off\n", strip.GetColour(0), strip.GetGlobalBrightness())
+	}
+	client.handlers["strip/set"]("strip/set", []byte(`{"state":"ON"}`))
+	if strip.GetGlobalBrightness() != 128 {
+		t.Errorf("Got brightness %d after on\n", strip.GetGlobalBrightness())
+	}
+}
+
+func TestNewInitialState(t *testing.T) {
+	client := &fakeClient{handlers: map[string]func(string, []byte){}, published: map[string]string{}}
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	strip.Fill(dotstar.Green)
+	if _, err := New(strip, client, Config{CommandTopic: "strip/set", StateTopic: "strip/state"}); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	expected := `{"state":"ON","brightness":255,"color_mode":"rgb","color":{"r":0,"g":255,"b":0}}`
+	if client.published["strip/state"] != expected {
+		t.Errorf("Got state %s expected %s\n", client.published["strip/state"], expected)
+	}
+}
+
+func TestNewPublishError(t *testing.T) {
+	client := &fakeClient{handlers: map[string]func(string, []byte){}, published: map[string]string{}}
+	client.publishErr = errors.New("broker gone")
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	b, err := New(strip, client, Config{CommandTopic: "strip/set", StateTopic: "strip/state"})
+	if err != client.publishErr || b != nil {
+		t.Errorf("Got %v %v expected nil and %v\n", b, err, client.publishErr)
+	}
+	if len(client.handlers) != 0 {
+		t.Errorf("Got %d subscriptions expected 0\n", len(client.handlers))
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	client := &fakeClient{handlers: map[string]func(string, []byte){}, published: map[string]string{}}
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	b, err := New(strip, client, Config{CommandTopic: "strip/set", StateTopic: "strip/state"})
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if err := b.Announce(Discovery{}); err == nil {
+		t.Errorf("Expected an error without a unique ID\n")
+	}
+
+	d := Discovery{UniqueID: "shelf", Name: "Shelf", Effects: []string{"fire", "rainbow"}, AvailabilityTopic: "strip/status",
+		Device: &Device{Identifiers: []string{"pi-shelf"}, Name: "Shelf Pi"}}
+	if err := b.Announce(d); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	expected := `{"schema":"json","name":"Shelf","unique_id":"shelf","command_topic":"strip/set","state_topic":"strip/state",` +
+		`"brightness":true,"supported_color_modes":["rgb"],"effect":true,"effect_list":["fire","rainbow"],` +
+		`"availability_topic":"strip/status","payload_available":"online","payload_not_available":"offline",` +
+		`"device":{"identifiers":["pi-shelf"],"name":"Shelf Pi"}}`
+	if client.published["homeassistant/light/shelf/config"] != expected {
+		t.Errorf("Got config %s expected %s\n", client.published["homeassistant/light/shelf/config"], expected)
+	}
+	if client.published["strip/status"] != "online" {
+		t.Errorf("Got availability %q\n", client.published["strip/status"])
+	}
+
+	// Home Assistant coming back online gets the config again.
+	delete(client.published, "homeassistant/light/shelf/config")
+	client.handlers["homeassistant/status"]("homeassistant/status", []byte("online"))
+	if client.published["homeassistant/light/shelf/config"] != expected {
+		t.Errorf("Config not published again\n")
+	}
+
+	if err := b.Offline(); err != nil || client.published["strip/status"] != "offline" {
+		t.Errorf("Got availability %q error %v\n", client.published["strip/status"], err)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+The netstrip package streams Dotstar messages over a network, so that effects can be rendered on one machine and
+shown on a strip attached to another.
+
+A NetWriter is given to a Controller in place of the SPI bus.  Each message written by Update() is sent, with the
+number of LEDs in the strip, to a Server that writes it unchanged to its local Driver.  The Controller should be
+configured with the same order and chip as the remote strip.
+
+On the machine with the strip:
+
+	driver, _ := periph.NewDriver("", 8*physic.MegaHertz)
+	server := netstrip.NewServer(driver, 144)
+	server.ListenAndServe(fmt.Sprintf(":%d", netstrip.DefaultPort))
+
+On the machine rendering the effects:
+
+	strip, err := netstrip.NewController("tcp", "pi.local", 144)
+
+Both TCP and UDP are supported.  Over TCP each message follows the previous one on the connection, over UDP each
+message is sent as a single datagram.
+*/
+package netstrip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultPort is the TCP and UDP port used when none is given.
+const DefaultPort = 7780
+
+// magic starts every message.
+var magic = [4]byte{'D', 'S', 'N', 'F'}
+
+// version is the protocol version sent in each message.
+const version = 1
+
+// headerSize is the size of the message header: magic, version, flags, the LED count and the payload length.
+const headerSize = 12
+
+// maxPayload is the largest Dotstar message accepted, enough for over 16000 LEDs.
+const maxPayload = 65000
+
+// ErrBadMessage is returned when a message does not follow the protocol.
+var ErrBadMessage = errors.New("Message is not a netstrip frame")
+
+/*
+Internal function used to put the header for a payload of length bytes for ledCount LEDs into header.
+*/
+func putHeader(header []byte, ledCount, length int) {
+	copy(header, magic[:])
+	header[4] = version
+	header[5] = 0
+	binary.BigEndian.PutUint16(header[6:], uint16(ledCount))
+	binary.BigEndian.PutUint32(header[8:], uint32(length))
+}
+
+/*
+Internal function used to check a message header, returning the LED count and payload length.
+*/
+func parseHeader(header []byte) (ledCount, length int, err error) {
+	if len(header) < headerSize || string(header[:4]) != string(magic[:]) {
+		return 0, 0, ErrBadMessage
+	}
+	if header[4] != version {
+		return 0, 0, fmt.Errorf("Unsupported netstrip protocol version %d", header[4])
+	}
+	ledCount = int(binary.BigEndian.Uint16(header[6:]))
+	length = int(binary.BigEndian.Uint32(header[8:]))
+	if length > maxPayload {
+		return 0, 0, fmt.Errorf("Frame of %d bytes is too large", length)
+	}
+	return ledCount, length, nil
+}
+
+/*
+Internal function used to read a whole message from r into buffer, returning the LED count and the frame.
+*/
+func readMessage(r io.Reader, buffer []byte) (int, []byte, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	ledCount, length, err := parseHeader(header[:])
+	if err != nil {
+		return 0, nil, err
+	}
+	if cap(buffer) < length {
+		buffer = make([]byte, length, length)
+	}
+	buffer = buffer[:length]
+	if _, err := io.ReadFull(r, buffer); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+	return ledCount, buffer, nil
+}
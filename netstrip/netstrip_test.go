@@ -0,0 +1,98 @@
+package netstrip
+
+import (
+	"bytes"
+	"github.com/owlfish/dotstar"
+	"net"
+	"testing"
+)
+
+// frameDriver records the frames written to it, sending each on written if it is set.
+type frameDriver struct {
+	frames  [][]byte
+	written chan []byte
+}
+
+func (d *frameDriver) WriteFrame(frame []byte) error {
+	frame = append([]byte(nil), frame...)
+	d.frames = append(d.frames, frame)
+	if d.written != nil {
+		d.written <- frame
+	}
+	return nil
+}
+
+func (d *frameDriver) Close() error {
+	return nil
+}
+
+func TestServeConn(t *testing.T) {
+	var stream bytes.Buffer
+	strip := dotstar.NewController(NewNetWriter(&stream, 3), 3)
+	strip.SetColour(0, dotstar.Red)
+	strip.Update()
+	strip.SetColour(1, dotstar.Blue)
+	strip.Update()
+	sent := stream.Bytes()
+
+	local := &frameDriver{}
+	server := NewServer(local, 3)
+	if err := server.ServeConn(bytes.NewReader(sent)); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if len(local.frames) != 2 {
+		t.Fatalf("Got %d frames expected 2\n", len(local.frames))
+	}
+	// The messages are passed through unchanged.
+	expected := &frameDriver{}
+	direct := dotstar.NewDriverController(expected, 3)
+	direct.SetColour(0, dotstar.Red)
+	direct.SetColour(1, dotstar.Blue)
+	direct.Update()
+	if !bytes.Equal(local.frames[1], expected.frames[0]) {
+		t.Errorf("Got % X expected % X\n", local.frames[1], expected.frames[0])
+	}
+
+	if err := NewServer(local, 4).ServeConn(bytes.NewReader(sent)); err != ErrLedCount {
+		t.Errorf("Got %v expected %v\n", err, ErrLedCount)
+	}
+	if err := server.ServeConn(bytes.NewReader(sent[:20])); err == nil {
+		t.Errorf("Expected an error for a truncated message\n")
+	}
+	if err := server.ServeConn(bytes.NewReader([]byte("not a netstrip frame"))); err != ErrBadMessage {
+		t.Errorf("Got %v expected %v\n", err, ErrBadMessage)
+	}
+}
+
+func TestUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("UDP not available: ", err)
+	}
+	local := &frameDriver{written: make(chan []byte, 1)}
+	server := NewServer(local, 2)
+	done := make(chan error)
+	go func() { done <- server.ServePacketConn(conn) }()
+
+	strip, err := NewController("udp", conn.LocalAddr().String(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer strip.Close()
+	// A stray datagram is ignored.
+	stray, _ := net.Dial("udp", conn.LocalAddr().String())
+	stray.Write([]byte("hello"))
+	stray.Close()
+	strip.SetColour(1, dotstar.Green)
+	if err := strip.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := <-local.written
+	conn.Close()
+	<-done
+	clrs, err := dotstar.DecodeFrame(frame, "bgr")
+	if err != nil || len(clrs) != 2 || clrs[1].G != 255 {
+		t.Fatalf("Got %v %v\n", clrs, err)
+	}
+}
@@ -0,0 +1,135 @@
+package netstrip
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"io"
+	"net"
+	"sync"
+)
+
+/*
+A Server receives Dotstar messages from NetWriters and writes them to a local Driver.
+
+Server methods are safe to call from multiple goroutines, messages from several clients are written one at a time.
+*/
+type Server struct {
+	mu       sync.Mutex
+	driver   dotstar.Driver
+	ledCount int
+}
+
+/*
+NewServer creates a Server that writes messages to driver.
+
+ledCount is the number of LEDs on the local strip, messages from clients with a different count are rejected.
+A ledCount of 0 accepts messages of any size.
+*/
+func NewServer(driver dotstar.Driver, ledCount int) *Server {
+	return &Server{driver: driver, ledCount: ledCount}
+}
+
+/*
+ListenAndServe listens on the TCP address addr and serves NetWriter clients.
+*/
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return s.Serve(l)
+}
+
+/*
+Serve accepts connections from l, handling each in a new goroutine, until l returns an error.
+*/
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+/*
+ServeConn reads messages from r until it returns an error or a message is rejected.
+
+io.EOF at the end of a message is not treated as an error.
+*/
+func (s *Server) ServeConn(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	var buffer []byte
+	for {
+		ledCount, frame, err := readMessage(reader, buffer)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		buffer = frame
+		if err := s.HandleFrame(ledCount, frame); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+ListenAndServeUDP listens on the UDP address addr and serves NetWriter clients.
+*/
+func (s *Server) ListenAndServeUDP(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return s.ServePacketConn(conn)
+}
+
+/*
+ServePacketConn reads a message from each datagram on conn until it returns an error.
+
+Datagrams that are not valid messages, or are for a different number of LEDs, are ignored.  Errors writing to the
+Driver are returned.
+*/
+func (s *Server) ServePacketConn(conn net.PacketConn) error {
+	packet := make([]byte, headerSize+maxPayload)
+	var buffer []byte
+	for {
+		n, _, err := conn.ReadFrom(packet)
+		if err != nil {
+			return err
+		}
+		ledCount, frame, err := readMessage(bytes.NewReader(packet[:n]), buffer)
+		if err != nil {
+			continue
+		}
+		buffer = frame
+		if err := s.HandleFrame(ledCount, frame); err != nil && err != ErrLedCount {
+			return err
+		}
+	}
+}
+
+// ErrLedCount is returned when a client has a different number of LEDs to the Server.
+var ErrLedCount = errors.New("Client LED count does not match the strip")
+
+/*
+HandleFrame writes a Dotstar message for ledCount LEDs to the Driver.
+*/
+func (s *Server) HandleFrame(ledCount int, frame []byte) error {
+	if s.ledCount != 0 && ledCount != s.ledCount {
+		return ErrLedCount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.driver.WriteFrame(frame)
+}
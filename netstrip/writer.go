@@ -0,0 +1,93 @@
+package netstrip
+
+import (
+	"fmt"
+	"github.com/owlfish/dotstar"
+	"io"
+	"net"
+)
+
+/*
+A NetWriter sends each Dotstar message written to it to a Server.
+
+Each call to Write must contain a whole message, as sent by Update().
+*/
+type NetWriter struct {
+	conn     io.Writer
+	ledCount int
+	// packet holds the header and message, so each message is sent with a single write.
+	packet []byte
+}
+
+/*
+Dial connects to the Server at addr (host:port, the port defaults to DefaultPort) and returns a NetWriter for a
+strip of ledCount LEDs.  network is "tcp" or "udp".
+*/
+func Dial(network, addr string, ledCount int) (*NetWriter, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(DefaultPort))
+	}
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewNetWriter(conn, ledCount), nil
+}
+
+/*
+NewNetWriter returns a NetWriter that sends messages for a strip of ledCount LEDs to conn.
+*/
+func NewNetWriter(conn io.Writer, ledCount int) *NetWriter {
+	return &NetWriter{conn: conn, ledCount: ledCount}
+}
+
+/*
+NewController connects to the Server at addr and returns a Controller for ledCount LEDs that sends to it.
+
+The network and addr are as for Dial.  The connection is closed when the Controller is closed.
+*/
+func NewController(network, addr string, ledCount int, cfgs ...dotstar.ConfigFunc) (*dotstar.Controller, error) {
+	w, err := Dial(network, addr, ledCount)
+	if err != nil {
+		return nil, err
+	}
+	return dotstar.NewDriverController(w, ledCount, cfgs...), nil
+}
+
+/*
+WriteFrame sends the whole Dotstar message.
+*/
+func (w *NetWriter) WriteFrame(frame []byte) error {
+	if len(frame) > maxPayload {
+		return fmt.Errorf("Frame of %d bytes is too large", len(frame))
+	}
+	size := headerSize + len(frame)
+	if cap(w.packet) < size {
+		w.packet = make([]byte, size, size)
+	}
+	w.packet = w.packet[:size]
+	putHeader(w.packet, w.ledCount, len(frame))
+	copy(w.packet[headerSize:], frame)
+	_, err := w.conn.Write(w.packet)
+	return err
+}
+
+/*
+Write sends the Dotstar message in p.  Each call must contain a whole message.
+*/
+func (w *NetWriter) Write(p []byte) (int, error) {
+	if err := w.WriteFrame(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/*
+Close closes the connection if it implements io.Closer.
+*/
+func (w *NetWriter) Close() error {
+	if closer, ok := w.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
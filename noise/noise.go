@@ -0,0 +1,129 @@
+/*
+The noise package generates simplex noise, a smoothly varying pseudo-random value useful for organic looking
+effects such as fire, lava and clouds.
+
+The functions return values from -1 to 1 and are based on Stefan Gustavson's public domain implementation of
+Ken Perlin's simplex noise.  Nearby inputs give nearby outputs, with features roughly 1 unit apart.
+*/
+package noise
+
+import (
+	"math"
+)
+
+// perm is Ken Perlin's permutation table, repeated to avoid wrapping indexes.
+var perm [512]uint8
+
+func init() {
+	base := [256]uint8{151, 160, 137, 91, 90, 15,
+		131, 13, 201, 95, 96, 53, 194, 233, 7, 225, 140, 36, 103, 30, 69, 142, 8, 99, 37, 240, 21, 10, 23,
+		190, 6, 148, 247, 120, 234, 75, 0, 26, 197, 62, 94, 252, 219, 203, 117, 35, 11, 32, 57, 177, 33,
+		88, 237, 149, 56, 87, 174, 20, 125, 136, 171, 168, 68, 175, 74, 165, 71, 134, 139, 48, 27, 166,
+		77, 146, 158, 231, 83, 111, 229, 122, 60, 211, 133, 230, 220, 105, 92, 41, 55, 46, 245, 40, 244,
+		102, 143, 54, 65, 25, 63, 161, 1, 216, 80, 73, 209, 76, 132, 187, 208, 89, 18, 169, 200, 196,
+		135, 130, 116, 188, 159, 86, 164, 100, 109, 198, 173, 186, 3, 64, 52, 217, 226, 250, 124, 123,
+		5, 202, 38, 147, 118, 126, 255, 82, 85, 212, 207, 206, 59, 227, 47, 16, 58, 17, 182, 189, 28, 42,
+		223, 183, 170, 213, 119, 248, 152, 2, 44, 154, 163, 70, 221, 153, 101, 155, 167, 43, 172, 9,
+		129, 22, 39, 253, 19, 98, 108, 110, 79, 113, 224, 232, 178, 185, 112, 104, 218, 246, 97, 228,
+		251, 34, 242, 193, 238, 210, 144, 12, 191, 179, 162, 241, 81, 51, 145, 235, 249, 14, 239, 107,
+		49, 192, 214, 31, 181, 199, 106, 157, 184, 84, 204, 176, 115, 121, 50, 45, 127, 4, 150, 254,
+		138, 236, 205, 93, 222, 114, 67, 29, 24, 72, 243, 141, 128, 195, 78, 66, 215, 61, 156, 180}
+	for i := range perm {
+		perm[i] = base[i&255]
+	}
+}
+
+// Skewing factors for 2D simplex noise.
+const (
+	f2 = 0.366025403784438646763723170752936183 // (sqrt(3)-1)/2
+	g2 = 0.211324865405187117745425609748791    // (3-sqrt(3))/6
+)
+
+/*
+Simplex1D returns the noise value at x.
+*/
+func Simplex1D(x float64) float64 {
+	i0 := int(math.Floor(x))
+	x0 := x - float64(i0)
+	x1 := x0 - 1
+
+	t0 := 1 - x0*x0
+	t0 *= t0
+	n0 := t0 * t0 * grad1(perm[i0&255], x0)
+
+	t1 := 1 - x1*x1
+	t1 *= t1
+	n1 := t1 * t1 * grad1(perm[(i0+1)&255], x1)
+
+	// Scale the result to cover -1 to 1.
+	return 0.395 * (n0 + n1)
+}
+
+/*
+Simplex2D returns the noise value at x, y.
+
+For strips a common pattern is to use the LED position for x and time for y, giving noise that moves smoothly.
+*/
+func Simplex2D(x, y float64) float64 {
+	// Skew the input space to find the simplex cell.
+	s := (x + y) * f2
+	i := int(math.Floor(x + s))
+	j := int(math.Floor(y + s))
+	t := float64(i+j) * g2
+	x0 := x - (float64(i) - t)
+	y0 := y - (float64(j) - t)
+
+	// Find which of the two triangles of the cell the point is in.
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+	x1 := x0 - float64(i1) + g2
+	y1 := y0 - float64(j1) + g2
+	x2 := x0 - 1 + 2*g2
+	y2 := y0 - 1 + 2*g2
+
+	ii, jj := i&255, j&255
+	n0 := corner2(x0, y0, perm[ii+int(perm[jj])])
+	n1 := corner2(x1, y1, perm[ii+i1+int(perm[jj+j1])])
+	n2 := corner2(x2, y2, perm[ii+1+int(perm[jj+1])])
+
+	// Scale the result to cover -1 to 1.
+	return 40 * (n0 + n1 + n2)
+}
+
+// corner2 returns the contribution of a simplex corner.
+func corner2(x, y float64, hash uint8) float64 {
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * grad2(hash, x, y)
+}
+
+// grad1 returns the dot product of x with one of 16 gradients.
+func grad1(hash uint8, x float64) float64 {
+	h := hash & 15
+	grad := 1 + float64(h&7)
+	if h&8 != 0 {
+		grad = -grad
+	}
+	return grad * x
+}
+
+// grad2 returns the dot product of x, y with one of 8 gradients.
+func grad2(hash uint8, x, y float64) float64 {
+	h := hash & 7
+	u, v := x, y
+	if h >= 4 {
+		u, v = y, x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + 2*v
+}
@@ -0,0 +1,35 @@
+package noise
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		x, y := float64(i)*0.137-300, float64(i%97)*0.291-10
+		if v := Simplex1D(x); v < -1 || v > 1 {
+			t.Fatalf("Simplex1D(%v) got %v outside -1 to 1\n", x, v)
+		}
+		if v := Simplex2D(x, y); v < -1 || v > 1 {
+			t.Fatalf("Simplex2D(%v, %v) got %v outside -1 to 1\n", x, y, v)
+		}
+	}
+}
+
+func TestLatticePointsAreZero(t *testing.T) {
+	for i := -5; i < 5; i++ {
+		if v := Simplex1D(float64(i)); v != 0 {
+			t.Errorf("Simplex1D(%d) got %v expected 0\n", i, v)
+		}
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	const step = 0.001
+	for x := 0.0; x < 10; x += 0.1 {
+		if d := math.Abs(Simplex2D(x, 0.5) - Simplex2D(x+step, 0.5)); d > 0.05 {
+			t.Errorf("Got jump of %v at %v\n", d, x)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+The opc package implements an Open Pixel Control server that drives a Dotstar strip.
+
+Existing OPC clients, such as Processing sketches or Fadecandy tools, can then target the strip:
+
+	strip := dotstar.NewController(spiBus, 64)
+	server := opc.NewServer(strip, 1)
+	server.ListenAndServe(fmt.Sprintf(":%d", opc.DefaultPort))
+*/
+package opc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"github.com/owlfish/dotstar"
+	"io"
+	"net"
+	"sync"
+)
+
+// DefaultPort is the TCP port normally used by OPC servers.
+const DefaultPort = 7890
+
+// broadcastChannel addresses every channel on the server.
+const broadcastChannel = 0
+
+// setPixelColours is the OPC command holding RGB values for each pixel.
+const setPixelColours = 0
+
+// headerSize is the size of the OPC message header: channel, command and a 16 bit length.
+const headerSize = 4
+
+/*
+A Server receives OPC messages and applies them to a Controller.
+
+Set pixel colours messages for the server's channel, or the broadcast channel 0, set the LEDs from position 0
+and trigger an Update().  Other commands are ignored.  Server methods are safe to call from multiple goroutines,
+but the Controller must not be used elsewhere while the Server is running.
+*/
+type Server struct {
+	mu      sync.Mutex
+	ctl     *dotstar.Controller
+	channel uint8
+}
+
+/*
+NewServer creates a Server that drives ctl when messages are received for channel.
+*/
+func NewServer(ctl *dotstar.Controller, channel uint8) *Server {
+	return &Server{ctl: ctl, channel: channel}
+}
+
+/*
+ListenAndServe listens on the TCP address addr and serves OPC clients.
+*/
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return s.Serve(l)
+}
+
+/*
+Serve accepts connections from l, handling each in a new goroutine, until l returns an error.
+*/
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+/*
+ServeConn reads OPC messages from r until it returns an error or a message cannot be applied.
+
+io.EOF at the end of a message is not treated as an error.
+*/
+func (s *Server) ServeConn(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	var header [headerSize]byte
+	var data []byte
+	for {
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		length := int(binary.BigEndian.Uint16(header[2:]))
+		if cap(data) < length {
+			data = make([]byte, length, length)
+		}
+		data = data[:length]
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return err
+		}
+		if err := s.handleMessage(header[0], header[1], data); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Internal method used to apply a single OPC message.
+*/
+func (s *Server) handleMessage(channel, command uint8, data []byte) error {
+	if command != setPixelColours || (channel != broadcastChannel && channel != s.channel) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+3 <= len(data); i += 3 {
+		s.ctl.SetColour(i/3, dotstar.NewColour(data[i], data[i+1], data[i+2], 255))
+	}
+	return s.ctl.Update()
+}
@@ -0,0 +1,37 @@
+package opc
+
+import (
+	"bytes"
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"testing"
+)
+
+func TestServeConn(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	server := NewServer(strip, 2)
+
+	messages := []byte{
+		// Channel 2, set pixels
+		2, 0, 0, 6, 255, 0, 0, 0, 255, 0,
+		// Channel 3 is ignored
+		3, 0, 0, 3, 1, 1, 1,
+		// System exclusive is ignored
+		0, 255, 0, 2, 9, 9,
+		// Broadcast
+		0, 0, 0, 9, 255, 0, 0, 0, 255, 0, 0, 0, 255,
+	}
+	if err := server.ServeConn(bytes.NewReader(messages[:23])); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if strip.GetColour(0) != dotstar.Red || strip.GetColour(1) != dotstar.Green || strip.GetColour(2) != dotstar.Off {
+		t.Errorf("Got %v\n", strip.Snapshot())
+	}
+
+	if err := server.ServeConn(bytes.NewReader(messages[23:])); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if strip.GetColour(2) != dotstar.Blue {
+		t.Errorf("Got %v\n", strip.Snapshot())
+	}
+}
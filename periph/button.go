@@ -0,0 +1,43 @@
+package periph
+
+import (
+	"errors"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/host/v3"
+	"time"
+)
+
+/*
+A Button is a push button on a GPIO pin, wired to connect the pin to ground when pressed.  It can be bound to
+actions with an input.Binder.
+*/
+type Button struct {
+	pin gpio.PinIn
+}
+
+/*
+NewButton initialises periph.io and opens the named GPIO pin, such as "GPIO17", as a button.
+
+The pin's pull-up resistor is enabled and presses are detected on the falling edge.
+*/
+func NewButton(pinName string) (*Button, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, err
+	}
+	pin := gpioreg.ByName(pinName)
+	if pin == nil {
+		return nil, errors.New("GPIO pin " + pinName + " not found")
+	}
+	if err := pin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+		return nil, err
+	}
+	return &Button{pin: pin}, nil
+}
+
+/*
+WaitForPress blocks until the button is pressed, returning false if timeout passes first.
+*/
+func (b *Button) WaitForPress(timeout time.Duration) bool {
+	return b.pin.WaitForEdge(timeout)
+}
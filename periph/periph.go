@@ -0,0 +1,96 @@
+/*
+The periph package opens an SPI port using periph.io and returns a dotstar Controller that drives it.
+
+	strip, err := periph.NewPeriphController("", 8*physic.MegaHertz, 30)
+	if err != nil {
+		panic(err)
+	}
+	strip.SetColour(0, dotstar.Red)
+	strip.Update()
+*/
+package periph
+
+import (
+	"github.com/owlfish/dotstar"
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+	"periph.io/x/host/v3"
+)
+
+/*
+NewPeriphController initialises periph.io, opens the SPI port and returns a Controller for ledCount LEDs.
+
+portName is passed to spireg.Open, an empty string opens the first available port.
+speed is the SPI clock frequency to use.  The port is closed when the Driver is closed.
+*/
+func NewPeriphController(portName string, speed physic.Frequency, ledCount int, cfgs ...dotstar.ConfigFunc) (*dotstar.Controller, error) {
+	driver, err := NewDriver(portName, speed)
+	if err != nil {
+		return nil, err
+	}
+	return dotstar.NewDriverController(driver, ledCount, cfgs...), nil
+}
+
+/*
+A Driver sends Dotstar messages over a periph.io SPI connection.
+
+Messages larger than the maximum transfer size of the port are split into several transfers.
+*/
+type Driver struct {
+	port spi.PortCloser
+	conn spi.Conn
+	// maxTxSize is the largest transfer supported by the port, or 0 if there is no limit.
+	maxTxSize int
+}
+
+/*
+NewDriver initialises periph.io and opens the named SPI port at the given speed.
+*/
+func NewDriver(portName string, speed physic.Frequency) (*Driver, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, err
+	}
+
+	port, err := spireg.Open(portName)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := port.Connect(speed, spi.Mode0, 8)
+	if err != nil {
+		port.Close()
+		return nil, err
+	}
+
+	driver := &Driver{port: port, conn: c}
+	if limits, ok := c.(conn.Limits); ok {
+		driver.maxTxSize = limits.MaxTxSize()
+	}
+	return driver, nil
+}
+
+/*
+WriteFrame sends the message to the SPI port.
+*/
+func (d *Driver) WriteFrame(frame []byte) error {
+	for len(frame) > 0 {
+		chunk := frame
+		if d.maxTxSize > 0 && len(chunk) > d.maxTxSize {
+			chunk = chunk[:d.maxTxSize]
+		}
+		if err := d.conn.Tx(chunk, nil); err != nil {
+			return err
+		}
+		frame = frame[len(chunk):]
+	}
+	return nil
+}
+
+/*
+Close closes the SPI port.
+*/
+func (d *Driver) Close() error {
+	return d.port.Close()
+}
@@ -0,0 +1,268 @@
+/*
+The preview package shows a live view of a Dotstar strip in a web browser.
+
+A Server is used as the io.Writer for a Controller.  Each message written by Update() is decoded and streamed
+over a WebSocket to any browsers viewing the page served by the Server:
+
+	server, err := preview.NewServer("bgr")
+	if err != nil {
+		panic(err)
+	}
+	go server.ListenAndServe(":8080")
+	strip := dotstar.NewController(server, 30)
+*/
+package preview
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/owlfish/dotstar"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is appended to the client key to form the handshake response (RFC 6455).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// writeTimeout limits how long a slow browser can hold up sending a frame.
+const writeTimeout = 5 * time.Second
+
+/*
+A Server serves the preview page and streams decoded frames to connected browsers.
+
+Server implements io.Writer so that it can be passed to dotstar.NewController.  It also implements
+http.Handler, serving the page at / and the WebSocket at /ws.
+*/
+type Server struct {
+	// order is the colour order used to decode messages.
+	order string
+
+	mu sync.Mutex
+	// format is the layout of the messages, as given by the Controller.
+	format  dotstar.FrameFormat
+	clients map[*client]struct{}
+	// last holds the most recent frame so new browsers are shown the current state.
+	last []byte
+}
+
+// client is a connected browser.
+type client struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+/*
+NewServer creates a Server that decodes messages using the given colour order.
+
+order must match the order configured on the Controller.
+*/
+func NewServer(order string) (*Server, error) {
+	if _, err := dotstar.OrderConfig(order); err != nil {
+		return nil, err
+	}
+	return &Server{
+		order:   order,
+		clients: make(map[*client]struct{}),
+	}, nil
+}
+
+/*
+ListenAndServe listens on the TCP address addr and serves the preview page.
+*/
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s)
+}
+
+/*
+ServeHTTP serves the preview page and WebSocket.
+*/
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	case "/ws":
+		s.serveWebSocket(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+/*
+Write decodes the Dotstar message in p and sends it to all connected browsers.
+
+Browsers that are too slow to keep up skip frames rather than blocking the Controller.
+*/
+func (s *Server) Write(p []byte) (int, error) {
+	frame, err := s.decode(p)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = frame
+	for c := range s.clients {
+		select {
+		case c.frames <- frame:
+		default:
+			// Still sending the previous frame.
+		}
+	}
+	return len(p), nil
+}
+
+/*
+SetFrameFormat implements dotstar.FrameDecoder, it is called by the Controller.
+*/
+func (s *Server) SetFrameFormat(format dotstar.FrameFormat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = format
+}
+
+/*
+Internal method used to decode a Dotstar message into RGB triples with the brightness applied.
+*/
+func (s *Server) decode(p []byte) ([]byte, error) {
+	s.mu.Lock()
+	format := s.format
+	s.mu.Unlock()
+	clrs, err := dotstar.DecodeFrameFormat(p, s.order, format)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, 0, len(clrs)*3)
+	for _, clr := range clrs {
+		clr = clr.Flatten()
+		frame = append(frame, clr.R, clr.G, clr.B)
+	}
+	return frame, nil
+}
+
+/*
+Internal method used to complete the WebSocket handshake and start streaming frames.
+*/
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "WebSocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &client{conn: conn, frames: make(chan []byte, 1)}
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	if s.last != nil {
+		c.frames <- s.last
+	}
+	s.mu.Unlock()
+
+	go s.sendFrames(c)
+	// Messages from the browser are not used, reading only detects the connection closing.
+	io.Copy(ioutil.Discard, rw.Reader)
+	s.remove(c)
+}
+
+/*
+Internal method used to send frames to a client until the connection fails or is closed.
+*/
+func (s *Server) sendFrames(c *client) {
+	w := bufio.NewWriter(c.conn)
+	for frame := range c.frames {
+		c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := writeBinaryMessage(w, frame); err != nil {
+			s.remove(c)
+		}
+	}
+}
+
+/*
+Internal method used to disconnect a client.
+*/
+func (s *Server) remove(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.frames)
+		c.conn.Close()
+	}
+}
+
+/*
+Internal function used to write an unmasked binary WebSocket message.
+*/
+func writeBinaryMessage(w *bufio.Writer, data []byte) error {
+	// FIN bit set with the binary opcode.
+	w.WriteByte(0x82)
+	switch {
+	case len(data) < 126:
+		w.WriteByte(byte(len(data)))
+	case len(data) <= 0xFFFF:
+		w.WriteByte(126)
+		binary.Write(w, binary.BigEndian, uint16(len(data)))
+	default:
+		w.WriteByte(127)
+		binary.Write(w, binary.BigEndian, uint64(len(data)))
+	}
+	w.Write(data)
+	return w.Flush()
+}
+
+// page is the HTML served to browsers.  Each frame is a sequence of RGB bytes, one LED per triple.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<title>Dotstar preview</title>
+<style>body { background: #111; margin: 0; } canvas { width: 100%; }</style>
+</head>
+<body>
+<canvas id="strip" height="40"></canvas>
+<script>
+var canvas = document.getElementById("strip");
+var ctx = canvas.getContext("2d");
+var ws = new WebSocket((location.protocol == "https:" ? "wss://" : "ws://") + location.host + "/ws");
+ws.binaryType = "arraybuffer";
+ws.onmessage = function(event) {
+	var data = new Uint8Array(event.data);
+	var count = data.length / 3;
+	var size = 40;
+	canvas.width = count * size;
+	ctx.fillStyle = "#111";
+	ctx.fillRect(0, 0, canvas.width, canvas.height);
+	for (var i = 0; i < count; i++) {
+		ctx.fillStyle = "rgb(" + data[i*3] + "," + data[i*3+1] + "," + data[i*3+2] + ")";
+		ctx.beginPath();
+		ctx.arc(i * size + size / 2, size / 2, size * 0.4, 0, 2 * Math.PI);
+		ctx.fill();
+	}
+};
+</script>
+</body>
+</html>
+`
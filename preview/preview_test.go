@@ -0,0 +1,54 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/owlfish/dotstar"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerStreamsFrames(t *testing.T) {
+	server, err := NewServer("bgr")
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	strip := dotstar.NewController(server, 2, dotstar.DisableGammaCorrectionConfig())
+	strip.SetColour(0, dotstar.Red)
+	strip.SetColour(1, dotstar.NewColour(0, 0, 248, 255))
+	strip.Update()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(httpServer.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Got handshake %v %v\n", resp.Status, resp.Header)
+	}
+
+	// The last frame is sent on connection.
+	message := make([]byte, 8)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		t.Fatalf("Unexpected error %v\n", err)
+	}
+	expected := []byte{0x82, 6, 255, 0, 0, 0, 0, 248}
+	if !bytes.Equal(message, expected) {
+		t.Errorf("Got % X expected % X\n", message, expected)
+	}
+}
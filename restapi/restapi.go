@@ -0,0 +1,253 @@
+/*
+The restapi package provides an http.Handler that exposes the state of a Dotstar strip as JSON.
+
+The handler serves the following paths, relative to where it is mounted:
+
+	GET, PUT  /leds             all LED colours as an array
+	GET, PUT  /leds/{n}         the colour of LED n
+	GET, PUT  /brightness       the global brightness, {"brightness": 255}
+	GET       /segments         the configured segments
+	GET, PUT  /segments/{name}  the colours of a segment.  PUT a single colour to fill it.
+	GET, PUT  /effect           the running effect, {"effect": "name"}
+
+Colours are objects with r, g, b and l (luminosity) fields, for example {"r": 255, "g": 0, "b": 0, "l": 255}.
+Every PUT is followed by an Update().  To mount it under a prefix use http.StripPrefix:
+
+	http.Handle("/strip/", http.StripPrefix("/strip", restapi.NewHandler(strip, restapi.Config{})))
+*/
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Colour is the JSON representation of a dotstar.Colour.
+*/
+type Colour struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+	L uint8 `json:"l"`
+}
+
+/*
+A Segment is a named range of LEDs.
+*/
+type Segment struct {
+	Start int `json:"start"`
+	Count int `json:"count"`
+}
+
+/*
+A Config holds the segments and effect handling for a Handler.
+*/
+type Config struct {
+	// Segments are the named ranges of LEDs served under /segments.
+	Segments map[string]Segment
+	// Effect is called when an effect is selected.  It may be nil if no effects are supported.
+	Effect func(name string) error
+}
+
+/*
+A Handler serves the REST API for a Controller.
+
+Requests are handled one at a time.  The Controller must not be used elsewhere while the Handler is being
+served, except from the Effect function.
+*/
+type Handler struct {
+	mu     sync.Mutex
+	ctl    *dotstar.Controller
+	cfg    Config
+	effect string
+}
+
+// brightnessBody is the JSON used by /brightness.
+type brightnessBody struct {
+	Brightness uint8 `json:"brightness"`
+}
+
+// effectBody is the JSON used by /effect.
+type effectBody struct {
+	Effect string `json:"effect"`
+}
+
+// errNotFound is returned for unknown paths.
+var errNotFound = errors.New("Not found")
+
+/*
+NewHandler creates a Handler for ctl.
+*/
+func NewHandler(ctl *dotstar.Controller, cfg Config) *Handler {
+	return &Handler{ctl: ctl, cfg: cfg}
+}
+
+/*
+ServeHTTP handles a single API request.
+*/
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		methodNotAllowed(w, "GET, PUT")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var result interface{}
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "leds":
+		result, err = h.serveRange(r, 0, h.ctl.Len())
+	case len(parts) == 2 && parts[0] == "leds":
+		result, err = h.serveLed(r, parts[1])
+	case len(parts) == 1 && parts[0] == "brightness":
+		result, err = h.serveBrightness(r)
+	case len(parts) == 1 && parts[0] == "segments":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, "GET")
+			return
+		}
+		result = h.cfg.Segments
+	case len(parts) == 2 && parts[0] == "segments":
+		segment, ok := h.cfg.Segments[parts[1]]
+		if !ok {
+			err = errNotFound
+			break
+		}
+		result, err = h.serveRange(r, segment.Start, segment.Count)
+	case len(parts) == 1 && parts[0] == "effect":
+		result, err = h.serveEffect(r)
+	default:
+		err = errNotFound
+	}
+
+	if err == errNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodPut {
+		if err := h.ctl.Update(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+/*
+Internal function used to reject a request with a method other than those allowed.
+*/
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+/*
+Internal method used to get or set a range of LEDs.  A PUT of a single colour fills the range.
+*/
+func (h *Handler) serveRange(r *http.Request, start, count int) (interface{}, error) {
+	if r.Method == http.MethodPut {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		var clrs []Colour
+		if err := json.Unmarshal(body, &clrs); err != nil {
+			var clr Colour
+			if err := json.Unmarshal(body, &clr); err != nil {
+				return nil, err
+			}
+			clrs = make([]Colour, count, count)
+			for i := range clrs {
+				clrs[i] = clr
+			}
+		}
+		for i, clr := range clrs {
+			if i >= count {
+				break
+			}
+			h.ctl.SetColour(start+i, fromJSON(clr))
+		}
+	}
+
+	result := make([]Colour, 0, count)
+	for i := start; i < start+count; i++ {
+		result = append(result, toJSON(h.ctl.GetColour(i)))
+	}
+	return result, nil
+}
+
+/*
+Internal method used to get or set a single LED.
+*/
+func (h *Handler) serveLed(r *http.Request, index string) (interface{}, error) {
+	position, err := strconv.Atoi(index)
+	if err != nil || position < 0 || position >= h.ctl.Len() {
+		return nil, errNotFound
+	}
+	if r.Method == http.MethodPut {
+		var clr Colour
+		if err := json.NewDecoder(r.Body).Decode(&clr); err != nil {
+			return nil, err
+		}
+		h.ctl.SetColour(position, fromJSON(clr))
+	}
+	return toJSON(h.ctl.GetColour(position)), nil
+}
+
+/*
+Internal method used to get or set the global brightness.
+*/
+func (h *Handler) serveBrightness(r *http.Request) (interface{}, error) {
+	if r.Method == http.MethodPut {
+		var body brightnessBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		h.ctl.SetGlobalBrightness(body.Brightness)
+	}
+	return brightnessBody{Brightness: h.ctl.GetGlobalBrightness()}, nil
+}
+
+/*
+Internal method used to get or select the running effect.
+*/
+func (h *Handler) serveEffect(r *http.Request) (interface{}, error) {
+	if r.Method == http.MethodPut {
+		var body effectBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if h.cfg.Effect == nil {
+			return nil, errors.New("Effects are not supported")
+		}
+		if err := h.cfg.Effect(body.Effect); err != nil {
+			return nil, err
+		}
+		h.effect = body.Effect
+	}
+	return effectBody{Effect: h.effect}, nil
+}
+
+// toJSON converts a dotstar.Colour to its JSON representation.
+func toJSON(c dotstar.Colour) Colour {
+	return Colour{R: c.R, G: c.G, B: c.B, L: c.L}
+}
+
+// fromJSON converts the JSON representation back to a dotstar.Colour.
+func fromJSON(c Colour) dotstar.Colour {
+	return dotstar.NewColour(c.R, c.G, c.B, c.L)
+}
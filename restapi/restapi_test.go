@@ -0,0 +1,53 @@
+package restapi
+
+import (
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func request(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 4)
+	h := NewHandler(strip, Config{Segments: map[string]Segment{"end": {Start: 2, Count: 2}}})
+
+	if rec := request(h, http.MethodPut, "/leds/1", `{"r":255,"g":0,"b":0,"l":255}`); rec.Code != http.StatusOK {
+		t.Fatalf("Got status %d: %s\n", rec.Code, rec.Body)
+	}
+	if strip.GetColour(1) != dotstar.Red {
+		t.Errorf("Got colour %v expected red\n", strip.GetColour(1))
+	}
+
+	request(h, http.MethodPut, "/segments/end", `{"r":0,"g":0,"b":255,"l":255}`)
+	rec := request(h, http.MethodGet, "/leds", "")
+	expected := `[{"r":0,"g":0,"b":0,"l":0},{"r":255,"g":0,"b":0,"l":255},{"r":0,"g":0,"b":255,"l":255},{"r":0,"g":0,"b":255,"l":255}]`
+	if strings.TrimSpace(rec.Body.String()) != expected {
+		t.Errorf("Got %s expected %s\n", rec.Body, expected)
+	}
+
+	rec = request(h, http.MethodPut, "/brightness", `{"brightness":64}`)
+	if strip.GetGlobalBrightness() != 64 || strings.TrimSpace(rec.Body.String()) != `{"brightness":64}` {
+		t.Errorf("Got brightness %d response %s\n", strip.GetGlobalBrightness(), rec.Body)
+	}
+
+	if rec := request(h, http.MethodGet, "/leds/4", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Got status %d for out of range LED\n", rec.Code)
+	}
+	if rec := request(h, http.MethodPut, "/effect", `{"effect":"fire"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Got status %d selecting effect without support\n", rec.Code)
+	}
+	if rec := request(h, http.MethodPut, "/segments", "{}"); rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET" {
+		t.Errorf("Got status %d allowing %q for PUT to segments\n", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec := request(h, http.MethodDelete, "/leds", ""); rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("Got status %d allowing %q for DELETE\n", rec.Code, rec.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,109 @@
+package rpcapi
+
+import (
+	"github.com/owlfish/dotstar"
+	"net/rpc"
+)
+
+/*
+A Client makes typed calls to a remote Service.  Methods are safe to call from multiple goroutines.
+*/
+type Client struct {
+	rpc *rpc.Client
+}
+
+/*
+Dial connects to the Service at addr on the given network, normally "tcp".
+*/
+func Dial(network, addr string) (*Client, error) {
+	c, err := rpc.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+/*
+NewClient creates a Client that makes calls using c.
+*/
+func NewClient(c *rpc.Client) *Client {
+	return &Client{rpc: c}
+}
+
+/*
+SetColour sets the colour of the LED at position.
+*/
+func (c *Client) SetColour(position int, colour dotstar.Colour) error {
+	return c.rpc.Call(ServiceName+".SetColour", SetColourArgs{Position: position, Colour: colour}, &Empty{})
+}
+
+/*
+SetColours sets the colours of the LEDs from offset onwards.
+*/
+func (c *Client) SetColours(offset int, colours []dotstar.Colour) error {
+	return c.rpc.Call(ServiceName+".SetColours", SetColoursArgs{Offset: offset, Colours: colours}, &Empty{})
+}
+
+/*
+Fill sets every LED to colour.
+*/
+func (c *Client) Fill(colour dotstar.Colour) error {
+	return c.rpc.Call(ServiceName+".Fill", colour, &Empty{})
+}
+
+/*
+Colours returns the colours of every LED.
+*/
+func (c *Client) Colours() ([]dotstar.Colour, error) {
+	var clrs []dotstar.Colour
+	err := c.rpc.Call(ServiceName+".GetColours", Empty{}, &clrs)
+	return clrs, err
+}
+
+/*
+SetBrightness sets the global brightness.
+*/
+func (c *Client) SetBrightness(brightness uint8) error {
+	return c.rpc.Call(ServiceName+".SetBrightness", brightness, &Empty{})
+}
+
+/*
+Brightness returns the global brightness.
+*/
+func (c *Client) Brightness() (uint8, error) {
+	var brightness uint8
+	err := c.rpc.Call(ServiceName+".GetBrightness", Empty{}, &brightness)
+	return brightness, err
+}
+
+/*
+SetEffect selects the running effect.
+*/
+func (c *Client) SetEffect(name string) error {
+	return c.rpc.Call(ServiceName+".SetEffect", name, &Empty{})
+}
+
+/*
+Effect returns the name of the effect last selected.
+*/
+func (c *Client) Effect() (string, error) {
+	var name string
+	err := c.rpc.Call(ServiceName+".GetEffect", Empty{}, &name)
+	return name, err
+}
+
+/*
+NextFrame waits for a frame after the Sequence given, see Service.NextFrame.
+*/
+func (c *Client) NextFrame(after uint64) (Frame, error) {
+	var frame Frame
+	err := c.rpc.Call(ServiceName+".NextFrame", after, &frame)
+	return frame, err
+}
+
+/*
+Close closes the connection to the Service.
+*/
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
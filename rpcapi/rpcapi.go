@@ -0,0 +1,246 @@
+/*
+The rpcapi package provides typed remote control of a Dotstar strip using net/rpc.
+
+A Service is registered with an rpc.Server and exposes the colours, brightness and effect of the strip, along with
+a frame endpoint that lets clients follow every frame sent.  A Client wraps an rpc.Client with typed methods:
+
+	service := rpcapi.NewService(strip, rpcapi.Config{Effect: startEffect})
+	go rpcapi.ListenAndServe(":7781", service)
+
+	client, err := rpcapi.Dial("tcp", "pi.local:7781")
+	client.SetColour(0, dotstar.Red)
+	for frame, err := client.NextFrame(0); err == nil; frame, err = client.NextFrame(frame.Sequence) {
+		fmt.Println(frame.Colours)
+	}
+
+Every call that changes the strip is followed by an Update().  An effect run by an effects.Animator updates the
+strip from its own goroutine, so give the Service a lock and hold it around each frame:
+
+	var lock sync.Mutex
+	service := rpcapi.NewService(strip, rpcapi.Config{Lock: &lock, Effect: startEffect})
+
+	// In the effect goroutine started by startEffect:
+	lock.Lock()
+	err := animator.RenderFrame(ctx, effect, t)
+	lock.Unlock()
+*/
+package rpcapi
+
+import (
+	"errors"
+	"github.com/owlfish/dotstar"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// ServiceName is the name the Service is registered under.
+const ServiceName = "Strip"
+
+// framePoll is how often NextFrame checks for a new frame.
+const framePoll = 20 * time.Millisecond
+
+// frameTimeout is the longest NextFrame waits before returning the current frame.
+const frameTimeout = 10 * time.Second
+
+/*
+A Config holds the effect handling for a Service.
+*/
+type Config struct {
+	// Effect is called when an effect is selected.  It may be nil if no effects are supported.
+	Effect func(name string) error
+	// Lock, if not nil, is held whenever the Service uses the Controller.  Anything else using the Controller,
+	// such as the Animator of an effect, must hold it too.  It must not be held while calling SetEffect.
+	Lock sync.Locker
+}
+
+/*
+A Service is the net/rpc service for a Controller.
+
+Calls using the Controller are handled one at a time.  The Controller must not be used elsewhere while the Service is being served
+unless Config.Lock is held.
+*/
+type Service struct {
+	// mu guards the selected effect.
+	mu sync.Mutex
+	// lock guards the Controller, it is Config.Lock or a lock of the Service's own.
+	lock   sync.Locker
+	ctl    *dotstar.Controller
+	cfg    Config
+	effect string
+}
+
+/*
+Empty is used for calls that take no arguments or return no result.
+*/
+type Empty struct{}
+
+/*
+SetColourArgs are the arguments to SetColour.
+*/
+type SetColourArgs struct {
+	Position int
+	Colour   dotstar.Colour
+}
+
+/*
+SetColoursArgs are the arguments to SetColours, setting the LEDs from Offset onwards.
+*/
+type SetColoursArgs struct {
+	Offset  int
+	Colours []dotstar.Colour
+}
+
+/*
+A Frame holds the colours of the strip after a frame was sent.  Sequence counts the frames sent by the Controller.
+*/
+type Frame struct {
+	Sequence uint64
+	Colours  []dotstar.Colour
+}
+
+/*
+NewService creates a Service for ctl.
+*/
+func NewService(ctl *dotstar.Controller, cfg Config) *Service {
+	lock := cfg.Lock
+	if lock == nil {
+		lock = &sync.Mutex{}
+	}
+	return &Service{ctl: ctl, cfg: cfg, lock: lock}
+}
+
+/*
+ListenAndServe registers service with a new rpc.Server and serves it on the TCP address addr.
+
+It only returns when accepting a connection fails, returning that error.
+*/
+func ListenAndServe(addr string, service *Service) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, service); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+/*
+SetColour sets the colour of a single LED.
+*/
+func (s *Service) SetColour(args SetColourArgs, reply *Empty) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if args.Position < 0 || args.Position >= s.ctl.Len() {
+		return errors.New("LED position out of range")
+	}
+	s.ctl.SetColour(args.Position, args.Colour)
+	return s.ctl.Update()
+}
+
+/*
+SetColours sets the colours of the LEDs from args.Offset.  Colours beyond the end of the strip are ignored.
+*/
+func (s *Service) SetColours(args SetColoursArgs, reply *Empty) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ctl.SetColoursAt(args.Offset, args.Colours)
+	return s.ctl.Update()
+}
+
+/*
+Fill sets every LED to colour.
+*/
+func (s *Service) Fill(colour dotstar.Colour, reply *Empty) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ctl.Fill(colour)
+	return s.ctl.Update()
+}
+
+/*
+GetColours returns the colours of every LED.
+*/
+func (s *Service) GetColours(args Empty, reply *[]dotstar.Colour) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	*reply = s.ctl.Snapshot()
+	return nil
+}
+
+/*
+SetBrightness sets the global brightness.
+*/
+func (s *Service) SetBrightness(brightness uint8, reply *Empty) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ctl.SetGlobalBrightness(brightness)
+	return s.ctl.Update()
+}
+
+/*
+GetBrightness returns the global brightness.
+*/
+func (s *Service) GetBrightness(args Empty, reply *uint8) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	*reply = s.ctl.GetGlobalBrightness()
+	return nil
+}
+
+/*
+SetEffect selects the running effect by calling the configured Effect function.
+*/
+func (s *Service) SetEffect(name string, reply *Empty) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.Effect == nil {
+		return errors.New("Effects are not supported")
+	}
+	if err := s.cfg.Effect(name); err != nil {
+		return err
+	}
+	s.effect = name
+	return nil
+}
+
+/*
+GetEffect returns the name of the effect last selected, or an empty string if there is none.
+*/
+func (s *Service) GetEffect(args Empty, reply *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*reply = s.effect
+	return nil
+}
+
+/*
+NextFrame waits for a frame with a Sequence after the one given, then returns it.
+
+If no frame is sent within 10 seconds the current frame is returned, so clients can follow the strip by passing
+the Sequence of each Frame to the next call.  A Sequence of 0 returns the current frame straight away.
+*/
+func (s *Service) NextFrame(after uint64, reply *Frame) error {
+	deadline := time.Now().Add(frameTimeout)
+	for {
+		s.lock.Lock()
+		sequence := s.ctl.Stats().Frames
+		if sequence > after || after == 0 || !time.Now().Before(deadline) {
+			*reply = Frame{Sequence: sequence, Colours: s.ctl.Snapshot()}
+			s.lock.Unlock()
+			return nil
+		}
+		s.lock.Unlock()
+		time.Sleep(framePoll)
+	}
+}
@@ -0,0 +1,139 @@
+package rpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, service *Service) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, service); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	go server.ServeConn(serverConn)
+	return NewClient(rpc.NewClient(clientConn))
+}
+
+func TestService(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 3)
+	var selected string
+	client := newTestClient(t, NewService(strip, Config{Effect: func(name string) error {
+		if name != "fire" {
+			return errors.New("Unknown effect")
+		}
+		selected = name
+		return nil
+	}}))
+	defer client.Close()
+
+	if err := client.SetColour(0, dotstar.Red); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetColours(1, []dotstar.Colour{dotstar.Green, dotstar.Blue, dotstar.White}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetColour(3, dotstar.Red); err == nil {
+		t.Errorf("Expected an error for a position out of range\n")
+	}
+	clrs, err := client.Colours()
+	if err != nil || len(clrs) != 3 || clrs[0] != dotstar.Red || clrs[2] != dotstar.Blue {
+		t.Errorf("Got %v %v\n", clrs, err)
+	}
+
+	if err := client.SetBrightness(100); err != nil {
+		t.Fatal(err)
+	}
+	if brightness, err := client.Brightness(); err != nil || brightness != 100 {
+		t.Errorf("Got %v %v expected 100\n", brightness, err)
+	}
+
+	if err := client.SetEffect("rain"); err == nil {
+		t.Errorf("Expected an error for an unknown effect\n")
+	}
+	if err := client.SetEffect("fire"); err != nil || selected != "fire" {
+		t.Errorf("Got %v selecting %v\n", err, selected)
+	}
+	if name, err := client.Effect(); err != nil || name != "fire" {
+		t.Errorf("Got %v %v expected fire\n", name, err)
+	}
+}
+
+func TestNextFrame(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 2)
+	client := newTestClient(t, NewService(strip, Config{}))
+	defer client.Close()
+
+	client.Fill(dotstar.Green)
+	frame, err := client.NextFrame(0)
+	if err != nil || frame.Sequence != 1 || frame.Colours[1] != dotstar.Green {
+		t.Fatalf("Got %v %v\n", frame, err)
+	}
+
+	next := make(chan Frame)
+	go func() {
+		frame, _ := client.NextFrame(frame.Sequence)
+		next <- frame
+	}()
+	client.Fill(dotstar.Blue)
+	if frame := <-next; frame.Sequence != 2 || frame.Colours[0] != dotstar.Blue {
+		t.Errorf("Got %v\n", frame)
+	}
+}
+
+func TestServiceWithAnimator(t *testing.T) {
+	strip := dotstar.NewController(ioutil.Discard, 4)
+	var lock sync.Mutex
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan bool)
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+	startEffect := func(name string) error {
+		animator := effects.NewAnimator(strip, 100)
+		effect := effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+			for i := range leds {
+				leds[i] = dotstar.Red
+			}
+		})
+		go func() {
+			defer close(stopped)
+			for ctx.Err() == nil {
+				lock.Lock()
+				animator.RenderFrame(ctx, effect, 0)
+				lock.Unlock()
+				time.Sleep(time.Millisecond)
+			}
+		}()
+		return nil
+	}
+	client := newTestClient(t, NewService(strip, Config{Lock: &lock, Effect: startEffect}))
+	defer client.Close()
+
+	if err := client.SetEffect("red"); err != nil {
+		t.Fatal(err)
+	}
+	// Run with -race to check the Service and the Animator share the Controller safely.
+	var frame Frame
+	for i := 0; i < 5; i++ {
+		if err := client.SetBrightness(uint8(100 + i)); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if frame, err = client.NextFrame(frame.Sequence); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if frame.Colours[0] != dotstar.Red {
+		t.Errorf("Got %v expected Red\n", frame.Colours[0])
+	}
+}
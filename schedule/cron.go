@@ -0,0 +1,252 @@
+/*
+The schedule package runs jobs, such as a sunrise wake up light, at set times of day.
+
+A Cron runs jobs using cron style specifications:
+
+	cron := schedule.NewCron(time.Local)
+	cron.Add("30 6 * * 1-5", func(ctx context.Context) {
+		strip.SunriseContext(ctx, 30*time.Minute)
+	})
+	cron.Run(ctx)
+
+A Schedule switches a strip between effects and scenes through the day, at clock times or relative to sunrise
+and sunset.
+*/
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// searchLimit is how far ahead to look for the next time a specification matches.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+/*
+A Spec is a parsed cron specification of minute, hour, day of month, month and day of week.
+*/
+type Spec struct {
+	minute, hour, day, month, weekday []bool
+	// anyDay and anyWeekday are set when the field is *, cron matches either day field when both are restricted.
+	anyDay, anyWeekday bool
+}
+
+/*
+ParseSpec parses a five field cron specification: minute (0-59), hour (0-23), day of month (1-31), month (1-12)
+and day of week (0-6 from Sunday, 7 is also Sunday).
+
+Each field is * or a comma separated list of values and ranges such as 1-5.  Either may be followed by a step,
+so 0-30/10 matches 0, 10, 20 and 30.
+*/
+func ParseSpec(spec string) (*Spec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Cron specification %q must have 5 fields", spec)
+	}
+	s := &Spec{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	var err error
+	ranges := []struct {
+		dest     *[]bool
+		min, max int
+	}{{&s.minute, 0, 59}, {&s.hour, 0, 23}, {&s.day, 1, 31}, {&s.month, 1, 12}, {&s.weekday, 0, 7}}
+	for i, r := range ranges {
+		if *r.dest, err = parseField(fields[i], r.min, r.max); err != nil {
+			return nil, fmt.Errorf("Cron specification %q: %v", spec, err)
+		}
+	}
+	if s.weekday[7] {
+		s.weekday[0] = true
+	}
+	return s, nil
+}
+
+/*
+Internal function used to parse one field of a specification into the values it matches.
+*/
+func parseField(field string, min, max int) ([]bool, error) {
+	matches := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step, stepped := 1, false
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[slash+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("Invalid step in %q", part)
+			}
+			part, stepped = part[:slash], true
+		}
+		low, high := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("Invalid value %q", part)
+			}
+			high = low
+			if stepped {
+				// As in cron, a step from a single value runs to the end of the range.
+				high = max
+			}
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("Invalid range %q", part)
+				}
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			matches[value] = true
+		}
+	}
+	return matches, nil
+}
+
+/*
+Next returns the first time after t, to the minute, that the specification matches in t's location.
+
+The zero time is returned if there is no match within five years, for example for the 31st of February.
+*/
+func (s *Spec) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	// Truncate works in UTC, so correct for locations with offsets that are not whole minutes.
+	next = next.Add(-time.Duration(next.Second()) * time.Second)
+	limit := t.Add(searchLimit)
+	for next.Before(limit) {
+		if !s.month[next.Month()] || !s.matchDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.hour[next.Hour()] {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.minute[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+/*
+Internal method used to check the day of month and day of week fields, which match if either does when both are set.
+*/
+func (s *Spec) matchDay(t time.Time) bool {
+	day, weekday := s.day[t.Day()], s.weekday[t.Weekday()]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+/*
+A Job is run by a Cron.  The context is cancelled when the Cron stops.
+*/
+type Job func(ctx context.Context)
+
+// cronEntry is a job and when to run it.
+type cronEntry struct {
+	spec *Spec
+	job  Job
+}
+
+/*
+A Cron runs jobs according to cron specifications.  Jobs may be added while it is running.
+*/
+type Cron struct {
+	mu       sync.Mutex
+	location *time.Location
+	entries  []cronEntry
+	// wake is signalled when entries are added, so Run can recalculate when to wake up.
+	wake chan struct{}
+	// now and after are replaced in tests.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+/*
+NewCron creates a Cron that interprets specifications in location, for example time.Local.
+*/
+func NewCron(location *time.Location) *Cron {
+	if location == nil {
+		location = time.Local
+	}
+	return &Cron{location: location, wake: make(chan struct{}, 1), now: time.Now, after: time.After}
+}
+
+/*
+Add runs job each time spec matches.  An error is returned if spec cannot be parsed.
+*/
+func (c *Cron) Add(spec string, job Job) error {
+	parsed, err := ParseSpec(spec)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.entries = append(c.entries, cronEntry{spec: parsed, job: job})
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+/*
+Run waits for each job's next time and starts it in its own goroutine, until ctx is done.
+
+The context error is returned once ctx is done, jobs still running are given the cancelled context.
+*/
+func (c *Cron) Run(ctx context.Context) error {
+	last := c.now().In(c.location)
+	for {
+		next, jobs := c.nextJobs(last)
+		var timer <-chan time.Time
+		if len(jobs) > 0 {
+			timer = c.after(next.Sub(c.now()))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.wake:
+			continue
+		case <-timer:
+		}
+		for _, job := range jobs {
+			go job(ctx)
+		}
+		last = next
+	}
+}
+
+/*
+Internal method used to find the earliest time after last that any job is due, and the jobs due then.
+*/
+func (c *Cron) nextJobs(last time.Time) (time.Time, []Job) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var next time.Time
+	var jobs []Job
+	for _, entry := range c.entries {
+		due := entry.spec.Next(last)
+		switch {
+		case due.IsZero():
+		case next.IsZero() || due.Before(next):
+			next, jobs = due, []Job{entry.job}
+		case due.Equal(next):
+			jobs = append(jobs, entry.job)
+		}
+	}
+	return next, jobs
+}
@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSpecNext(t *testing.T) {
+	// 1st January 2021 was a Friday.
+	start := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, 1, 1, 12, 1, 0, 0, time.UTC)},
+		{"30 6 * * *", time.Date(2021, 1, 2, 6, 30, 0, 0, time.UTC)},
+		{"30 6 * * 1-5", time.Date(2021, 1, 4, 6, 30, 0, 0, time.UTC)},
+		{"*/15 13 * * *", time.Date(2021, 1, 1, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 3 *", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 10 * 0", time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2021, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		spec, err := ParseSpec(test.spec)
+		if err != nil {
+			t.Fatalf("Got %v for %q\n", err, test.spec)
+		}
+		if next := spec.Next(start); !next.Equal(test.expected) {
+			t.Errorf("Got %v expected %v for %q\n", next, test.expected, test.spec)
+		}
+	}
+}
+
+func TestSpecStep(t *testing.T) {
+	spec, err := ParseSpec("5/15 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, minute := range []int{5, 20, 35, 50, 65} {
+		expected := time.Date(2021, 1, 1, 12, minute, 0, 0, time.UTC)
+		if next = spec.Next(next); !next.Equal(expected) {
+			t.Errorf("Got %v expected %v\n", next, expected)
+		}
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("Expected an error for %q\n", spec)
+		}
+	}
+}
+
+func TestCronRun(t *testing.T) {
+	clock := time.Date(2021, 1, 1, 6, 29, 30, 0, time.UTC)
+	waits := make(chan time.Duration, 10)
+	cron := NewCron(time.UTC)
+	cron.now = func() time.Time { return clock }
+	cron.after = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		fired := make(chan time.Time, 1)
+		// Only the first wait completes, later ones wait until the test ends.
+		if len(waits) == 1 {
+			clock = clock.Add(d)
+			fired <- clock
+		}
+		return fired
+	}
+
+	ran := make(chan bool, 1)
+	cron.Add("30 6 * * *", func(ctx context.Context) {
+		ran <- true
+	})
+	// Drop the wake up from Add, so that Run makes a single wait for the job.
+	<-cron.wake
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- cron.Run(ctx) }()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Errorf("Job did not run\n")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Got %v expected context.Canceled\n", err)
+	}
+	if first, second := <-waits, <-waits; first != 30*time.Second || second != 24*time.Hour {
+		t.Errorf("Got waits %v and %v\n", first, second)
+	}
+}
@@ -0,0 +1,219 @@
+package schedule
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"sort"
+	"time"
+)
+
+/*
+A TimeOfDay is when a Schedule entry starts each day, either a clock time or relative to sunrise or sunset.
+*/
+type TimeOfDay struct {
+	// sun is 0 for a clock time, 1 for sunrise and 2 for sunset.
+	sun    int
+	offset time.Duration
+}
+
+/*
+At returns the clock time hour:minute.
+*/
+func At(hour, minute int) TimeOfDay {
+	return TimeOfDay{offset: time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute}
+}
+
+/*
+AtSunrise returns a time offset from sunrise, for example AtSunrise(-30 * time.Minute) is half an hour before.
+*/
+func AtSunrise(offset time.Duration) TimeOfDay {
+	return TimeOfDay{sun: 1, offset: offset}
+}
+
+/*
+AtSunset returns a time offset from sunset.
+*/
+func AtSunset(offset time.Duration) TimeOfDay {
+	return TimeOfDay{sun: 2, offset: offset}
+}
+
+// scheduleEntry is an effect and when it starts.
+type scheduleEntry struct {
+	at     TimeOfDay
+	effect effects.Effect
+	scene  *effects.Scene
+}
+
+/*
+A Schedule switches between effects and scenes at set times each day.
+
+Each entry runs from its time until the time of the next entry, so a day is covered by adding an entry for
+each change:
+
+	day := schedule.NewSchedule(time.Local)
+	day.SetPosition(51.5, -0.13)
+	day.Add(schedule.At(7, 0), &effects.NoiseEffect{Palette: dotstar.CloudPalette, Scale: 0.05, Speed: 0.1})
+	day.Add(schedule.AtSunset(0), fire)
+	day.Add(schedule.At(23, 30), nil)
+	day.Run(ctx, strip, 30)
+
+Methods are NOT safe to call from multiple goroutines concurrently.
+*/
+type Schedule struct {
+	location            *time.Location
+	latitude, longitude float64
+	hasPosition         bool
+	entries             []scheduleEntry
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+/*
+NewSchedule creates an empty Schedule with clock times in location, for example time.Local.
+*/
+func NewSchedule(location *time.Location) *Schedule {
+	if location == nil {
+		location = time.Local
+	}
+	return &Schedule{location: location, now: time.Now}
+}
+
+/*
+SetPosition sets the latitude and longitude, in degrees north and east, used for sunrise and sunset times.
+
+Entries relative to sunrise or sunset are skipped until a position is set, and on days where the sun does not
+rise or set.
+*/
+func (s *Schedule) SetPosition(latitude, longitude float64) {
+	s.latitude, s.longitude, s.hasPosition = latitude, longitude, true
+}
+
+/*
+Add runs effect each day from at until the next entry.  A nil effect turns the strip off.
+*/
+func (s *Schedule) Add(at TimeOfDay, effect effects.Effect) {
+	s.entries = append(s.entries, scheduleEntry{at: at, effect: effect})
+}
+
+/*
+AddScene applies scene, setting its brightness, and runs its effect each day from at until the next entry.
+*/
+func (s *Schedule) AddScene(at TimeOfDay, scene *effects.Scene) {
+	s.entries = append(s.entries, scheduleEntry{at: at, effect: scene.Effect, scene: scene})
+}
+
+// occurrence is a scheduleEntry on a particular day.
+type occurrence struct {
+	when  time.Time
+	entry *scheduleEntry
+}
+
+/*
+Internal method used to list the entries occurring from the day before t to the day after, in order.
+*/
+func (s *Schedule) occurrences(t time.Time) []occurrence {
+	t = t.In(s.location)
+	var result []occurrence
+	for day := -1; day <= 1; day++ {
+		date := time.Date(t.Year(), t.Month(), t.Day()+day, 0, 0, 0, 0, s.location)
+		for i := range s.entries {
+			entry := &s.entries[i]
+			var when time.Time
+			switch entry.at.sun {
+			case 0:
+				// Use the calendar so clock times are right on days when daylight saving changes.
+				hour, minute := int(entry.at.offset/time.Hour), int(entry.at.offset%time.Hour/time.Minute)
+				when = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, s.location)
+			default:
+				if !s.hasPosition {
+					continue
+				}
+				sunrise, sunset, ok := Sun(date, s.latitude, s.longitude)
+				if !ok {
+					continue
+				}
+				if entry.at.sun == 1 {
+					when = sunrise.Add(entry.at.offset)
+				} else {
+					when = sunset.Add(entry.at.offset)
+				}
+			}
+			result = append(result, occurrence{when: when, entry: entry})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].when.Before(result[j].when)
+	})
+	return result
+}
+
+/*
+Current returns the effect scheduled at t and when the schedule next changes.
+
+The effect is nil if the strip should be off, and the time is zero if nothing is scheduled.
+*/
+func (s *Schedule) Current(t time.Time) (effects.Effect, time.Time) {
+	entry, next := s.current(t)
+	if entry == nil {
+		return nil, next
+	}
+	return entry.effect, next
+}
+
+/*
+Internal method used to find the entry running at t and the time of the next change.
+*/
+func (s *Schedule) current(t time.Time) (*scheduleEntry, time.Time) {
+	var current *scheduleEntry
+	var next time.Time
+	for _, o := range s.occurrences(t) {
+		if !o.when.After(t) {
+			current = o.entry
+		} else if next.IsZero() {
+			next = o.when
+		}
+	}
+	return current, next
+}
+
+/*
+Run animates ctl at fps frames a second, running the scheduled effect and switching as the schedule changes,
+until ctx is done or an Update() fails.
+
+The context error is returned when ctx is done.
+*/
+func (s *Schedule) Run(ctx context.Context, ctl *dotstar.Controller, fps int) error {
+	animator := effects.NewAnimator(ctl, fps)
+	for {
+		now := s.now()
+		entry, next := s.current(now)
+		var effect effects.Effect = off
+		if entry != nil && entry.effect != nil {
+			effect = entry.effect
+		}
+		if entry != nil && entry.scene != nil && entry.scene.Brightness >= 0 {
+			ctl.SetGlobalBrightness(uint8(entry.scene.Brightness))
+		}
+
+		runCtx, cancel := ctx, context.CancelFunc(func() {})
+		if !next.IsZero() {
+			runCtx, cancel = context.WithTimeout(ctx, next.Sub(now))
+		}
+		err := animator.Run(runCtx, effect)
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != context.DeadlineExceeded {
+			return err
+		}
+	}
+}
+
+// off is the effect used when nothing is scheduled.
+var off = effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+	for i := range leds {
+		leds[i] = dotstar.Off
+	}
+})
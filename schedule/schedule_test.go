@@ -0,0 +1,69 @@
+package schedule
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestSun(t *testing.T) {
+	// London on the summer solstice, sunrise is around 03:43 and sunset 20:21 UTC.
+	sunrise, sunset, ok := Sun(time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC), 51.5, -0.13)
+	expectedRise := time.Date(2021, 6, 21, 3, 43, 0, 0, time.UTC)
+	expectedSet := time.Date(2021, 6, 21, 20, 21, 0, 0, time.UTC)
+	if !ok || sunrise.Sub(expectedRise) > 3*time.Minute || expectedRise.Sub(sunrise) > 3*time.Minute ||
+		sunset.Sub(expectedSet) > 3*time.Minute || expectedSet.Sub(sunset) > 3*time.Minute {
+		t.Errorf("Got %v and %v, %v\n", sunrise, sunset, ok)
+	}
+
+	// The sun does not set in northern Norway in June.
+	if _, _, ok := Sun(time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC), 70, 20); ok {
+		t.Errorf("Expected no sunset\n")
+	}
+}
+
+func TestScheduleCurrent(t *testing.T) {
+	morning := effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {})
+	evening := effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {})
+	s := NewSchedule(time.UTC)
+	s.Add(At(7, 0), morning)
+	s.Add(AtSunset(-time.Hour), evening)
+	s.Add(At(23, 30), nil)
+
+	// Without a position the sunset entry is skipped.
+	if effect, next := s.Current(time.Date(2021, 6, 21, 12, 0, 0, 0, time.UTC)); effect == nil || next.Hour() != 23 {
+		t.Errorf("Got %v, %v\n", effect, next)
+	}
+
+	s.SetPosition(51.5, -0.13)
+	effect, next := s.Current(time.Date(2021, 6, 21, 12, 0, 0, 0, time.UTC))
+	if effect == nil || next.Hour() != 19 || next.Minute() < 18 || next.Minute() > 24 {
+		t.Errorf("Got next change %v\n", next)
+	}
+	if effect, next := s.Current(time.Date(2021, 6, 22, 2, 0, 0, 0, time.UTC)); effect != nil || !next.Equal(time.Date(2021, 6, 22, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Got %v, %v after midnight\n", effect, next)
+	}
+}
+
+func TestScheduleRun(t *testing.T) {
+	clock := time.Date(2021, 1, 1, 7, 0, 0, 0, time.UTC)
+	s := NewSchedule(time.UTC)
+	s.now = func() time.Time { return clock }
+	ran := make(chan bool, 100)
+	s.AddScene(At(7, 0), &effects.Scene{Brightness: 64, Effect: effects.EffectFunc(func(leds []dotstar.Colour, t time.Duration) {
+		ran <- true
+	})})
+
+	ctl := dotstar.NewController(ioutil.Discard, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx, ctl, 100); err != context.DeadlineExceeded {
+		t.Errorf("Got %v expected context.DeadlineExceeded\n", err)
+	}
+	if len(ran) == 0 || ctl.GetGlobalBrightness() != 64 {
+		t.Errorf("Got %v frames at brightness %v\n", len(ran), ctl.GetGlobalBrightness())
+	}
+}
@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"math"
+	"time"
+)
+
+// Julian dates used by the sunrise equation.
+const (
+	unixEpochJulian = 2440587.5
+	j2000Julian     = 2451545.0
+)
+
+/*
+Sun returns the times of sunrise and sunset on the day of date, as seen from latitude and longitude in degrees
+(north and east positive).  The day is taken in date's location.
+
+ok is false if the sun does not rise or set that day, which happens near the poles.  The times are accurate to a
+few minutes, which is plenty for lighting.
+*/
+func Sun(date time.Time, latitude, longitude float64) (sunrise, sunset time.Time, ok bool) {
+	// Work from midday in the date's location, so the result is for the right local day.
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location())
+	julian := float64(noon.Unix())/86400 + unixEpochJulian
+	day := math.Floor(julian - j2000Julian + 0.5)
+
+	meanSolarTime := day - longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanSolarTime, 360)
+	m := radians(anomaly)
+	centre := 1.9148*math.Sin(m) + 0.02*math.Sin(2*m) + 0.0003*math.Sin(3*m)
+	eclipticLongitude := radians(math.Mod(anomaly+centre+180+102.9372, 360))
+	transit := j2000Julian + meanSolarTime + 0.0053*math.Sin(m) - 0.0069*math.Sin(2*eclipticLongitude)
+
+	declination := math.Asin(math.Sin(eclipticLongitude) * math.Sin(radians(23.4397)))
+	lat := radians(latitude)
+	// -0.833 degrees allows for refraction and the size of the sun's disc.
+	cosHourAngle := (math.Sin(radians(-0.833)) - math.Sin(lat)*math.Sin(declination)) / (math.Cos(lat) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+
+	sunrise = julianToTime(transit-hourAngle/360, date.Location())
+	sunset = julianToTime(transit+hourAngle/360, date.Location())
+	return sunrise, sunset, true
+}
+
+// radians converts degrees to radians.
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// julianToTime converts a Julian date into a time in location, to the second.
+func julianToTime(julian float64, location *time.Location) time.Time {
+	return time.Unix(int64(math.Round((julian-unixEpochJulian)*86400)), 0).In(location)
+}
@@ -0,0 +1,129 @@
+package sysstats
+
+import (
+	"context"
+	"github.com/owlfish/dotstar"
+	"github.com/owlfish/dotstar/effects"
+	"sync"
+	"time"
+)
+
+/*
+A Gauge shows a Metric on the strip, from LED Start for Count LEDs.
+
+The gauge is drawn as an effects.LevelMeter unless a Palette is set, in which case the whole of its part of the
+strip is filled with the colour at the metric's position along the palette.
+*/
+type Gauge struct {
+	Start, Count int
+	Metric       Metric
+	Palette      dotstar.Palette
+
+	meter effects.LevelMeter
+	// value is the last reading, err the error from it if any.
+	value float64
+	err   error
+}
+
+/*
+A Dashboard reads its gauges on an interval and draws them.  It is an Effect, so it can be run by an
+effects.Animator while Run polls the metrics from another goroutine.
+*/
+type Dashboard struct {
+	interval time.Duration
+	gauges   []*Gauge
+	lock     sync.Mutex
+}
+
+/*
+NewDashboard creates a Dashboard reading gauges every interval.  An interval of 0 reads them every 5 seconds.
+*/
+func NewDashboard(interval time.Duration, gauges ...*Gauge) *Dashboard {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &Dashboard{interval: interval, gauges: gauges}
+}
+
+/*
+Poll reads every gauge once.  All gauges are read even if some fail, the first error is returned.
+
+A gauge that fails to read keeps showing its previous value.
+*/
+func (d *Dashboard) Poll() error {
+	var first error
+	for _, g := range d.gauges {
+		value, err := g.Metric()
+		d.lock.Lock()
+		g.err = err
+		if err == nil {
+			g.value = value
+			g.meter.Set(value)
+		}
+		d.lock.Unlock()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+/*
+Run polls the gauges every interval until ctx is done, returning the context error.
+
+Errors from reading the metrics do not stop Run, they can be checked with Err.
+*/
+func (d *Dashboard) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.Poll()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+Err returns the error from the last reading of each gauge that failed, or nil if they all succeeded.
+*/
+func (d *Dashboard) Err() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, g := range d.gauges {
+		if g.err != nil {
+			return g.err
+		}
+	}
+	return nil
+}
+
+/*
+Render draws each gauge into its part of leds.  Parts of a gauge beyond leds are left out.
+*/
+func (d *Dashboard) Render(leds []dotstar.Colour, t time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, g := range d.gauges {
+		start, end := g.Start, g.Start+g.Count
+		if start < 0 {
+			start = 0
+		}
+		if end > len(leds) {
+			end = len(leds)
+		}
+		if start >= end {
+			continue
+		}
+		if g.Palette == nil {
+			g.meter.Render(leds[start:end], t)
+			continue
+		}
+		clr := g.Palette.At(g.value)
+		for i := start; i < end; i++ {
+			leds[i] = clr
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+The sysstats package turns a strip into an at-a-glance health indicator for the host it runs on.
+
+Metrics read the CPU load, memory use and temperature from /proc and /sys on Linux, each as a value from 0 to 1.
+A Dashboard polls a set of Gauges on an interval and draws each as a level meter, or as a colour from a palette,
+on its own part of the strip:
+
+	dash := sysstats.NewDashboard(5*time.Second,
+		&sysstats.Gauge{Start: 0, Count: 10, Metric: sysstats.CPULoad()},
+		&sysstats.Gauge{Start: 10, Count: 10, Metric: sysstats.MemoryUsed()},
+		&sysstats.Gauge{Start: 20, Count: 4, Metric: sysstats.Temperature(0, 40, 80), Palette: dotstar.HeatPalette})
+	go dash.Run(ctx)
+	effects.NewAnimator(strip, 30).Run(ctx, dash)
+*/
+package sysstats
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// root is prefixed to the /proc and /sys paths read, it is replaced in tests.
+var root = "/"
+
+/*
+A Metric reads a measurement of the host, scaled to the range 0 to 1.
+*/
+type Metric func() (float64, error)
+
+/*
+CPULoad returns a Metric reading the share of time the CPUs have been busy since the previous reading.
+
+The first reading covers the time since the host started.
+*/
+func CPULoad() Metric {
+	var lastBusy, lastTotal uint64
+	return func() (float64, error) {
+		data, err := ioutil.ReadFile(filepath.Join(root, "proc/stat"))
+		if err != nil {
+			return 0, err
+		}
+		line := strings.SplitN(string(data), "\n", 2)[0]
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] != "cpu" {
+			return 0, errors.New("Unexpected format of /proc/stat")
+		}
+		var busy, total uint64
+		// Only user, nice, system, idle, iowait, irq, softirq and steal are counted, as guest and guest_nice are
+		// already included in user and nice.
+		for i, field := range fields[1:] {
+			if i == 8 {
+				break
+			}
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("Unexpected value in /proc/stat: %v", err)
+			}
+			total += value
+			// The idle and iowait times are the 4th and 5th values.
+			if i != 3 && i != 4 {
+				busy += value
+			}
+		}
+		previousBusy, previousTotal := lastBusy, lastTotal
+		lastBusy, lastTotal = busy, total
+		// The iowait time can go backwards on Linux, so the total may not have grown.
+		if total <= previousTotal || busy < previousBusy {
+			return 0, nil
+		}
+		return clamp(float64(busy-previousBusy) / float64(total-previousTotal)), nil
+	}
+}
+
+/*
+MemoryUsed returns a Metric reading the share of memory that is not available for new programs.
+*/
+func MemoryUsed() Metric {
+	return func() (float64, error) {
+		data, err := ioutil.ReadFile(filepath.Join(root, "proc/meminfo"))
+		if err != nil {
+			return 0, err
+		}
+		var total, available float64
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[0] {
+			case "MemTotal:":
+				total = value
+			case "MemAvailable:":
+				available = value
+			}
+		}
+		if total <= 0 {
+			return 0, errors.New("MemTotal not found in /proc/meminfo")
+		}
+		return clamp(1 - available/total), nil
+	}
+}
+
+/*
+Temperature returns a Metric reading the thermal zone given, with cold degrees Celsius as 0 and hot as 1.
+
+Zone 0 is the CPU on a Raspberry Pi.
+*/
+func Temperature(zone int, cold, hot float64) Metric {
+	return func() (float64, error) {
+		path := filepath.Join(root, "sys/class/thermal", fmt.Sprintf("thermal_zone%d", zone), "temp")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			return 0, fmt.Errorf("Unexpected temperature in %v: %v", path, err)
+		}
+		if hot <= cold {
+			return 0, errors.New("Hot temperature must be above cold")
+		}
+		return clamp((milli/1000 - cold) / (hot - cold)), nil
+	}
+}
+
+/*
+Internal function used to limit a value to the range 0 to 1.
+*/
+func clamp(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package sysstats
+
+import (
+	"github.com/owlfish/dotstar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, contents string) {
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fakeRoot(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "sysstats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root = dir
+	return func() {
+		root = "/"
+		os.RemoveAll(dir)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	defer fakeRoot(t)()
+	writeFile(t, "proc/stat", "cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 1 2 3 4\n")
+	writeFile(t, "proc/meminfo", "MemTotal:       1000 kB\nMemFree:         100 kB\nMemAvailable:    250 kB\n")
+	writeFile(t, "sys/class/thermal/thermal_zone0/temp", "60000\n")
+
+	cpu := CPULoad()
+	if load, err := cpu(); err != nil || load != 0.2 {
+		t.Errorf("Got %v %v expected 0.2\n", load, err)
+	}
+	writeFile(t, "proc/stat", "cpu  150 0 150 750 150 0 0 0 0 0\n")
+	if load, err := cpu(); err != nil || load != 0.5 {
+		t.Errorf("Got %v %v expected 0.5\n", load, err)
+	}
+	// Guest time is already part of the user time.
+	writeFile(t, "proc/stat", "cpu  200 0 200 800 150 0 0 0 50 0\n")
+	if load, err := cpu(); err != nil || load != 2.0/3 {
+		t.Errorf("Got %v %v expected 0.67\n", load, err)
+	}
+	// A fall in the iowait time must not wrap around.
+	writeFile(t, "proc/stat", "cpu  200 0 200 800 100 0 0 0 50 0\n")
+	if load, err := cpu(); err != nil || load != 0 {
+		t.Errorf("Got %v %v expected 0\n", load, err)
+	}
+
+	if used, err := MemoryUsed()(); err != nil || used != 0.75 {
+		t.Errorf("Got %v %v expected 0.75\n", used, err)
+	}
+	if temp, err := Temperature(0, 40, 80)(); err != nil || temp != 0.5 {
+		t.Errorf("Got %v %v expected 0.5\n", temp, err)
+	}
+	if _, err := Temperature(1, 40, 80)(); err == nil {
+		t.Errorf("Expected an error for a missing zone\n")
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	failing := func() (float64, error) { return 0, os.ErrNotExist }
+	dash := NewDashboard(time.Second,
+		&Gauge{Start: 0, Count: 4, Metric: func() (float64, error) { return 0.5, nil }},
+		&Gauge{Start: 4, Count: 2, Metric: func() (float64, error) { return 1, nil }, Palette: dotstar.Palette{dotstar.Green, dotstar.Red}},
+		&Gauge{Start: 6, Count: 4, Metric: failing})
+	if err := dash.Poll(); err != os.ErrNotExist || dash.Err() != os.ErrNotExist {
+		t.Errorf("Got %v expected %v\n", err, os.ErrNotExist)
+	}
+
+	leds := make([]dotstar.Colour, 8)
+	dash.Render(leds, 0)
+	expected := []dotstar.Colour{dotstar.Green, dotstar.Green, dotstar.Off, dotstar.Off, dotstar.Red, dotstar.Red,
+		dotstar.Off, dotstar.Off}
+	for i := range expected {
+		if leds[i] != expected[i] {
+			t.Errorf("LED %d got %v expected %v\n", i, leds[i], expected[i])
+		}
+	}
+}
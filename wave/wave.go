@@ -0,0 +1,104 @@
+/*
+The wave package provides oscillators for animation, computed from the time passed to an effect's Render.
+
+Each oscillator takes the time t, a frequency in Hz and a phase offset in cycles (0.25 is a quarter cycle
+ahead) and returns a value from 0 to 1.  As they depend only on the time, motion stays smooth and runs at
+the same speed whatever the frame rate:
+
+	brightness := wave.Sine(t, 0.5, 0)
+	position := int(wave.Triangle(t, 0.2, 0) * float64(len(leds)-1))
+
+The Beat functions mirror FastLED's beat8 and beatsin8, with the speed given in beats per minute.
+*/
+package wave
+
+import (
+	"math"
+	"time"
+)
+
+/*
+An Oscillator returns a value from 0 to 1 that repeats over time, such as a bound Sine or Triangle.
+*/
+type Oscillator func(t time.Duration) float64
+
+/*
+Sine returns a sine wave from 0 to 1, starting half way and rising.
+*/
+func Sine(t time.Duration, hz, phase float64) float64 {
+	return (1 + math.Sin(2*math.Pi*cycle(t, hz, phase))) / 2
+}
+
+/*
+Triangle returns a value rising steadily from 0 to 1 over the first half of each cycle and falling back to 0
+over the second half.
+*/
+func Triangle(t time.Duration, hz, phase float64) float64 {
+	position := cycle(t, hz, phase)
+	if position < 0.5 {
+		return position * 2
+	}
+	return 2 - position*2
+}
+
+/*
+Sawtooth returns a value rising steadily from 0 to 1 over each cycle and then jumping back to 0.
+*/
+func Sawtooth(t time.Duration, hz, phase float64) float64 {
+	return cycle(t, hz, phase)
+}
+
+/*
+Square returns 1 for the first half of each cycle and 0 for the second half.
+*/
+func Square(t time.Duration, hz, phase float64) float64 {
+	if cycle(t, hz, phase) < 0.5 {
+		return 1
+	}
+	return 0
+}
+
+/*
+NewOscillator binds one of the wave functions to a frequency and phase.
+*/
+func NewOscillator(wave func(t time.Duration, hz, phase float64) float64, hz, phase float64) Oscillator {
+	return func(t time.Duration) float64 {
+		return wave(t, hz, phase)
+	}
+}
+
+/*
+Beat8 returns a sawtooth rising from 0 to 255 bpm times a minute, like FastLED's beat8.
+*/
+func Beat8(t time.Duration, bpm float64) uint8 {
+	return uint8(Sawtooth(t, bpm/60, 0) * 256)
+}
+
+/*
+BeatSin8 returns a sine wave between low and high, bpm times a minute, like FastLED's beatsin8.
+*/
+func BeatSin8(t time.Duration, bpm float64, low, high uint8) uint8 {
+	return uint8(math.Round(Between(Sine(t, bpm/60, 0), float64(low), float64(high))))
+}
+
+/*
+BeatSin returns a sine wave between low and high, bpm times a minute.
+*/
+func BeatSin(t time.Duration, bpm float64, low, high float64) float64 {
+	return Between(Sine(t, bpm/60, 0), low, high)
+}
+
+/*
+Between scales value, from 0 to 1, to the range low to high.
+*/
+func Between(value, low, high float64) float64 {
+	return low + value*(high-low)
+}
+
+/*
+Internal function used to find how far through its cycle, from 0 up to 1, a wave of frequency hz is at time t.
+*/
+func cycle(t time.Duration, hz, phase float64) float64 {
+	position := t.Seconds()*hz + phase
+	return position - math.Floor(position)
+}
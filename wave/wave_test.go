@@ -0,0 +1,60 @@
+package wave
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWaves(t *testing.T) {
+	tests := []struct {
+		name     string
+		wave     func(t time.Duration, hz, phase float64) float64
+		expected []float64
+	}{
+		{"Sine", Sine, []float64{0.5, 1, 0.5, 0, 0.5}},
+		{"Triangle", Triangle, []float64{0, 0.5, 1, 0.5, 0}},
+		{"Sawtooth", Sawtooth, []float64{0, 0.25, 0.5, 0.75, 0}},
+		{"Square", Square, []float64{1, 1, 0, 0, 1}},
+	}
+	for _, test := range tests {
+		// At 2Hz each step of 125ms is a quarter of a cycle.
+		for i, want := range test.expected {
+			if got := test.wave(time.Duration(i)*125*time.Millisecond, 2, 0); math.Abs(got-want) > 1e-9 {
+				t.Errorf("%s step %d got %v expected %v\n", test.name, i, got, want)
+			}
+		}
+		if a, b := test.wave(0, 2, 0.25), test.wave(125*time.Millisecond, 2, 0); math.Abs(a-b) > 1e-9 {
+			t.Errorf("%s got %v with a quarter phase expected %v\n", test.name, a, b)
+		}
+	}
+	if got := Sawtooth(-100*time.Millisecond, 1, 0); math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("Got %v expected 0.9 before the start\n", got)
+	}
+}
+
+func TestOscillator(t *testing.T) {
+	osc := NewOscillator(Triangle, 1, 0.5)
+	if got := osc(0); got != 1 {
+		t.Errorf("Got %v expected 1\n", got)
+	}
+}
+
+func TestBeats(t *testing.T) {
+	// At 60 bpm there is one beat a second.
+	if got := Beat8(500*time.Millisecond, 60); got != 128 {
+		t.Errorf("Got %d expected 128\n", got)
+	}
+	if got := Beat8(time.Second, 60); got != 0 {
+		t.Errorf("Got %d expected 0\n", got)
+	}
+	if got := BeatSin8(250*time.Millisecond, 60, 100, 200); got != 200 {
+		t.Errorf("Got %d expected 200\n", got)
+	}
+	if got := BeatSin8(750*time.Millisecond, 60, 100, 200); got != 100 {
+		t.Errorf("Got %d expected 100\n", got)
+	}
+	if got := BeatSin(0, 120, -1, 1); math.Abs(got) > 1e-9 {
+		t.Errorf("Got %v expected 0\n", got)
+	}
+}